@@ -0,0 +1,182 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"golang.org/x/exp/slices"
+	tspb "google.golang.org/protobuf/types/known/timestamppb"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+// Kinds of the changes in AcceptedDiffs.
+const (
+	acceptedAdded    = "added"
+	acceptedRemoved  = "removed"
+	acceptedModified = "modified"
+)
+
+// ReadAcceptedDiffs reads the accepted diffs file at path. A missing file holds no
+// accepted diffs, so the first review doesn't need to create it.
+func (r *Reporter) ReadAcceptedDiffs(path string) (*fspb.AcceptedDiffs, error) {
+	accepted := &fspb.AcceptedDiffs{}
+	if err := readTextProto(path, accepted); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return accepted, nil
+}
+
+// WriteAcceptedDiffs writes accepted to the accepted diffs file at path, replacing
+// its content. The file mode is taken from the report config.
+func (r *Reporter) WriteAcceptedDiffs(path string, accepted *fspb.AcceptedDiffs) error {
+	mode, err := r.OutputFileMode()
+	if err != nil {
+		return err
+	}
+	return writeTextProto(path, accepted, mode)
+}
+
+// AcceptDiffs adds the added, removed and modified files of report to accepted,
+// replacing the previously accepted diffs of the same paths. Diffs which were
+// suppressed by SuppressAcceptedDiffs are kept as they are.
+func (r *Report) AcceptDiffs(accepted *fspb.AcceptedDiffs) {
+	var diffs []*fspb.AcceptedDiff
+	for _, a := range r.Added {
+		diffs = append(diffs, acceptedDiff(acceptedAdded, a))
+	}
+	for _, a := range r.Deleted {
+		diffs = append(diffs, acceptedDiff(acceptedRemoved, a))
+	}
+	for _, a := range r.Modified {
+		diffs = append(diffs, acceptedDiff(acceptedModified, a))
+	}
+	replaced := map[string]bool{}
+	for _, d := range diffs {
+		replaced[d.Path] = true
+	}
+	kept := accepted.Diff[:0]
+	for _, d := range accepted.Diff {
+		if !replaced[d.Path] {
+			kept = append(kept, d)
+		}
+	}
+	accepted.Diff = append(kept, diffs...)
+	slices.SortFunc(accepted.Diff, func(a, b *fspb.AcceptedDiff) bool {
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Kind < b.Kind
+	})
+}
+
+// SuppressAcceptedDiffs removes the added, removed and modified files from the
+// Report whose change is still exactly the one accepted, so a reviewer only sees
+// new changes. A file which changed any further since is reported again.
+// Critical changes are never suppressed. It returns the number of suppressed diffs.
+func (r *Report) SuppressAcceptedDiffs(accepted *fspb.AcceptedDiffs) int {
+	hashes := map[string]bool{}
+	for _, d := range accepted.GetDiff() {
+		hashes[d.Hash] = true
+	}
+	n := 0
+	keep := func(kind string, entries []ActionData) []ActionData {
+		var kept []ActionData
+		for _, a := range entries {
+			if !a.Critical && hashes[acceptedDiff(kind, a).Hash] {
+				n++
+				continue
+			}
+			kept = append(kept, a)
+		}
+		return kept
+	}
+	r.Added = keep(acceptedAdded, r.Added)
+	r.Deleted = keep(acceptedRemoved, r.Deleted)
+	r.Modified = keep(acceptedModified, r.Modified)
+	r.PermissionChanges = keep(acceptedModified, r.PermissionChanges)
+	if r.Counter != nil {
+		r.Counter.Add(int64(n), "accepted-diffs-suppressed")
+	}
+	return n
+}
+
+// acceptedDiff returns the AcceptedDiff of the change of kind described by a.
+// Its hash covers the changed field values of modified files and the content
+// and metadata of added and removed ones, but not their access or change times.
+func acceptedDiff(kind string, a ActionData) *fspb.AcceptedDiff {
+	var path, change string
+	switch kind {
+	case acceptedAdded:
+		path, change = a.After.Path, acceptedFileSummary(a.After)
+	case acceptedRemoved:
+		path, change = a.Before.Path, acceptedFileSummary(a.Before)
+	default:
+		path, change = a.After.Path, acceptedFieldsSummary(a)
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s", kind, path, change)
+	return &fspb.AcceptedDiff{
+		Path: path,
+		Kind: kind,
+		Hash: hex.EncodeToString(h.Sum(nil)),
+	}
+}
+
+// acceptedFieldsSummary describes the changed fields of the modified file of a.
+// Timestamps are taken from the files in UTC rather than as displayed, so the
+// summary doesn't depend on the displayTimezone or the output format.
+func acceptedFieldsSummary(a ActionData) string {
+	var summary []string
+	for _, d := range a.Fields {
+		before, after := d.Before, d.After
+		var bt, at *tspb.Timestamp
+		switch d.Field {
+		case "mtime":
+			bt, at = a.Before.GetInfo().GetModified(), a.After.GetInfo().GetModified()
+		case "ctime":
+			bt, at = a.Before.GetStat().GetCtime(), a.After.GetStat().GetCtime()
+		case "btime":
+			bt, at = a.Before.GetStat().GetBtime(), a.After.GetStat().GetBtime()
+		}
+		if bt != nil || at != nil {
+			before = bt.AsTime().UTC().Format(timeReportFormatNano)
+			after = at.AsTime().UTC().Format(timeReportFormatNano)
+		}
+		summary = append(summary, fmt.Sprintf("%s: %s => %s", d.Field, before, after))
+	}
+	return strings.Join(summary, "\n")
+}
+
+// acceptedFileSummary describes the state of f an added or removed file is accepted in.
+func acceptedFileSummary(f *fspb.File) string {
+	summary := []string{
+		fmt.Sprintf("size: %d", f.Info.GetSize()),
+		fmt.Sprintf("mode: %d", f.Info.GetMode()),
+		fmt.Sprintf("mtime: %s", f.Info.GetModified().AsTime().UTC().Format(timeReportFormatNano)),
+		fmt.Sprintf("uid: %d", f.Stat.GetUid()),
+		fmt.Sprintf("gid: %d", f.Stat.GetGid()),
+	}
+	for _, fp := range f.Fingerprint {
+		summary = append(summary, fmt.Sprintf("fingerprint: %s:%s", fp.Method, fp.Value))
+	}
+	return strings.Join(summary, "\n")
+}
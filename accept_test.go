@@ -0,0 +1,152 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	tspb "google.golang.org/protobuf/types/known/timestamppb"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+func TestSuppressAcceptedDiffs(t *testing.T) {
+	before := &fspb.Walk{
+		Id: "1",
+		File: []*fspb.File{
+			{Path: "/etc/hosts", Info: &fspb.FileInfo{Mode: 0644, Size: 10}},
+			{Path: "/etc/passwd", Info: &fspb.FileInfo{Mode: 0644, Size: 20}},
+			{Path: "/etc/old", Info: &fspb.FileInfo{Mode: 0644, Size: 30}},
+		},
+	}
+	after := &fspb.Walk{
+		Id: "2",
+		File: []*fspb.File{
+			{Path: "/etc/hosts", Info: &fspb.FileInfo{Mode: 0644, Size: 11}},
+			{Path: "/etc/passwd", Info: &fspb.FileInfo{Mode: 0644, Size: 20}},
+			{Path: "/etc/new", Info: &fspb.FileInfo{Mode: 0644, Size: 40}},
+		},
+	}
+	r := &Reporter{config: &fspb.ReportConfig{}}
+	report, err := r.Compare(before, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	if len(report.Added) != 1 || len(report.Deleted) != 1 || len(report.Modified) != 1 {
+		t.Fatalf("Compare() = %d added, %d deleted, %d modified; want 1 each", len(report.Added), len(report.Deleted), len(report.Modified))
+	}
+
+	// Accept all diffs and persist them.
+	path := filepath.Join(t.TempDir(), "accepted.asciipb")
+	accepted, err := r.ReadAcceptedDiffs(path)
+	if err != nil {
+		t.Fatalf("ReadAcceptedDiffs() of a missing file: %v", err)
+	}
+	report.AcceptDiffs(accepted)
+	if err := r.WriteAcceptedDiffs(path, accepted); err != nil {
+		t.Fatalf("WriteAcceptedDiffs() error: %v", err)
+	}
+	accepted, err = r.ReadAcceptedDiffs(path)
+	if err != nil {
+		t.Fatalf("ReadAcceptedDiffs() error: %v", err)
+	}
+
+	// Re-running the comparison reports nothing.
+	report, err = r.Compare(before, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	if n := report.SuppressAcceptedDiffs(accepted); n != 3 {
+		t.Errorf("SuppressAcceptedDiffs() = %d, want 3", n)
+	}
+	if !report.Empty() {
+		t.Errorf("report with accepted diffs only is not empty: %d added, %d deleted, %d modified", len(report.Added), len(report.Deleted), len(report.Modified))
+	}
+	if got, _ := report.Counter.Get("accepted-diffs-suppressed"); got != 3 {
+		t.Errorf("accepted-diffs-suppressed = %d, want 3", got)
+	}
+
+	// A further change of an accepted file is reported again.
+	after.File[0].Info.Size = 12
+	after.File[2].Info.Mode = 0600
+	report, err = r.Compare(before, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	if n := report.SuppressAcceptedDiffs(accepted); n != 1 {
+		t.Errorf("SuppressAcceptedDiffs() = %d, want 1", n)
+	}
+	var got []string
+	for _, a := range append(report.Added, report.Modified...) {
+		got = append(got, a.After.Path)
+	}
+	if diff := cmp.Diff([]string{"/etc/new", "/etc/hosts"}, got); diff != "" {
+		t.Errorf("reported diffs after suppression: diff (-want +got):\n%s", diff)
+	}
+
+	// Accepted changes of critical paths are still reported.
+	after.File[0].Info.Size = 11
+	after.File[2].Info.Mode = 0644
+	r.config.CriticalPaths = []string{"/etc/old"}
+	report, err = r.Compare(before, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	if n := report.SuppressAcceptedDiffs(accepted); n != 2 {
+		t.Errorf("SuppressAcceptedDiffs() with a critical path = %d, want 2", n)
+	}
+	if !report.CriticalChangeDetected || len(report.Deleted) != 1 || report.Deleted[0].Before.Path != "/etc/old" {
+		t.Errorf("SuppressAcceptedDiffs() with a critical path left critical %v, deleted %v; want the critical /etc/old", report.CriticalChangeDetected, report.Deleted)
+	}
+}
+
+func TestSuppressAcceptedDiffsDisplayTimezone(t *testing.T) {
+	mtime := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	newMtime := mtime.Add(time.Hour)
+	before := &fspb.Walk{Id: "1", File: []*fspb.File{{
+		Path: "/etc/hosts",
+		Info: &fspb.FileInfo{Modified: tspb.New(mtime)},
+		Stat: &fspb.FileStat{Mtime: tspb.New(mtime), Ctime: tspb.New(mtime)},
+	}}}
+	after := &fspb.Walk{Id: "2", File: []*fspb.File{{
+		Path: "/etc/hosts",
+		Info: &fspb.FileInfo{Modified: tspb.New(newMtime)},
+		Stat: &fspb.FileStat{Mtime: tspb.New(newMtime), Ctime: tspb.New(newMtime)},
+	}}}
+
+	r, err := ReporterFromConfigBytes([]byte(`displayTimezone = "Asia/Tokyo"`))
+	if err != nil {
+		t.Fatalf("ReporterFromConfigBytes() error: %v", err)
+	}
+	report, err := r.Compare(before, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	accepted := &fspb.AcceptedDiffs{}
+	report.AcceptDiffs(accepted)
+
+	// Displaying the timestamps in another zone doesn't change the accepted diff.
+	r = &Reporter{config: &fspb.ReportConfig{}}
+	report, err = r.Compare(before, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	if n := report.SuppressAcceptedDiffs(accepted); n != 1 {
+		t.Errorf("SuppressAcceptedDiffs() with another displayTimezone = %d, want 1", n)
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/exp/slices"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/google/fswalker/internal/metrics"
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+// reportCacheVersion is part of every cache key, so bumping it invalidates all
+// cached Reports, e.g. after the fields of Report or the diffing changed.
+const reportCacheVersion = 3
+
+// cachedReport is the serialized form of a Report in the comparison cache.
+// The compared Walks aren't stored, they are restored from the WalkFiles.
+type cachedReport struct {
+	Added, Deleted, Modified, Errors []cachedAction
+	WorldWritable, PermissionChanges []cachedAction
+	KnownBad, DirectorySizeChanges   []cachedAction
+	Counter                          map[string]int64
+	Warnings                         []string
+	Baseline, CriticalChangeDetected bool
+}
+
+// cachedAction is the serialized form of an ActionData.
+type cachedAction struct {
+	// Before and After are the marshaled files, nil if unset.
+	Before, After []byte
+	Diff          string
+	Fields        []FieldDiff
+	// Err is the message of the error, empty if there was none.
+	Err      string
+	Critical bool
+}
+
+// CompareWalkFiles compares the Walks of before and after like Compare. before
+// may be nil to create a baseline.
+// If CacheDir is set, the Report is stored there keyed by the fingerprints of the
+// WalkFiles and the comparison settings, and later comparisons of the same pair
+// return the stored Report instead of diffing the Walks again. Comparisons with
+// a PathTransform or of WalkFiles without fingerprints aren't cached.
+// Cached Reports are trusted as they are, so CacheDir is refused if it isn't
+// owned by the current user or is writable by other users. Unreadable cached
+// Reports are replaced and noted in the Warnings of the Report.
+func (r *Reporter) CompareWalkFiles(before, after *WalkFile) (*Report, error) {
+	var beforeWalk, afterWalk *fspb.Walk
+	if before != nil {
+		beforeWalk = before.Walk
+	}
+	if after != nil {
+		afterWalk = after.Walk
+	}
+	key, ok := r.cacheKey(before, after)
+	if !ok {
+		return r.Compare(beforeWalk, afterWalk)
+	}
+	if err := checkCacheDir(r.CacheDir); err != nil {
+		return nil, fmt.Errorf("refusing to use the report cache: %w", err)
+	}
+	path := filepath.Join(r.CacheDir, key+".report")
+	report, readErr := readCachedReport(path)
+	if readErr == nil {
+		report.WalkBefore = beforeWalk
+		report.WalkAfter = afterWalk
+		return report, nil
+	}
+
+	report, err := r.Compare(beforeWalk, afterWalk)
+	if err != nil {
+		return nil, err
+	}
+	// The cache only saves time, failing to update it doesn't fail the comparison.
+	if err := writeCachedReport(path, report); err != nil && r.Verbose {
+		fmt.Printf("Unable to cache report in %q: %v\n", path, err)
+	}
+	if !errors.Is(readErr, os.ErrNotExist) {
+		// Added after caching the Report, as it only concerns this comparison.
+		report.Warnings = append(report.Warnings, fmt.Sprintf("ignored unreadable cached report %q: %v", path, readErr))
+	}
+	return report, nil
+}
+
+// cacheKey returns the key of the comparison of before and after in the
+// comparison cache, and false if it can't be cached.
+// Any setting affecting the content of the Report is part of the key.
+func (r *Reporter) cacheKey(before, after *WalkFile) (string, bool) {
+	if r.CacheDir == "" || r.PathTransform != nil || after == nil || after.Fingerprint == nil {
+		return "", false
+	}
+	if before != nil && before.Fingerprint == nil {
+		return "", false
+	}
+	config, err := proto.MarshalOptions{Deterministic: true}.Marshal(r.config)
+	if err != nil {
+		return "", false
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "fswalker report cache %d\n", reportCacheVersion)
+	for _, w := range []*WalkFile{before, after} {
+		if w == nil {
+			fmt.Fprintln(h, "baseline")
+			continue
+		}
+		fmt.Fprintf(h, "%s:%s\n", w.Fingerprint.Method, w.Fingerprint.Value)
+	}
+	fmt.Fprintf(h, "%x\n", sha256.Sum256(config))
+	fmt.Fprintf(h, "ignore-hash-method-changes=%t upgrade-walks=%t\n", r.IgnoreHashMethodChanges, r.UpgradeWalks)
+	hashes := make([]string, 0, len(r.KnownHashes))
+	for hash, desc := range r.KnownHashes {
+		hashes = append(hashes, fmt.Sprintf("%q %q", hash, desc))
+	}
+	slices.Sort(hashes)
+	for _, hash := range hashes {
+		fmt.Fprintln(h, hash)
+	}
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+// readCachedReport reads the Report cached at path. Its Walks are left unset.
+func readCachedReport(path string) (*Report, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &cachedReport{}
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(c); err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		Counter:                &metrics.Counter{},
+		Warnings:               c.Warnings,
+		Baseline:               c.Baseline,
+		CriticalChangeDetected: c.CriticalChangeDetected,
+	}
+	for m, v := range c.Counter {
+		report.Counter.Add(v, m)
+	}
+	for _, l := range []struct {
+		src []cachedAction
+		dst *[]ActionData
+	}{
+		{c.Added, &report.Added},
+		{c.Deleted, &report.Deleted},
+		{c.Modified, &report.Modified},
+		{c.Errors, &report.Errors},
+		{c.WorldWritable, &report.WorldWritable},
+		{c.PermissionChanges, &report.PermissionChanges},
+		{c.KnownBad, &report.KnownBad},
+		{c.DirectorySizeChanges, &report.DirectorySizeChanges},
+	} {
+		for _, ca := range l.src {
+			a := ActionData{Diff: ca.Diff, Fields: ca.Fields, Critical: ca.Critical}
+			if a.Before, err = unmarshalCachedFile(ca.Before); err != nil {
+				return nil, err
+			}
+			if a.After, err = unmarshalCachedFile(ca.After); err != nil {
+				return nil, err
+			}
+			if ca.Err != "" {
+				a.Err = errors.New(ca.Err)
+			}
+			*l.dst = append(*l.dst, a)
+		}
+	}
+	return report, nil
+}
+
+// writeCachedReport stores report at path, creating its directory if needed.
+// The file is replaced atomically so concurrent readers never see partial Reports.
+func writeCachedReport(path string, report *Report) error {
+	c := &cachedReport{
+		Counter:                report.Counter.Snapshot(),
+		Warnings:               report.Warnings,
+		Baseline:               report.Baseline,
+		CriticalChangeDetected: report.CriticalChangeDetected,
+	}
+	for _, l := range []struct {
+		src []ActionData
+		dst *[]cachedAction
+	}{
+		{report.Added, &c.Added},
+		{report.Deleted, &c.Deleted},
+		{report.Modified, &c.Modified},
+		{report.Errors, &c.Errors},
+		{report.WorldWritable, &c.WorldWritable},
+		{report.PermissionChanges, &c.PermissionChanges},
+		{report.KnownBad, &c.KnownBad},
+		{report.DirectorySizeChanges, &c.DirectorySizeChanges},
+	} {
+		for _, a := range l.src {
+			ca := cachedAction{Diff: a.Diff, Fields: a.Fields, Critical: a.Critical}
+			var err error
+			if ca.Before, err = marshalCachedFile(a.Before); err != nil {
+				return err
+			}
+			if ca.After, err = marshalCachedFile(a.After); err != nil {
+				return err
+			}
+			if a.Err != nil {
+				ca.Err = a.Err.Error()
+			}
+			*l.dst = append(*l.dst, ca)
+		}
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(c); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// marshalCachedFile marshals f for the comparison cache, returning nil for a nil File.
+func marshalCachedFile(f *fspb.File) ([]byte, error) {
+	if f == nil {
+		return nil, nil
+	}
+	return proto.MarshalOptions{Deterministic: true}.Marshal(f)
+}
+
+// unmarshalCachedFile is the inverse of marshalCachedFile.
+func unmarshalCachedFile(b []byte) (*fspb.File, error) {
+	if b == nil {
+		return nil, nil
+	}
+	f := &fspb.File{}
+	if err := proto.Unmarshal(b, f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
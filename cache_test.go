@@ -0,0 +1,207 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+// reportCmpOpts compare Reports read from the comparison cache with computed ones.
+var reportCmpOpts = []cmp.Option{
+	protocmp.Transform(),
+	cmpopts.EquateEmpty(),
+	cmpopts.IgnoreFields(Report{}, "Counter"),
+	cmpopts.IgnoreUnexported(Report{}),
+	cmp.Comparer(func(a, b error) bool {
+		if a == nil || b == nil {
+			return a == nil && b == nil
+		}
+		return a.Error() == b.Error()
+	}),
+}
+
+func TestCompareWalkFilesCache(t *testing.T) {
+	newWalkFile := func(id, fp string, files ...*fspb.File) *WalkFile {
+		return &WalkFile{
+			Walk:        &fspb.Walk{Id: id, File: files},
+			Fingerprint: &fspb.Fingerprint{Method: fspb.Fingerprint_SHA256, Value: fp},
+		}
+	}
+	before := newWalkFile("1", "aaaa",
+		&fspb.File{Path: "/a", Info: &fspb.FileInfo{Size: 1}},
+		&fspb.File{Path: "/b", Info: &fspb.FileInfo{}},
+	)
+	after := newWalkFile("2", "bbbb",
+		&fspb.File{Path: "/a", Info: &fspb.FileInfo{Size: 2}},
+		&fspb.File{Path: "/c", Info: &fspb.FileInfo{}},
+	)
+	dir := t.TempDir()
+	r := &Reporter{config: &fspb.ReportConfig{}, CacheDir: dir}
+
+	want, err := r.CompareWalkFiles(before, after)
+	if err != nil {
+		t.Fatalf("CompareWalkFiles() error: %v", err)
+	}
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) != 1 {
+		t.Fatalf("cache dir holds %d entries (%v), want 1", len(entries), err)
+	}
+
+	// Changing the Walks behind the unchanged fingerprints shows whether the
+	// second comparison is served from the cache.
+	after.Walk.File = append(after.Walk.File, &fspb.File{Path: "/d", Info: &fspb.FileInfo{}})
+	got, err := r.CompareWalkFiles(before, after)
+	if err != nil {
+		t.Fatalf("CompareWalkFiles() error: %v", err)
+	}
+	if diff := cmp.Diff(want, got, reportCmpOpts...); diff != "" {
+		t.Errorf("cached report differs (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(want.Counter.Snapshot(), got.Counter.Snapshot()); diff != "" {
+		t.Errorf("cached counters differ (-want +got):\n%s", diff)
+	}
+
+	// A new fingerprint invalidates the cached Report.
+	after.Fingerprint.Value = "cccc"
+	got, err = r.CompareWalkFiles(before, after)
+	if err != nil {
+		t.Fatalf("CompareWalkFiles() error: %v", err)
+	}
+	if len(got.Added) != 2 {
+		t.Errorf("len(Added) = %d after fingerprint change, want 2", len(got.Added))
+	}
+
+	// So do different report settings.
+	r.config.Exclude = []string{"/d"}
+	got, err = r.CompareWalkFiles(before, after)
+	if err != nil {
+		t.Fatalf("CompareWalkFiles() error: %v", err)
+	}
+	if len(got.Added) != 1 {
+		t.Errorf("len(Added) = %d after config change, want 1", len(got.Added))
+	}
+}
+
+func TestCachedReportRoundTrip(t *testing.T) {
+	before := &fspb.Walk{Id: "1", File: []*fspb.File{
+		{Path: "/a", Info: &fspb.FileInfo{Size: 1}},
+		{Path: "/gone", Info: &fspb.FileInfo{}},
+	}}
+	after := &fspb.Walk{Id: "2", File: []*fspb.File{
+		{Path: "/a", Info: &fspb.FileInfo{Size: 2}},
+		{Path: "/new", Info: &fspb.FileInfo{}},
+	}}
+	r := &Reporter{config: &fspb.ReportConfig{}}
+	want, err := r.Compare(before, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	want.Errors = append(want.Errors, ActionData{Before: before.File[0], Err: errors.New("boom")})
+	want.Warnings = append(want.Warnings, "something odd")
+
+	path := filepath.Join(t.TempDir(), "sub", "x.report")
+	if err := writeCachedReport(path, want); err != nil {
+		t.Fatalf("writeCachedReport() error: %v", err)
+	}
+	got, err := readCachedReport(path)
+	if err != nil {
+		t.Fatalf("readCachedReport() error: %v", err)
+	}
+	got.WalkBefore, got.WalkAfter = want.WalkBefore, want.WalkAfter
+	if diff := cmp.Diff(want, got, reportCmpOpts...); diff != "" {
+		t.Errorf("round trip differs (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(want.Counter.Snapshot(), got.Counter.Snapshot()); diff != "" {
+		t.Errorf("round trip counters differ (-want +got):\n%s", diff)
+	}
+}
+
+func TestCompareWalkFilesUncached(t *testing.T) {
+	dir := t.TempDir()
+	r := &Reporter{
+		config:        &fspb.ReportConfig{},
+		CacheDir:      dir,
+		PathTransform: func(p string) string { return p },
+	}
+	after := &WalkFile{
+		Walk:        &fspb.Walk{Id: "2", File: []*fspb.File{{Path: "/a", Info: &fspb.FileInfo{}}}},
+		Fingerprint: &fspb.Fingerprint{Method: fspb.Fingerprint_SHA256, Value: "bbbb"},
+	}
+	if _, err := r.CompareWalkFiles(nil, after); err != nil {
+		t.Fatalf("CompareWalkFiles() error: %v", err)
+	}
+	r.PathTransform = nil
+	after.Fingerprint = nil
+	if _, err := r.CompareWalkFiles(nil, after); err != nil {
+		t.Fatalf("CompareWalkFiles() error: %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("cache dir holds %d entries, want none", len(entries))
+	}
+	if _, err := r.CompareWalkFiles(nil, nil); err == nil {
+		t.Error("CompareWalkFiles(nil, nil) succeeded, want error")
+	}
+}
+
+func TestCompareWalkFilesUnreadableCache(t *testing.T) {
+	dir := t.TempDir()
+	r := &Reporter{config: &fspb.ReportConfig{}, CacheDir: dir}
+	after := &WalkFile{
+		Walk:        &fspb.Walk{Id: "2", File: []*fspb.File{{Path: "/a", Info: &fspb.FileInfo{}}}},
+		Fingerprint: &fspb.Fingerprint{Method: fspb.Fingerprint_SHA256, Value: "bbbb"},
+	}
+	key, _ := r.cacheKey(nil, after)
+	if err := os.WriteFile(filepath.Join(dir, key+".report"), []byte("garbage"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	report, err := r.CompareWalkFiles(nil, after)
+	if err != nil {
+		t.Fatalf("CompareWalkFiles() error: %v", err)
+	}
+	if len(report.Added) != 1 || len(report.Warnings) != 1 {
+		t.Errorf("CompareWalkFiles() = %d added, warnings %q; want 1 added and a warning about the cached report", len(report.Added), report.Warnings)
+	}
+	// The replaced cached Report is read without warnings.
+	if report, err = r.CompareWalkFiles(nil, after); err != nil || len(report.Warnings) != 0 {
+		t.Errorf("CompareWalkFiles() = warnings %q, %v; want none", report.Warnings, err)
+	}
+}
+
+func TestCompareWalkFilesUnsafeCacheDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("cache directory permissions aren't checked on Windows")
+	}
+	dir := t.TempDir()
+	if err := os.Chmod(dir, 0777); err != nil {
+		t.Fatal(err)
+	}
+	r := &Reporter{config: &fspb.ReportConfig{}, CacheDir: dir}
+	after := &WalkFile{
+		Walk:        &fspb.Walk{Id: "2"},
+		Fingerprint: &fspb.Fingerprint{Method: fspb.Fingerprint_SHA256, Value: "bbbb"},
+	}
+	if _, err := r.CompareWalkFiles(nil, after); err == nil {
+		t.Error("CompareWalkFiles() with a world-writable cache dir succeeded, want error")
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unix
+
+package fswalker
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// checkCacheDir returns an error if the existing directory dir isn't owned by the
+// current user or is writable by other users, who could plant forged Reports in it.
+func checkCacheDir(dir string) error {
+	info, err := os.Stat(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		// It is created accessible only by the current user.
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if st, ok := info.Sys().(*syscall.Stat_t); ok && int(st.Uid) != os.Geteuid() {
+		return fmt.Errorf("cache directory %q is owned by uid %d, not by the current user", dir, st.Uid)
+	}
+	if info.Mode().Perm()&0022 != 0 {
+		return fmt.Errorf("cache directory %q is writable by other users (%v)", dir, info.Mode().Perm())
+	}
+	return nil
+}
@@ -16,27 +16,52 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"os"
 	"strings"
 
 	"golang.org/x/exp/slices"
 
 	"github.com/google/fswalker"
+	fspb "github.com/google/fswalker/proto/fswalker"
 )
 
 const labelPfx = "before-files"
 
+// Exit codes signaling the outcome of the report.
+const (
+	exitDiff           = 1
+	exitCriticalChange = 3
+)
+
 var (
-	configFile   = flag.String("c", "", "required report config file to use")
+	configFile   = flag.String("c", "", "report config file to use, required unless the config is set in the FSWALKER_REPORT_CONFIG environment variable")
 	walkPath     = flag.String("walk-path", "", "path to search for Walks")
-	reviewFile   = flag.String("review-file", "", "path to the file containing a list of last-known-good states - this needs to be writeable")
+	reviewFile   = flag.String("review-file", "", "comma separated paths to the files containing a list of last-known-good states, searched in order - reviews are written to the first writable one already holding a review of the host, or else to the first writable one")
 	hostname     = flag.String("hostname", "", "host to review the differences for")
-	beforeFile   = flag.String("before-file", "", "path to the file to compare against (last known good typically)")
-	afterFile    = flag.String("after-file", "", "path to the file to compare with the before state")
+	beforeFile   = flag.String("before-file", "", "path to the file to compare against (last known good typically), - to read it from stdin")
+	afterFile    = flag.String("after-file", "", "path to the file to compare with the before state, - to read it from stdin")
 	verbose      = flag.Bool("verbose", false, "print additional output for each file which changed")
+	verboseProto = flag.Bool("verbose-proto", false, "print the complete before and after file info of each modified file")
 	updateReview = flag.Bool("update-review", false, "ask to update the \"last known good\" review")
+	statsOnly    = flag.Bool("stats-only", false, "only print the number of changes and metrics instead of the full report")
+	upgrade      = flag.Bool("upgrade-walks", false, "migrate walks written by older versions of the walker so they can be compared")
+	noHash       = flag.Bool("no-hash", false, "don't report files as modified whose fingerprints were built with a different method before, e.g. while migrating hash algorithms")
+	swap         = flag.Bool("swap", false, "swap the before and after walks, e.g. if they were passed in the wrong order")
+	failOnDiff   = flag.Bool("fail-on-diff", false, "exit with a non-zero status if any differences were found")
+	maxDiffs     = flag.Int("max-diffs", 0, "maximum number of entries to print per section of the report, 0 means no limit")
+	keyFile      = flag.String("decrypt-key-file", "", "path to the key file used to decrypt encrypted walks")
+	manifestFile = flag.String("manifest", "", "path to write a checksum manifest of the after walk to (compatible with e.g. sha256sum -c)")
+	manifestAlgo = flag.String("manifest-algo", "sha256", "fingerprint method of the manifest, sha256 or sha512 (recorded by the walker if hashSha512 is set in the policy)")
+	patchFile    = flag.String("patch-file", "", "path to write the metadata changes of modified files to in unified diff format")
+	knownHashes  = flag.String("known-hashes", "", "path to a file of known bad hashes, one per line, to flag matching files of the after walk")
+	metricsFile  = flag.String("metrics-file", "", "path to write the report totals to in the Prometheus text format, e.g. for the node exporter textfile collector")
+	cacheDir     = flag.String("cache-dir", "", "directory to cache reports in, so comparing the same walks again reuses the previous report; cached reports are trusted, so it must be owned by and writable only by the current user")
+	acceptedFile = flag.String("accepted-diffs", "", "path to a file of accepted diffs, which are not reported again while they stay the same")
+	acceptDiffs  = flag.Bool("accept-diffs", false, "ask to add the reported diffs to the -accepted-diffs file")
 )
 
 func askUpdateReviews() bool {
@@ -46,8 +71,27 @@ func askUpdateReviews() bool {
 	return strings.ToLower(strings.TrimSpace(input)) == "y"
 }
 
-func walksByLatest(r *fswalker.Reporter, hostname, reviewFile, walkPath string) (*fswalker.WalkFile, *fswalker.WalkFile, error) {
-	before, err := r.ReadLastGoodWalk(hostname, reviewFile)
+func askAcceptDiffs() bool {
+	fmt.Print("Do you want to accept the reported diffs [y/N]: ")
+	var input string
+	fmt.Scanln(&input)
+	return strings.ToLower(strings.TrimSpace(input)) == "y"
+}
+
+// reviewFiles returns the comma separated paths of the -review-file flag,
+// ignoring surrounding whitespace and empty entries.
+func reviewFiles() []string {
+	var files []string
+	for _, f := range strings.Split(*reviewFile, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+func walksByLatest(r *fswalker.Reporter, hostname string, reviewFiles []string, walkPath string) (*fswalker.WalkFile, *fswalker.WalkFile, error) {
+	before, err := r.ReadLastGoodWalk(hostname, reviewFiles...)
 	if err != nil {
 		return nil, nil, fmt.Errorf("unable to load last good walk for %s: %v", hostname, err)
 	}
@@ -58,14 +102,28 @@ func walksByLatest(r *fswalker.Reporter, hostname, reviewFile, walkPath string)
 	return before, after, nil
 }
 
+// stdinPath is the walk file path denoting to read the walk from stdin.
+const stdinPath = "-"
+
+// readWalk reads the walk at path, or from stdin if path is stdinPath.
+func readWalk(r *fswalker.Reporter, path string) (*fswalker.WalkFile, error) {
+	if path == stdinPath {
+		return r.ReadWalkFrom(os.Stdin, path)
+	}
+	return r.ReadWalk(path)
+}
+
 func walksByFiles(r *fswalker.Reporter, beforeFile, afterFile string) (*fswalker.WalkFile, *fswalker.WalkFile, error) {
-	after, err := r.ReadWalk(afterFile)
+	if beforeFile == stdinPath && afterFile == stdinPath {
+		return nil, nil, errors.New("only one of the walks can be read from stdin")
+	}
+	after, err := readWalk(r, afterFile)
 	if err != nil {
 		return nil, nil, fmt.Errorf("File cannot be read: %s", afterFile)
 	}
 	var before *fswalker.WalkFile
 	if beforeFile != "" {
-		before, err = r.ReadWalk(beforeFile)
+		before, err = readWalk(r, beforeFile)
 		if err != nil {
 			return nil, nil, fmt.Errorf("File cannot be read: %s", beforeFile)
 		}
@@ -73,17 +131,133 @@ func walksByFiles(r *fswalker.Reporter, beforeFile, afterFile string) (*fswalker
 	return before, after, nil
 }
 
+// exitCode returns the exit code for report, or 0 if the reporter should succeed.
+// A critical change always fails, other differences only if failOnDiff is set.
+func exitCode(report *fswalker.Report, failOnDiff bool) int {
+	switch {
+	case report.CriticalChangeDetected:
+		return exitCriticalChange
+	case failOnDiff && !report.Empty():
+		return exitDiff
+	}
+	return 0
+}
+
+// createFile creates or truncates the file at path for writing, making sure it
+// ends up with mode regardless of the umask and of whether it existed before.
+func createFile(path string, mode os.FileMode) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Chmod(mode); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// manifestMethod returns the fingerprint method of the manifest algorithm algo,
+// e.g. "sha256", which needs to be one the walker records.
+func manifestMethod(algo string) (fspb.Fingerprint_Method, error) {
+	var names []string
+	for _, m := range fswalker.ManifestMethods() {
+		if strings.EqualFold(m.String(), algo) {
+			return m, nil
+		}
+		names = append(names, strings.ToLower(m.String()))
+	}
+	return fspb.Fingerprint_UNKNOWN, fmt.Errorf("unsupported manifest algorithm %q, supported are: %s", algo, strings.Join(names, ", "))
+}
+
+func writeManifest(path string, method fspb.Fingerprint_Method, walk *fspb.Walk, mode os.FileMode) error {
+	f, err := createFile(path, mode)
+	if err != nil {
+		return err
+	}
+	n, err := fswalker.WriteManifest(f, walk, method)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s manifest of %d files to %q\n", strings.ToLower(method.String()), n, path)
+	return nil
+}
+
+func writePatch(path string, report *fswalker.Report, mode os.FileMode) error {
+	f, err := createFile(path, mode)
+	if err != nil {
+		return err
+	}
+	n, err := fswalker.WritePatch(f, report)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote metadata changes of %d files to %q\n", n, path)
+	return nil
+}
+
+// writeMetrics writes the report totals to path in the Prometheus text format.
+// The file is replaced atomically so collectors never read a partial file.
+func writeMetrics(path string, report *fswalker.Report, mode os.FileMode) error {
+	tmp := path + ".tmp"
+	f, err := createFile(tmp, mode)
+	if err != nil {
+		return err
+	}
+	if err := fswalker.WritePrometheus(f, report.WalkDiffStats()); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
 func main() {
 	flag.Parse()
+	manifestMeth, err := manifestMethod(*manifestAlgo)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// Loading configs and walks.
-	if *configFile == "" {
-		log.Fatal("-c needs to be specified")
+	if _, ok := os.LookupEnv(fswalker.ReportConfigEnvVar); *configFile == "" && !ok {
+		log.Fatalf("-c or %s needs to be specified", fswalker.ReportConfigEnvVar)
 	}
 	rptr, err := fswalker.ReporterFromConfigFile(*configFile, *verbose)
 	if err != nil {
 		log.Fatal(err)
 	}
+	rptr.MaxDiffs = *maxDiffs
+	rptr.VerboseProto = *verboseProto
+	rptr.UpgradeWalks = *upgrade
+	rptr.IgnoreHashMethodChanges = *noHash
+	rptr.CacheDir = *cacheDir
+	outputMode, err := rptr.OutputFileMode()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *keyFile != "" {
+		if rptr.EncryptionKey, err = fswalker.ReadKeyFile(*keyFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *knownHashes != "" {
+		if rptr.KnownHashes, err = fswalker.ReadKnownHashes(*knownHashes); err != nil {
+			log.Fatal(err)
+		}
+	}
 
 	var before, after *fswalker.WalkFile
 	var errWalks error
@@ -91,8 +265,12 @@ func main() {
 		if *afterFile != "" || *beforeFile != "" {
 			log.Fatalf("[hostname review-file walk-path] and [[before-file] after-file] are mutually exclusive")
 		}
-		before, after, errWalks = walksByLatest(rptr, *hostname, *reviewFile, *walkPath)
+		before, after, errWalks = walksByLatest(rptr, *hostname, reviewFiles(), *walkPath)
 	} else if *afterFile != "" {
+		if (*updateReview || *acceptDiffs) && (*beforeFile == stdinPath || *afterFile == stdinPath) {
+			// Their questions are answered on stdin, and a review can't refer to a walk read from it.
+			log.Fatal("-update-review and -accept-diffs can't be used with a walk read from stdin")
+		}
 		before, after, errWalks = walksByFiles(rptr, *beforeFile, *afterFile)
 	} else {
 		log.Fatalf("either [hostname review-file walk-path] OR [[before-file] after-file] need to be specified")
@@ -100,25 +278,41 @@ func main() {
 	if errWalks != nil {
 		log.Fatal(errWalks)
 	}
+	if *swap {
+		if before == nil {
+			log.Fatal("-swap needs a before walk")
+		}
+		before, after = after, before
+	}
 
-	var report *fswalker.Report
-	var errReport error
-	if before == nil {
-		report, errReport = rptr.Compare(nil, after.Walk)
-	} else {
-		report, errReport = rptr.Compare(before.Walk, after.Walk)
+	report, err := rptr.CompareWalkFiles(before, after)
+	if err != nil {
+		log.Fatal(err)
 	}
-	if errReport != nil {
-		log.Fatal(errReport)
+	if *acceptDiffs && *acceptedFile == "" {
+		log.Fatal("-accept-diffs needs -accepted-diffs")
+	}
+	accepted := &fspb.AcceptedDiffs{}
+	if *acceptedFile != "" {
+		if accepted, err = rptr.ReadAcceptedDiffs(*acceptedFile); err != nil {
+			log.Fatal(err)
+		}
+		if n := report.SuppressAcceptedDiffs(accepted); n > 0 {
+			fmt.Printf("Suppressed %d previously accepted diffs.\n", n)
+		}
 	}
 
 	// Processing and output.
-	if before == nil {
-		fmt.Println("No before walk found. Using after walk only.")
+	if report.Baseline {
+		fmt.Println("No before walk found. Creating a baseline from the after walk only.")
+	}
+	if *statsOnly {
+		rptr.PrintStatsSummary(report)
+	} else {
+		rptr.PrintReportSummary(report)
+		rptr.PrintRuleSummary(report)
+		rptr.PrintDiffSummary(report)
 	}
-	rptr.PrintReportSummary(report)
-	rptr.PrintRuleSummary(report)
-	rptr.PrintDiffSummary(report)
 
 	// sort so "before-files" metrics are first
 	metrics := report.Counter.Metrics()
@@ -138,12 +332,44 @@ func main() {
 		fmt.Printf("[%-30s] = %6d\n", k, v)
 	}
 
+	if *manifestFile != "" {
+		if err := writeManifest(*manifestFile, manifestMeth, after.Walk, outputMode); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *patchFile != "" {
+		if err := writePatch(*patchFile, report, outputMode); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *metricsFile != "" {
+		if err := writeMetrics(*metricsFile, report, outputMode); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Update accepted diffs file if desired.
+	if *acceptDiffs && !report.Empty() && askAcceptDiffs() {
+		report.AcceptDiffs(accepted)
+		if err := rptr.WriteAcceptedDiffs(*acceptedFile, accepted); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	// Update reviews file if desired.
-	if *updateReview && askUpdateReviews() {
-		if err := rptr.UpdateReviewProto(after, *reviewFile); err != nil {
+	if *updateReview && after.Walk.GetIncomplete() {
+		fmt.Println("not updating reviews file as the walk is incomplete")
+	} else if *updateReview && askUpdateReviews() {
+		if err := rptr.UpdateReviewProto(after, reviewFiles()...); err != nil {
 			log.Fatal(err)
 		}
 	} else {
 		fmt.Println("not updating reviews file")
 	}
+
+	if code := exitCode(report, *failOnDiff); code != 0 {
+		os.Exit(code)
+	}
 }
@@ -0,0 +1,116 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/google/fswalker"
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+func TestExitCode(t *testing.T) {
+	diff := &fswalker.Report{Added: []fswalker.ActionData{{}}}
+	critical := &fswalker.Report{Modified: []fswalker.ActionData{{}}, CriticalChangeDetected: true}
+	for _, tc := range []struct {
+		desc       string
+		report     *fswalker.Report
+		failOnDiff bool
+		want       int
+	}{
+		{desc: "no diff", report: &fswalker.Report{}, failOnDiff: true, want: 0},
+		{desc: "diff", report: diff, failOnDiff: false, want: 0},
+		{desc: "diff with fail-on-diff", report: diff, failOnDiff: true, want: exitDiff},
+		{desc: "critical change", report: critical, failOnDiff: false, want: exitCriticalChange},
+		{desc: "critical change with fail-on-diff", report: critical, failOnDiff: true, want: exitCriticalChange},
+	} {
+		if got := exitCode(tc.report, tc.failOnDiff); got != tc.want {
+			t.Errorf("exitCode() for %s = %d; want %d", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestOutputFileModes(t *testing.T) {
+	dir := t.TempDir()
+	report := &fswalker.Report{WalkAfter: &fspb.Walk{}}
+	writers := map[string]func(path string, mode os.FileMode) error{
+		"manifest": func(path string, mode os.FileMode) error {
+			return writeManifest(path, fspb.Fingerprint_SHA256, &fspb.Walk{}, mode)
+		},
+		"patch": func(path string, mode os.FileMode) error {
+			return writePatch(path, report, mode)
+		},
+		"metrics": func(path string, mode os.FileMode) error {
+			return writeMetrics(path, report, mode)
+		},
+	}
+	for name, write := range writers {
+		path := filepath.Join(dir, name)
+		// An existing file gets the mode as well.
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := write(path, 0600); err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm(); got != 0600 {
+			t.Errorf("%s file mode = %v; want %v", name, got, os.FileMode(0600))
+		}
+	}
+}
+
+func TestManifestMethod(t *testing.T) {
+	for _, tc := range []struct {
+		algo    string
+		want    fspb.Fingerprint_Method
+		wantErr bool
+	}{
+		{algo: "sha256", want: fspb.Fingerprint_SHA256},
+		{algo: "SHA256", want: fspb.Fingerprint_SHA256},
+		{algo: "sha512", want: fspb.Fingerprint_SHA512},
+		// Known methods which the walker never records.
+		{algo: "fsverity_sha256", wantErr: true},
+		{algo: "md5", wantErr: true},
+	} {
+		got, err := manifestMethod(tc.algo)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("manifestMethod(%q) = %v, %v; want %v, error %v", tc.algo, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
+
+func TestReviewFiles(t *testing.T) {
+	defer func(v string) { *reviewFile = v }(*reviewFile)
+	for _, tc := range []struct {
+		flag string
+		want []string
+	}{
+		{flag: "", want: nil},
+		{flag: "a.asciipb", want: []string{"a.asciipb"}},
+		{flag: "a.asciipb, b.asciipb ,", want: []string{"a.asciipb", "b.asciipb"}},
+	} {
+		*reviewFile = tc.flag
+		if got := reviewFiles(); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("reviewFiles() with -review-file %q = %q; want %q", tc.flag, got, tc.want)
+		}
+	}
+}
@@ -21,32 +21,167 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/google/fswalker"
 	"golang.org/x/exp/slices"
-	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/encoding/protojson"
 
 	fspb "github.com/google/fswalker/proto/fswalker"
 )
 
 var (
-	policyFile    = flag.String("c", "", "required policy file to use")
+	policyFile    = flag.String("c", "", "policy file to use, required unless the policy is set in the FSWALKER_POLICY environment variable")
 	outputFilePfx = flag.String("o", "", "path prefix for the output file to write")
 	verbose       = flag.Bool("v", false, "when set to true, prints all discovered files including a metadata summary")
+	utcFilename   = flag.Bool("utc-filename", false, "when set to true, names the output file with a sortable UTC timestamp")
+	outputMode    = flag.String("output-mode", "", "octal file mode of the output file (e.g. 0400), overrides the policy's outputFileMode")
+	keyFile       = flag.String("encrypt-key-file", "", "path to a file containing a 32 byte key (raw or hex) to encrypt the output file with")
+	progress      = flag.Bool("progress", false, "when set to true, counts all files first and prints the progress and estimated time remaining")
+	baselineFile  = flag.String("baseline", "", "path to a previous walk whose fingerprints are reused for unchanged files if the policy sets hashChangedOnly")
+	dumpPolicy    = flag.Bool("dump-policy", false, "when set to true, prints the effective policy with all defaults applied as JSON and exits without walking")
+	detailed      = flag.Bool("detailed-metrics", false, "when set to true, additionally prints a histogram of file sizes")
+	excludeOutput = flag.Bool("exclude-output", true, "when set to true, excludes the output file and previous walks of this host next to it from the walk")
+	policySchema  = flag.Bool("policy-schema", false, "when set to true, prints a JSON Schema of the policy in its JSON form and exits")
+	selfTest      = flag.Bool("selftest", false, "when set to true, walks a built-in fixture tree, compares it to the expected result and exits")
+	queueDepth    = flag.Int("queue-depth", 0, "number of discovered files to queue for hashing, overriding queueDepth of the policy if positive; raise it if slow storage stalls the traversal")
+
+	includes, excludes, explain stringList
+	labels                      = labelMap{}
 )
 
-func walkCallback(walk *fspb.Walk) error {
-	outpath, err := outputPath(*outputFilePfx)
+func init() {
+	flag.Var(&includes, "include", "path to include in addition to the policy's includes, can be repeated")
+	flag.Var(&excludes, "exclude", "path to exclude in addition to the policy's excludes, can be repeated")
+	flag.Var(&explain, "explain", "path to explain, logs the policy rule which decided whether it is recorded, can be repeated")
+	flag.Var(labels, "label", "key=value label to record in the walk, e.g. the change ticket it is taken for, can be repeated")
+}
+
+// stringList is a flag.Value collecting all values of a repeated flag.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// labelMap is a flag.Value collecting the key=value pairs of a repeated flag.
+type labelMap map[string]string
+
+func (m labelMap) String() string {
+	var pairs []string
+	for k, v := range m {
+		pairs = append(pairs, k+"="+v)
+	}
+	slices.Sort(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (m labelMap) Set(v string) error {
+	key, value, ok := strings.Cut(v, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("%q is not of the form key=value", v)
+	}
+	if _, ok := m[key]; ok {
+		return fmt.Errorf("label %q is given more than once", key)
+	}
+	m[key] = value
+	return nil
+}
+
+// progressInterval is the minimum time between two progress line updates.
+const progressInterval = 500 * time.Millisecond
+
+// encryptionKey is read from keyFile if set.
+var encryptionKey []byte
+
+// walkCallback returns a Walker.WalkCallback writing the walk to outpath.
+func walkCallback(outpath string) fswalker.WalkCallback {
+	return func(walk *fspb.Walk) error {
+		mode := walk.GetPolicy().GetOutputFileMode()
+		if *outputMode != "" {
+			mode = *outputMode
+		}
+		fileMode, err := fswalker.ParseFileMode(mode, fswalker.DefaultWalkFileMode)
+		if err != nil {
+			return err
+		}
+		if encryptionKey != nil {
+			return fswalker.WriteEncryptedWalk(outpath, walk, fileMode, encryptionKey)
+		}
+		return fswalker.WriteWalk(outpath, walk, fileMode)
+	}
+}
+
+// stopSignals stop the walk gracefully: no more files are discovered, but the
+// files found so far are processed and the partial walk is written out.
+var stopSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// stopContext returns a context which is canceled once one of stopSignals is
+// received. Receiving one restores their default handling, so a second signal
+// kills the process if stopping takes too long.
+func stopContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(parent, stopSignals...)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
+
+// outputExcludes returns the excludes keeping the walk from recording its own
+// output file at outpath as well as previous walks of the host next to it,
+// which would otherwise show up as changes in every report. The walk file name
+// pattern of the host matches all of them.
+func outputExcludes(outpath string) ([]string, error) {
+	abs, err := filepath.Abs(outpath)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	walkBytes, err := proto.Marshal(walk)
+	hn, _, err := fswalker.ParseWalkFilename(abs)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	return []string{filepath.Join(filepath.Dir(abs), fswalker.WalkFilename(hn, time.Time{}))}, nil
+}
+
+// progressPrinter returns a Walker.ProgressCallback printing the progress to stderr.
+func progressPrinter() func(fswalker.Progress) {
+	start := time.Now()
+	var last time.Time
+	return func(p fswalker.Progress) {
+		now := time.Now()
+		if now.Sub(last) < progressInterval && p.Files != p.TotalFiles {
+			return
+		}
+		last = now
+		fmt.Fprintf(os.Stderr, "\r%d/%d files (%.1f%%), ETA %s   ", p.Files, p.TotalFiles, percent(p.Bytes, p.TotalBytes), eta(now.Sub(start), p.Bytes, p.TotalBytes))
 	}
-	return os.WriteFile(outpath, walkBytes, 0444)
+}
+
+// percent returns done as a percentage of total.
+func percent(done, total int64) float64 {
+	if total <= 0 {
+		return 100
+	}
+	return 100 * float64(done) / float64(total)
+}
+
+// eta estimates the time remaining from the time elapsed to get done out of total.
+func eta(elapsed time.Duration, done, total int64) time.Duration {
+	if done <= 0 || done >= total {
+		return 0
+	}
+	remaining := time.Duration(float64(elapsed) * float64(total-done) / float64(done))
+	return remaining.Round(time.Second)
 }
 
 func outputPath(pfx string) (string, error) {
@@ -60,34 +195,128 @@ func outputPath(pfx string) (string, error) {
 			return "", fmt.Errorf("error getting current directory: %v", err)
 		}
 	}
+	if *utcFilename {
+		return filepath.Join(pfx, fswalker.WalkFilenameUTC(hn, time.Now())), nil
+	}
 	return filepath.Join(pfx, fswalker.WalkFilename(hn, time.Now())), nil
 }
 
 func main() {
 	flag.Parse()
 
-	if *policyFile == "" {
-		log.Fatal("-c needs to be specified")
+	if *policySchema {
+		b, err := fswalker.PolicyJSONSchema()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(string(b))
+		return
+	}
+	if *selfTest {
+		if err := fswalker.SelfTest(context.Background()); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("Self-test passed.")
+		return
+	}
+	if _, ok := os.LookupEnv(fswalker.PolicyEnvVar); *policyFile == "" && !ok {
+		log.Fatalf("-c or %s needs to be specified", fswalker.PolicyEnvVar)
+	}
+	if _, err := fswalker.ParseFileMode(*outputMode, fswalker.DefaultWalkFileMode); err != nil {
+		log.Fatalf("invalid -output-mode: %v", err)
+	}
+	if *queueDepth < 0 {
+		log.Fatalf("invalid -queue-depth %d: must not be negative", *queueDepth)
+	}
+	if *keyFile != "" {
+		var err error
+		if encryptionKey, err = fswalker.ReadKeyFile(*keyFile); err != nil {
+			log.Fatal(err)
+		}
 	}
 
 	w, err := fswalker.WalkerFromPolicyFile(*policyFile)
 	if err != nil {
 		log.Fatal(err)
 	}
+	if *baselineFile != "" {
+		r := &fswalker.Reporter{EncryptionKey: encryptionKey}
+		baseline, err := r.ReadWalk(*baselineFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		w.Baseline = baseline.Walk
+	}
+	w.AddIncludes(includes...)
+	w.AddExcludes(excludes...)
+	w.Labels = labels
+	outpath, err := outputPath(*outputFilePfx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *excludeOutput {
+		outExcludes, err := outputExcludes(outpath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		// They are kept out of the policy, which would otherwise change with every
+		// output path and show up in the recorded policy and its fingerprint.
+		w.SkipPaths = outExcludes
+	}
+	w.QueueDepth = *queueDepth
+	if *dumpPolicy {
+		pol := w.EffectivePolicy()
+		if *outputMode != "" {
+			pol.OutputFileMode = *outputMode
+		}
+		b, err := protojson.MarshalOptions{Multiline: true, EmitUnpopulated: true}.Marshal(pol)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(string(b))
+		return
+	}
 	w.Verbose = *verbose
-	w.WalkCallback = walkCallback
+	w.DetailedMetrics = *detailed
+	w.Explain = len(explain) > 0
+	w.ExplainPaths = explain
+	w.WalkCallback = walkCallback(outpath)
+	if *progress {
+		w.PrePass = true
+		w.ProgressCallback = progressPrinter()
+	}
 
 	// Walk the file system and wait for completion of processing.
-	ctx := context.Background()
+	ctx, stop := stopContext(context.Background())
+	defer stop()
 	if err := w.Run(ctx); err != nil {
 		log.Fatal(err)
 	}
+	if *progress {
+		fmt.Fprintln(os.Stderr)
+	}
+	if ctx.Err() != nil {
+		log.Printf("walk was stopped, the partial walk was written to %q", outpath)
+	}
 
 	fmt.Println("Metrics:")
 	metrics := w.Counter.Metrics()
 	slices.Sort(metrics)
+	histogram := false
 	for _, k := range metrics {
+		if strings.HasPrefix(k, fswalker.SizeBucketPrefix) {
+			histogram = true
+			continue
+		}
 		v, _ := w.Counter.Get(k)
 		fmt.Printf("[%-30s] = %6d\n", k, v)
 	}
+	if histogram {
+		fmt.Println("File size histogram:")
+		for _, b := range fswalker.SizeBuckets() {
+			if v, ok := w.Counter.Get(fswalker.SizeBucketPrefix + b); ok {
+				fmt.Printf("[%-30s] = %6d\n", b, v)
+			}
+		}
+	}
 }
@@ -0,0 +1,177 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/google/fswalker"
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+func TestOutputExcludes(t *testing.T) {
+	root := t.TempDir()
+	outDir := filepath.Join(root, "walks")
+	if err := os.Mkdir(outDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "file"), []byte("content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outpath, err := outputPath(outDir)
+	if err != nil {
+		t.Fatalf("outputPath() error: %v", err)
+	}
+	hn, _, err := fswalker.ParseWalkFilename(outpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := filepath.Join(outDir, fswalker.WalkFilename(hn, time.Now().Add(-time.Hour)))
+	if err := os.WriteFile(previous, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	policyPath := filepath.Join(t.TempDir(), "policy.toml")
+	policy := fmt.Sprintf("version = 1\ninclude = [%q]\n", root)
+	if err := os.WriteFile(policyPath, []byte(policy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w, err := fswalker.WalkerFromPolicyFile(policyPath)
+	if err != nil {
+		t.Fatalf("WalkerFromPolicyFile() error: %v", err)
+	}
+	outExcludes, err := outputExcludes(outpath)
+	if err != nil {
+		t.Fatalf("outputExcludes(%q) error: %v", outpath, err)
+	}
+	w.SkipPaths = outExcludes
+	w.WalkCallback = walkCallback(outpath)
+	if err := w.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	walkFile, err := (&fswalker.Reporter{}).ReadWalk(outpath)
+	if err != nil {
+		t.Fatalf("ReadWalk(%q) error: %v", outpath, err)
+	}
+	// The policy is recorded unchanged, so it fingerprints the same in every run.
+	if got := walkFile.Walk.Policy.Exclude; len(got) != 0 {
+		t.Errorf("walk policy excludes = %q; want none", got)
+	}
+	var foundFile bool
+	for _, f := range walkFile.Walk.File {
+		if strings.HasSuffix(f.Path, "-fswalker-state.pb") {
+			t.Errorf("walk contains the walk file %q", f.Path)
+		}
+		if f.Path == filepath.Join(root, "file") {
+			foundFile = true
+		}
+	}
+	if !foundFile {
+		t.Errorf("walk doesn't contain %q", filepath.Join(root, "file"))
+	}
+}
+
+func TestStopContextPartialWalk(t *testing.T) {
+	root := t.TempDir()
+	const numFiles = 300
+	for i := 0; i < numFiles; i++ {
+		if err := os.WriteFile(filepath.Join(root, fmt.Sprintf("file%03d", i)), []byte(fmt.Sprint(i)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	policyPath := filepath.Join(t.TempDir(), "policy.toml")
+	policy := fmt.Sprintf("version = 1\ninclude = [%q]\nmaxHashFileSize = 1024\n", root)
+	if err := os.WriteFile(policyPath, []byte(policy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w, err := fswalker.WalkerFromPolicyFile(policyPath)
+	if err != nil {
+		t.Fatalf("WalkerFromPolicyFile() error: %v", err)
+	}
+	outpath, err := outputPath(t.TempDir())
+	if err != nil {
+		t.Fatalf("outputPath() error: %v", err)
+	}
+	w.WalkCallback = walkCallback(outpath)
+
+	ctx, stop := stopContext(context.Background())
+	defer stop()
+	// Interrupt the walk with the first file and hold up processing until the
+	// signal arrived, so discovery stops long before reaching all files.
+	var once bool
+	w.ProgressCallback = func(fswalker.Progress) {
+		if once {
+			return
+		}
+		once = true
+		if err := syscall.Kill(os.Getpid(), syscall.SIGINT); err != nil {
+			t.Errorf("Kill() error: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(10 * time.Second):
+			t.Error("SIGINT didn't cancel the context")
+		}
+	}
+	if err := w.Run(ctx); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	walkFile, err := (&fswalker.Reporter{}).ReadWalk(outpath)
+	if err != nil {
+		t.Fatalf("ReadWalk(%q) error: %v", outpath, err)
+	}
+	walk := walkFile.Walk
+	if n := len(walk.File); n == 0 || n > numFiles {
+		t.Errorf("partial walk has %d files; want some but fewer than all %d", n, numFiles+1)
+	}
+	if walk.StopWalk == nil {
+		t.Error("partial walk has no stop time")
+	}
+	var interrupted bool
+	for _, n := range walk.Notification {
+		if n.Severity == fspb.Notification_WARNING && strings.Contains(n.Message, "walk was interrupted") {
+			interrupted = true
+		}
+	}
+	if !interrupted || !walk.Incomplete {
+		t.Errorf("partial walk isn't marked as interrupted and incomplete: %v", walk.Notification)
+	}
+}
+
+func TestLabelMap(t *testing.T) {
+	m := labelMap{}
+	for _, v := range []string{"ticket=CHG-1", "reason=a=b", "empty="} {
+		if err := m.Set(v); err != nil {
+			t.Errorf("Set(%q) error: %v", v, err)
+		}
+	}
+	for _, v := range []string{"novalue", "=value", "ticket=CHG-2"} {
+		if err := m.Set(v); err == nil {
+			t.Errorf("Set(%q) succeeded; want error", v)
+		}
+	}
+	if got, want := m.String(), "empty=,reason=a=b,ticket=CHG-1"; got != want {
+		t.Errorf("String() = %q; want %q", got, want)
+	}
+}
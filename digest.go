@@ -0,0 +1,111 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+// computeTreeDigests sets the tree digest of all directories in files.
+// A directory's digest is built over the sorted entries of its direct children,
+// so it depends on the digests of all its subdirectories.
+func computeTreeDigests(files []*fspb.File) {
+	children := map[string][]*fspb.File{}
+	var dirs []*fspb.File
+	for _, f := range files {
+		if f.Info == nil {
+			continue
+		}
+		if f.Info.IsDir {
+			dirs = append(dirs, f)
+		}
+		p := filepath.Clean(f.Path)
+		if parent := filepath.Dir(p); parent != p {
+			children[parent] = append(children[parent], f)
+		}
+	}
+
+	// Process the deepest directories first so digests of subdirectories are
+	// always available when their parent is processed.
+	slices.SortFunc(dirs, func(a, b *fspb.File) bool {
+		return pathDepth(a.Path) > pathDepth(b.Path)
+	})
+	for _, d := range dirs {
+		d.TreeDigest = treeDigest(children[filepath.Clean(d.Path)])
+	}
+}
+
+// treeDigest builds the digest over the given children of a directory.
+func treeDigest(children []*fspb.File) *fspb.Fingerprint {
+	entries := make([]string, 0, len(children))
+	for _, c := range children {
+		entries = append(entries, treeDigestEntry(c))
+	}
+	return treeDigestOfEntries(entries)
+}
+
+// treeDigestOfEntries builds the digest over the given entries of the children of
+// a directory, sorting them in place.
+func treeDigestOfEntries(entries []string) *fspb.Fingerprint {
+	slices.Sort(entries)
+
+	h := sha256.New()
+	for _, e := range entries {
+		h.Write([]byte(e))
+	}
+	return &fspb.Fingerprint{
+		Method: fspb.Fingerprint_SHA256,
+		Value:  hex.EncodeToString(h.Sum(nil)),
+	}
+}
+
+// treeDigestEntry returns the line representing a single child in its parent's digest.
+// Directories are represented by their own digest, hashed files by their fingerprint
+// and all other files by their size.
+func treeDigestEntry(f *fspb.File) string {
+	var content string
+	switch {
+	case f.TreeDigest != nil:
+		content = "tree:" + f.TreeDigest.Value
+	case len(f.Fingerprint) > 0:
+		content = "fp:" + f.Fingerprint[0].Value
+	default:
+		content = fmt.Sprintf("size:%d", f.Info.Size)
+	}
+	return fmt.Sprintf("%s\x00%o\x00%s\n", f.Info.Name, f.Info.Mode, content)
+}
+
+// pathDepth returns the number of path elements below the root in path.
+// Relative paths are treated as if they were relative to a root at ".".
+func pathDepth(path string) int {
+	sep := string(filepath.Separator)
+	p := filepath.Clean(path)
+	switch {
+	case p == sep || p == ".":
+		return 0
+	case filepath.IsAbs(p):
+		return strings.Count(p, sep)
+	default:
+		return strings.Count(p, sep) + 1
+	}
+}
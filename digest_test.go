@@ -0,0 +1,76 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"path/filepath"
+	"testing"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+func TestTreeDigests(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"a/x.txt":   "x",
+		"b/y.txt":   "y",
+		"b/c/z.txt": "z",
+	})
+	pol := &fspb.Policy{
+		Include:            []string{root},
+		MaxHashFileSize:    1024,
+		ComputeTreeDigests: true,
+	}
+
+	digests := func(walk *fspb.Walk) map[string]string {
+		d := map[string]string{}
+		for _, f := range walk.File {
+			if f.Info.IsDir {
+				if f.TreeDigest == nil {
+					t.Fatalf("directory %q has no tree digest", f.Path)
+				}
+				d[f.Path] = f.TreeDigest.Value
+			} else if f.TreeDigest != nil {
+				t.Errorf("file %q has a tree digest", f.Path)
+			}
+		}
+		return d
+	}
+	before := digests(runWalk(t, pol))
+	if again := digests(runWalk(t, pol)); len(again) != len(before) {
+		t.Fatalf("got %d directories on second walk; want %d", len(again), len(before))
+	} else {
+		for p, d := range before {
+			if again[p] != d {
+				t.Errorf("digest of unchanged %q differs between walks", p)
+			}
+		}
+	}
+
+	writeFiles(t, root, map[string]string{"b/c/z.txt": "Z"})
+	after := digests(runWalk(t, pol))
+
+	wantChanged := map[string]bool{
+		root:                       true,
+		filepath.Join(root, "a"):   false,
+		filepath.Join(root, "b"):   true,
+		filepath.Join(root, "b/c"): true,
+	}
+	for p, want := range wantChanged {
+		if got := before[p] != after[p]; got != want {
+			t.Errorf("digest of %q changed = %v; want %v", p, got, want)
+		}
+	}
+}
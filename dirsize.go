@@ -0,0 +1,49 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"path/filepath"
+
+	"google.golang.org/protobuf/proto"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+// computeDirectorySizes sets the aggregate size of all directories in files to the
+// total size of the files recorded below them, at any depth.
+func computeDirectorySizes(files []*fspb.File) {
+	sizes := map[string]uint64{}
+	var dirs []*fspb.File
+	for _, f := range files {
+		if f.Info == nil {
+			continue
+		}
+		if f.Info.IsDir {
+			dirs = append(dirs, f)
+			continue
+		}
+		// Every ancestor of the file contains it, up to the root.
+		for d := filepath.Dir(filepath.Clean(f.Path)); ; d = filepath.Dir(d) {
+			sizes[d] += uint64(f.Info.Size)
+			if filepath.Dir(d) == d {
+				break
+			}
+		}
+	}
+	for _, d := range dirs {
+		d.AggregateSize = proto.Uint64(sizes[filepath.Clean(d.Path)])
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+func TestComputeDirectorySizes(t *testing.T) {
+	files := []*fspb.File{
+		{Path: "/", Info: &fspb.FileInfo{IsDir: true, Size: 4096}},
+		{Path: "/a/", Info: &fspb.FileInfo{IsDir: true, Size: 4096}},
+		{Path: "/a/one", Info: &fspb.FileInfo{Size: 1}},
+		{Path: "/a/b/", Info: &fspb.FileInfo{IsDir: true, Size: 4096}},
+		{Path: "/a/b/two", Info: &fspb.FileInfo{Size: 20}},
+		{Path: "/a/b/three", Info: &fspb.FileInfo{Size: 300}},
+		{Path: "/empty/", Info: &fspb.FileInfo{IsDir: true, Size: 4096}},
+		{Path: "/top", Info: &fspb.FileInfo{Size: 4000}},
+		{Path: "/no-info"},
+	}
+	computeDirectorySizes(files)
+
+	got := map[string]uint64{}
+	for _, f := range files {
+		if f.AggregateSize != nil {
+			got[f.Path] = f.GetAggregateSize()
+		}
+	}
+	want := map[string]uint64{
+		"/":       4321,
+		"/a/":     321,
+		"/a/b/":   320,
+		"/empty/": 0,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("computeDirectorySizes() aggregate sizes: diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunComputeDirectorySizes(t *testing.T) {
+	fsys := fstest.MapFS{
+		"dir/a":     &fstest.MapFile{Data: []byte("12345")},
+		"dir/sub/b": &fstest.MapFile{Data: []byte("123")},
+	}
+	for _, compute := range []bool{false, true} {
+		var walk *fspb.Walk
+		wlkr := &Walker{
+			pol: &fspb.Policy{
+				Include:               []string{"dir"},
+				ComputeDirectorySizes: compute,
+			},
+			FS: fsys,
+			WalkCallback: func(w *fspb.Walk) error {
+				walk = w
+				return nil
+			},
+		}
+		if err := wlkr.Run(context.Background()); err != nil {
+			t.Fatalf("Run() error: %v", err)
+		}
+
+		got := map[string]uint64{}
+		for _, f := range walk.File {
+			if f.AggregateSize != nil {
+				got[f.Path] = f.GetAggregateSize()
+			}
+		}
+		want := map[string]uint64{}
+		if compute {
+			want = map[string]uint64{"dir": 8, "dir/sub": 3}
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Run() with computeDirectorySizes %v: aggregate sizes diff (-want +got):\n%s", compute, diff)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+
+	"google.golang.org/protobuf/proto"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+// encryptedWalkHeader starts every encrypted Walk file. It is followed by the
+// AES-GCM nonce and the sealed marshaled Walk.
+var encryptedWalkHeader = []byte("FSWALKER-AES256-GCM-V1\n")
+
+// encryptionKeySize is the size of the AES-256 keys used to encrypt Walks.
+const encryptionKeySize = 32
+
+// ReadKeyFile reads a Walk encryption key from path. The file contains either
+// the 32 raw bytes of the key or their hex encoding.
+func ReadKeyFile(path string) ([]byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == encryptionKeySize {
+		return b, nil
+	}
+	key, err := hex.DecodeString(string(bytes.TrimSpace(b)))
+	if err != nil || len(key) != encryptionKeySize {
+		return nil, fmt.Errorf("key file %q must contain %d raw or hex encoded bytes", path, encryptionKeySize)
+	}
+	return key, nil
+}
+
+// WriteEncryptedWalk writes walk to path encrypted with key, using the given file mode.
+func WriteEncryptedWalk(path string, walk *fspb.Walk, mode os.FileMode, key []byte) error {
+	walkBytes, err := proto.Marshal(walk)
+	if err != nil {
+		return err
+	}
+	ciphertext, err := encryptWalk(walkBytes, key)
+	if err != nil {
+		return err
+	}
+	return writeFile(path, ciphertext, mode)
+}
+
+// newWalkAEAD returns the AES-GCM cipher for key.
+func newWalkAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != encryptionKeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, got %d", encryptionKeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptWalk seals the marshaled Walk in walkBytes. The header is authenticated as well.
+func encryptWalk(walkBytes, key []byte) ([]byte, error) {
+	aead, err := newWalkAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	out := append([]byte{}, encryptedWalkHeader...)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, walkBytes, encryptedWalkHeader), nil
+}
+
+// decryptWalk opens data written by encryptWalk and returns the marshaled Walk.
+func decryptWalk(data, key []byte) ([]byte, error) {
+	if !isEncryptedWalk(data) {
+		return nil, errors.New("not an encrypted walk")
+	}
+	aead, err := newWalkAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	data = data[len(encryptedWalkHeader):]
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("encrypted walk is truncated")
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	walkBytes, err := aead.Open(nil, nonce, ciphertext, encryptedWalkHeader)
+	if err != nil {
+		return nil, errors.New("unable to decrypt walk: wrong key or corrupted file")
+	}
+	return walkBytes, nil
+}
+
+// isEncryptedWalk returns true if data starts with the encrypted Walk header.
+func isEncryptedWalk(data []byte) bool {
+	return bytes.HasPrefix(data, encryptedWalkHeader)
+}
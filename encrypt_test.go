@@ -0,0 +1,115 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+func TestEncryptedWalkRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	key := bytes.Repeat([]byte{0x42}, encryptionKeySize)
+	walk := &fspb.Walk{
+		Id:       "walk",
+		Hostname: "secret-host",
+		File:     []*fspb.File{{Path: "/etc/shadow", Info: &fspb.FileInfo{Name: "shadow"}}},
+	}
+
+	plainPath := filepath.Join(dir, "plain.pb")
+	if err := WriteWalk(plainPath, walk, DefaultWalkFileMode); err != nil {
+		t.Fatalf("WriteWalk() error: %v", err)
+	}
+	encPath := filepath.Join(dir, "encrypted.pb")
+	if err := WriteEncryptedWalk(encPath, walk, DefaultWalkFileMode, key); err != nil {
+		t.Fatalf("WriteEncryptedWalk() error: %v", err)
+	}
+	b, err := os.ReadFile(encPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(b, []byte("secret-host")) {
+		t.Error("encrypted walk contains the plaintext hostname")
+	}
+
+	r := &Reporter{EncryptionKey: key}
+	want, err := r.ReadWalk(plainPath)
+	if err != nil {
+		t.Fatalf("ReadWalk(%q) error: %v", plainPath, err)
+	}
+	got, err := r.ReadWalk(encPath)
+	if err != nil {
+		t.Fatalf("ReadWalk(%q) error: %v", encPath, err)
+	}
+	if diff := cmp.Diff(want.Walk, got.Walk, cmp.Comparer(proto.Equal)); diff != "" {
+		t.Errorf("ReadWalk() encrypted walk: diff (-want +got):\n%s", diff)
+	}
+	if !proto.Equal(want.Fingerprint, got.Fingerprint) {
+		t.Errorf("ReadWalk() fingerprint = %v; want %v (over the plaintext)", got.Fingerprint, want.Fingerprint)
+	}
+
+	for _, tc := range []struct {
+		desc    string
+		key     []byte
+		wantErr string
+	}{
+		{desc: "wrong key", key: bytes.Repeat([]byte{0x43}, encryptionKeySize), wantErr: "wrong key"},
+		{desc: "no key", key: nil, wantErr: "no key is configured"},
+	} {
+		r := &Reporter{EncryptionKey: tc.key}
+		if _, err := r.ReadWalk(encPath); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("ReadWalk() with %s error = %v; want error containing %q", tc.desc, err, tc.wantErr)
+		}
+	}
+}
+
+func TestReadKeyFile(t *testing.T) {
+	dir := t.TempDir()
+	key := bytes.Repeat([]byte{0x01, 0x02}, encryptionKeySize/2)
+	for name, content := range map[string][]byte{
+		"raw": key,
+		"hex": []byte(hex.EncodeToString(key) + "\n"),
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, content, 0600); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ReadKeyFile(path)
+		if err != nil {
+			t.Errorf("ReadKeyFile(%s) error: %v", name, err)
+			continue
+		}
+		if !bytes.Equal(got, key) {
+			t.Errorf("ReadKeyFile(%s) = %x; want %x", name, got, key)
+		}
+	}
+
+	path := filepath.Join(dir, "short")
+	if err := os.WriteFile(path, []byte("abcd"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadKeyFile(path); err == nil {
+		t.Error("ReadKeyFile() with a short key succeeded; want error")
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"hash"
+	"math"
+)
+
+// entropyHash wraps a hash.Hash and counts the bytes written to it, so the
+// entropy of a file's content is computed in the same pass as its hash sum.
+type entropyHash struct {
+	hash.Hash
+	counts [256]uint64
+	total  uint64
+}
+
+func (h *entropyHash) Write(p []byte) (int, error) {
+	for _, b := range p {
+		h.counts[b]++
+	}
+	h.total += uint64(len(p))
+	return h.Hash.Write(p)
+}
+
+func (h *entropyHash) Reset() {
+	h.Hash.Reset()
+	h.counts = [256]uint64{}
+	h.total = 0
+}
+
+// entropy returns the Shannon entropy of the bytes written since the last Reset
+// in bits per byte, from 0 for empty or uniform content to 8 for random content.
+func (h *entropyHash) entropy() float64 {
+	if h.total == 0 {
+		return 0
+	}
+	var e float64
+	for _, c := range h.counts {
+		if c == 0 {
+			continue
+		}
+		p := float64(c) / float64(h.total)
+		e -= p * math.Log2(p)
+	}
+	return e
+}
@@ -0,0 +1,87 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"crypto/sha256"
+	"math"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+func TestEntropyHash(t *testing.T) {
+	for _, tc := range []struct {
+		content string
+		want    float64
+	}{
+		{"", 0},
+		{"aaaa", 0},
+		{"abab", 1},
+		{"abcd", 2},
+	} {
+		h := &entropyHash{Hash: sha256.New()}
+		h.Write([]byte("leftover"))
+		h.Reset()
+		h.Write([]byte(tc.content))
+		if got := h.entropy(); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("entropy(%q) = %v, want %v", tc.content, got, tc.want)
+		}
+		if got, want := h.Sum(nil), sha256.Sum256([]byte(tc.content)); string(got) != string(want[:]) {
+			t.Errorf("hash of %q = %x, want %x", tc.content, got, want)
+		}
+	}
+}
+
+func TestCompareEntropyIncrease(t *testing.T) {
+	root := t.TempDir()
+	text := strings.Repeat("listen_address = 127.0.0.1\n", 200)
+	writeFiles(t, root, map[string]string{"app.conf": text, "notes.txt": "some notes"})
+	pol := &fspb.Policy{Include: []string{root}, MaxHashFileSize: 1 << 20, RecordEntropy: true}
+	before := runWalk(t, pol)
+
+	random := make([]byte, len(text))
+	rand.New(rand.NewSource(1)).Read(random)
+	writeFiles(t, root, map[string]string{"app.conf": string(random), "notes.txt": "other notes"})
+	after := runWalk(t, pol)
+
+	for _, f := range after.File {
+		if !f.Info.IsDir && f.Entropy == nil {
+			t.Errorf("%s: no entropy recorded", f.Path)
+		}
+	}
+
+	r := &Reporter{config: &fspb.ReportConfig{FlagEntropyIncrease: 2}}
+	report, err := r.Compare(before, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	got := map[string]bool{}
+	for _, m := range report.Modified {
+		for _, d := range m.Fields {
+			if d.Field == "entropy-increase" {
+				got[filepath.Base(m.After.Path)] = d.HighSeverity()
+			}
+		}
+	}
+	if diff := cmp.Diff(map[string]bool{"app.conf": true}, got); diff != "" {
+		t.Errorf("Compare() entropy increases: diff (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+// explainPrefix starts the messages of the notifications recorded for Walker.Explain.
+const explainPrefix = "explain: "
+
+// resetExplanations marks all ExplainPaths as not explained yet.
+func (w *Walker) resetExplanations() {
+	w.explained = make(map[string]bool, len(w.ExplainPaths))
+	for _, p := range w.ExplainPaths {
+		w.explained[filepath.Clean(p)] = false
+	}
+}
+
+// explainf records why the walk recorded or skipped p if Explain is set and p is
+// explained. If skipDir is true, p is a directory skipped with all its contents,
+// which explains the ExplainPaths below it as well.
+func (w *Walker) explainf(p string, skipDir bool, format string, args ...any) {
+	if !w.Explain {
+		return
+	}
+	p = filepath.Clean(p)
+	reason := fmt.Sprintf(format, args...)
+	w.walkMu.Lock()
+	_, ok := w.explained[p]
+	if ok {
+		w.explained[p] = true
+	}
+	w.walkMu.Unlock()
+	if ok || len(w.ExplainPaths) == 0 {
+		w.addNotificationToWalk(fspb.Notification_INFO, p, explainPrefix+reason)
+	}
+	if skipDir {
+		w.explainContentsf(p, "%s", reason)
+	}
+}
+
+// explainContentsf records why the walk didn't get to the ExplainPaths below the
+// directory dir if Explain is set.
+func (w *Walker) explainContentsf(dir string, format string, args ...any) {
+	if !w.Explain {
+		return
+	}
+	dir = filepath.Clean(dir)
+	var paths []string
+	w.walkMu.Lock()
+	for p := range w.explained {
+		if isBelow(dir, p) {
+			w.explained[p] = true
+			paths = append(paths, p)
+		}
+	}
+	w.walkMu.Unlock()
+	slices.Sort(paths)
+	reason := fmt.Sprintf(format, args...)
+	for _, p := range paths {
+		w.addNotificationToWalk(fspb.Notification_INFO, p, fmt.Sprintf("%snot walked into %q: %s", explainPrefix, dir, reason))
+	}
+}
+
+// explainUnreached records the ExplainPaths the walk didn't come across at all
+// if Explain is set, along with the include they should have been found in.
+func (w *Walker) explainUnreached(includes []string) {
+	if !w.Explain {
+		return
+	}
+	var paths []string
+	w.walkMu.Lock()
+	for p, explained := range w.explained {
+		if !explained {
+			paths = append(paths, p)
+		}
+	}
+	w.walkMu.Unlock()
+	slices.Sort(paths)
+	for _, p := range paths {
+		reason := "not below any include"
+		for _, include := range includes {
+			if p == include || isBelow(include, p) {
+				reason = fmt.Sprintf("not found while walking include %q", include)
+				break
+			}
+		}
+		w.addNotificationToWalk(fspb.Notification_INFO, p, explainPrefix+reason)
+	}
+}
+
+// isBelow returns true if the cleaned path is inside the cleaned directory dir.
+func isBelow(dir, path string) bool {
+	if dir == "." {
+		return path != "." && path != ".." && !filepath.IsAbs(path) && !strings.HasPrefix(path, ".."+string(filepath.Separator))
+	}
+	if !strings.HasSuffix(dir, string(filepath.Separator)) {
+		dir += string(filepath.Separator)
+	}
+	return strings.HasPrefix(path, dir)
+}
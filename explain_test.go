@@ -0,0 +1,148 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+func TestRunExplain(t *testing.T) {
+	fsys := fstest.MapFS{
+		"dir/keep":              &fstest.MapFile{Data: []byte("1")},
+		"dir/secret":            &fstest.MapFile{Data: []byte("2")},
+		"dir/cache/deep/file":   &fstest.MapFile{Data: []byte("3")},
+		"dir/.hidden":           &fstest.MapFile{Data: []byte("4")},
+		"dir/a/b/c/too-deep":    &fstest.MapFile{Data: []byte("5")},
+		"dir/logs/current.log":  &fstest.MapFile{Data: []byte("6")},
+		"other/outside-include": &fstest.MapFile{Data: []byte("7")},
+	}
+	var walk *fspb.Walk
+	wlkr := &Walker{
+		pol: &fspb.Policy{
+			Include:           []string{"dir"},
+			Exclude:           []string{"dir/*.tmp", "dir/secret", "dir/cache/"},
+			ExcludeContents:   []string{"dir/logs/"},
+			SkipHidden:        true,
+			MaxDirectoryDepth: 2,
+		},
+		FS:      fsys,
+		Explain: true,
+		ExplainPaths: []string{
+			"dir/keep",
+			"dir/secret",
+			"dir/cache/deep/file",
+			"dir/.hidden",
+			"dir/a/b/c/too-deep",
+			"dir/logs/current.log",
+			"dir/missing",
+			"other/outside-include",
+		},
+		WalkCallback: func(w *fspb.Walk) error {
+			walk = w
+			return nil
+		},
+	}
+	if err := wlkr.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	got := map[string]string{}
+	for _, n := range walk.Notification {
+		if strings.HasPrefix(n.Message, explainPrefix) {
+			if _, ok := got[n.Path]; ok {
+				t.Errorf("Run() explained %q more than once", n.Path)
+			}
+			got[n.Path] = strings.TrimPrefix(n.Message, explainPrefix)
+		}
+	}
+	want := map[string]string{
+		"dir/keep":              `recorded as part of include "dir"`,
+		"dir/secret":            `excluded by exclude entry "dir/secret"`,
+		"dir/cache/deep/file":   `not walked into "dir/cache": excluded by exclude entry "dir/cache/"`,
+		"dir/.hidden":           `hidden and skipHidden is set`,
+		"dir/a/b/c/too-deep":    `not walked into "dir/a/b": more than maxDirectoryDepth 2 into include "dir"`,
+		"dir/logs/current.log":  `not walked into "dir/logs": contents excluded by excludeContents entry "dir/logs/"`,
+		"dir/missing":           `not found while walking include "dir"`,
+		"other/outside-include": `not below any include`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Run() explained:\n%v\nwant:\n%v", got, want)
+	}
+}
+
+func TestRunExplainAll(t *testing.T) {
+	fsys := fstest.MapFS{
+		"dir/keep":   &fstest.MapFile{Data: []byte("1")},
+		"dir/secret": &fstest.MapFile{Data: []byte("2")},
+	}
+	var walk *fspb.Walk
+	wlkr := &Walker{
+		pol: &fspb.Policy{
+			Include: []string{"dir"},
+			Exclude: []string{"dir/secret"},
+		},
+		FS:      fsys,
+		Explain: true,
+		WalkCallback: func(w *fspb.Walk) error {
+			walk = w
+			return nil
+		},
+	}
+	if err := wlkr.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	var got []string
+	for _, n := range walk.Notification {
+		if strings.HasPrefix(n.Message, explainPrefix) {
+			got = append(got, n.Path+": "+strings.TrimPrefix(n.Message, explainPrefix))
+		}
+	}
+	want := []string{
+		`dir: recorded as part of include "dir"`,
+		`dir/keep: recorded as part of include "dir"`,
+		`dir/secret: excluded by exclude entry "dir/secret"`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Run() explained %q; want %q", got, want)
+	}
+}
+
+func TestIsBelow(t *testing.T) {
+	testCases := []struct {
+		dir, path string
+		want      bool
+	}{
+		{"/a", "/a/b", true},
+		{"/a", "/a", false},
+		{"/a", "/ab", false},
+		{"/", "/a", true},
+		{".", "a/b", true},
+		{".", "..", false},
+		{".", "../a", false},
+		{".", "..a", true},
+	}
+	for _, tc := range testCases {
+		if got := isBelow(tc.dir, tc.path); got != tc.want {
+			t.Errorf("isBelow(%q, %q) = %v; want %v", tc.dir, tc.path, got, tc.want)
+		}
+	}
+}
@@ -16,17 +16,29 @@
 package fswalker
 
 import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"hash"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"google.golang.org/protobuf/encoding/prototext"
 	"google.golang.org/protobuf/proto"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
 )
 
 // Generating Go representations for the proto buf libraries.
@@ -36,20 +48,79 @@ import (
 const (
 	// tsFileFormat is the time format used in file names.
 	tsFileFormat = "20060102-150405"
+	// tsFileFormatUTC is the time format used in file names by WalkFilenameUTC.
+	// It is always in UTC and zero-padded so lexical and chronological order agree.
+	tsFileFormatUTC = "2006-01-02T15-04-05Z"
+
+	// walkFileSuffix is appended to all Walk file names.
+	walkFileSuffix = "-fswalker-state.pb"
+
+	// DefaultWalkFileMode is the file mode of walk files if the policy doesn't set one.
+	DefaultWalkFileMode os.FileMode = 0444
+	// defaultReportFileMode is the file mode of files written by the reporter
+	// if the report config doesn't set one.
+	defaultReportFileMode os.FileMode = 0644
 )
 
 // WalkFilename returns the appropriate filename for a Walk for the given host and time.
 // If time is not provided, it returns a file pattern to glob by.
 func WalkFilename(hostname string, t time.Time) string {
+	ts := "*"
+	if !t.IsZero() {
+		ts = t.Format(tsFileFormat)
+	}
+	return walkFilename(hostname, ts)
+}
+
+// WalkFilenameUTC is like WalkFilename but renders the time in UTC with a layout
+// that sorts lexically in chronological order.
+// If time is not provided, it returns a file pattern to glob by which matches
+// both WalkFilename and WalkFilenameUTC file names.
+func WalkFilenameUTC(hostname string, t time.Time) string {
+	ts := "*"
+	if !t.IsZero() {
+		ts = t.UTC().Format(tsFileFormatUTC)
+	}
+	return walkFilename(hostname, ts)
+}
+
+func walkFilename(hostname, ts string) string {
 	hn := "*"
 	if hostname != "" {
 		hn = hostname
 	}
-	ts := "*"
-	if !t.IsZero() {
-		ts = t.Format(tsFileFormat)
+	return fmt.Sprintf("%s-%s%s", hn, ts, walkFileSuffix)
+}
+
+// ParseWalkFilename returns the hostname and time encoded in a file name created by
+// either WalkFilename or WalkFilenameUTC. Leading directories are ignored.
+// Times in the WalkFilename format carry no zone and are interpreted as local time.
+func ParseWalkFilename(name string) (string, time.Time, error) {
+	base := filepath.Base(name)
+	if !strings.HasSuffix(base, walkFileSuffix) {
+		return "", time.Time{}, fmt.Errorf("%q is not a walk file name", name)
 	}
-	return fmt.Sprintf("%s-%s-fswalker-state.pb", hn, ts)
+	base = strings.TrimSuffix(base, walkFileSuffix)
+
+	for _, f := range []struct {
+		layout string
+		loc    *time.Location
+	}{
+		{tsFileFormatUTC, time.UTC},
+		{tsFileFormat, time.Local},
+	} {
+		// The hostname is separated from the timestamp by a dash.
+		i := len(base) - len(f.layout) - 1
+		if i < 1 || base[i] != '-' {
+			continue
+		}
+		t, err := time.ParseInLocation(f.layout, base[i+1:], f.loc)
+		if err != nil {
+			continue
+		}
+		return base[:i], t, nil
+	}
+	return "", time.Time{}, fmt.Errorf("unable to parse time from walk file name %q", name)
 }
 
 // NormalizePath returns a cleaned up path with a path separator at the end if it's a directory.
@@ -62,49 +133,407 @@ func NormalizePath(path string, isDir bool) string {
 	return p
 }
 
+// duplicatePaths returns the normalized paths recorded more than once among files, sorted.
+// If transform is not nil, it is applied to the paths first, like Reporter.PathTransform.
+func duplicatePaths(files []*fspb.File, transform func(string) string) []string {
+	seen := make(map[string]int, len(files))
+	var dups []string
+	for _, f := range files {
+		p := f.Path
+		if transform != nil {
+			p = transform(p)
+		}
+		p = NormalizePath(p, f.Info.GetIsDir())
+		if seen[p]++; seen[p] == 2 {
+			dups = append(dups, p)
+		}
+	}
+	sort.Strings(dups)
+	return dups
+}
+
 // isExcluded determines whether a given path is excluded.
+// Entries containing glob meta characters are matched with globExcluded.
+// Empty entries, e.g. from a trailing newline in a policy, never match.
 func isExcluded(path string, excluded []string) bool {
+	_, ok := excludedBy(path, excluded)
+	return ok
+}
+
+// excludedBy returns the first entry of excluded which excludes path, see isExcluded.
+func excludedBy(path string, excluded []string) (string, bool) {
 	for _, e := range excluded {
+		if e == "" {
+			continue
+		}
+		if hasGlobMeta(e) {
+			if globExcluded(path, e) {
+				return e, true
+			}
+			continue
+		}
 		if path == e {
-			return true
+			return e, true
 		}
 		// if e ends in a slash, treat it like a directory and match if e is the
 		// dir of path
 		if e[len(e)-1] == filepath.Separator && strings.HasPrefix(filepath.Dir(path)+string(filepath.Separator), e) {
+			return e, true
+		}
+	}
+	return "", false
+}
+
+// hasGlobMeta returns true if the exclude entry contains glob meta characters.
+func hasGlobMeta(e string) bool {
+	return strings.ContainsAny(e, "*?[")
+}
+
+// globExcluded determines whether a given path is excluded by the glob pattern e.
+// Like literal excludes, a pattern ending in a slash only matches directories but
+// then also excludes everything below them, whereas other patterns only match files.
+func globExcluded(path, e string) bool {
+	sep := string(filepath.Separator)
+	isDir := strings.HasSuffix(path, sep)
+	if !strings.HasSuffix(e, sep) {
+		return !isDir && matchGlob(e, path)
+	}
+
+	// Check the path itself if it is a directory and all its parent directories.
+	e = strings.TrimSuffix(e, sep)
+	d := filepath.Clean(path)
+	if !isDir {
+		d = filepath.Dir(d)
+	}
+	for {
+		if matchGlob(e, d) {
 			return true
 		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return false
+		}
+		d = parent
 	}
-	return false
 }
 
-// sha256sum reads the given file path and builds a SHA-256 sum over its content.
-func sha256sum(path string, h hash.Hash) (string, error) {
-	f, err := os.Open(path)
+// matchGlob reports whether path matches the glob pattern.
+// In addition to the syntax of filepath.Match, a "**" path element matches any
+// number of path elements. Patterns without a separator are matched against the
+// base name of path only, so "*.iso" matches ISO files in all directories.
+func matchGlob(pattern, path string) bool {
+	sep := string(filepath.Separator)
+	path = filepath.Clean(path)
+	if !strings.Contains(pattern, sep) {
+		ok, _ := filepath.Match(pattern, filepath.Base(path))
+		return ok
+	}
+	return matchGlobElems(strings.Split(pattern, sep), strings.Split(path, sep))
+}
+
+// matchGlobElems matches the path elements against the pattern elements.
+func matchGlobElems(pattern, path []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(path); i++ {
+				if matchGlobElems(pattern[1:], path[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(path) == 0 {
+			return false
+		}
+		if ok, _ := filepath.Match(pattern[0], path[0]); !ok {
+			return false
+		}
+		pattern, path = pattern[1:], path[1:]
+	}
+	return len(path) == 0
+}
+
+// errFileChanged is returned by sha256sumNoFollow if path doesn't refer to the expected file anymore.
+var errFileChanged = errors.New("file changed since it was discovered")
+
+// sha256sumNoFollow builds a SHA-256 sum over the content of the file at path, refusing to
+// hash anything other than a regular file.
+// This prevents following a symlink (or blocking on a FIFO) that was swapped in after
+// the walk decided path was a regular file. buf is used as in hashFile.
+func sha256sumNoFollow(path string, h hash.Hash, buf []byte) (string, error) {
+	// O_NONBLOCK keeps the open from hanging if path was replaced by a FIFO.
+	f, err := os.OpenFile(path, os.O_RDONLY|syscall.O_NOFOLLOW|syscall.O_NONBLOCK, 0)
 	if err != nil {
+		if errors.Is(err, syscall.ELOOP) {
+			return "", fmt.Errorf("%w: it is a symlink now", errFileChanged)
+		}
 		return "", err
 	}
 	defer f.Close()
-	h.Reset()
 
-	if _, err := io.Copy(h, f); err != nil {
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%w: it is not a regular file anymore (%s)", errFileChanged, info.Mode().Type())
+	}
+	return hashFile(f, info.Size(), h, buf)
+}
+
+// sha256sumFS is like sha256sumNoFollow but reads name from fsys.
+// As fs.FS has no non-blocking open, the file type is checked before opening
+// name as well, so a FIFO doesn't block the open.
+func sha256sumFS(fsys fs.FS, name string, h hash.Hash, buf []byte) (string, error) {
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%w: it is not a regular file anymore (%s)", errFileChanged, info.Mode().Type())
+	}
+	f, err := fsys.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if info, err = f.Stat(); err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%w: it is not a regular file anymore (%s)", errFileChanged, info.Mode().Type())
+	}
+	return hashFile(f, info.Size(), h, buf)
+}
+
+// isBlockDevice returns true if mode describes a block device.
+func isBlockDevice(mode fs.FileMode) bool {
+	return mode&fs.ModeDevice != 0 && mode&fs.ModeCharDevice == 0
+}
+
+// sha256sumBlockDevice is like sha256sumNoFollow but hashes the content of the
+// block device at path instead of a regular file, only the first limit bytes if
+// limit is positive.
+func sha256sumBlockDevice(path string, h hash.Hash, limit int64, buf []byte) (string, error) {
+	f, err := os.OpenFile(path, os.O_RDONLY|syscall.O_NOFOLLOW|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		if errors.Is(err, syscall.ELOOP) {
+			return "", fmt.Errorf("%w: it is a symlink now", errFileChanged)
+		}
+		return "", err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	if !isBlockDevice(info.Mode()) {
+		return "", fmt.Errorf("%w: it is not a block device anymore (%s)", errFileChanged, info.Mode().Type())
+	}
+	return hashDevice(f, h, limit, buf)
+}
+
+// sha256sumBlockDeviceFS is like sha256sumBlockDevice but reads name from fsys.
+func sha256sumBlockDeviceFS(fsys fs.FS, name string, h hash.Hash, limit int64, buf []byte) (string, error) {
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		return "", err
+	}
+	if !isBlockDevice(info.Mode()) {
+		return "", fmt.Errorf("%w: it is not a block device anymore (%s)", errFileChanged, info.Mode().Type())
+	}
+	f, err := fsys.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return hashDevice(f, h, limit, buf)
+}
+
+// hashDevice returns the hex encoded hash sum of the first limit bytes of the
+// device f, or of all of it if limit is not positive. As devices have no size,
+// they are streamed unless limit is small.
+func hashDevice(f io.Reader, h hash.Hash, limit int64, buf []byte) (string, error) {
+	size := int64(smallFileSize)
+	if limit > 0 {
+		f = io.LimitReader(f, limit)
+		size = limit
+	}
+	return hashFile(f, size, h, buf)
+}
+
+// smallFileSize is the size below which hashFile reads files at once instead of streaming them.
+const smallFileSize = 64 << 10
+
+// smallFileBufs holds the buffers hashFile reads small files into.
+var smallFileBufs = sync.Pool{
+	New: func() any {
+		buf := make([]byte, smallFileSize)
+		return &buf
+	},
+}
+
+// hashFile returns the hex encoded hash sum of the content of f, whose size is
+// expected to be size. Small files are read at once into a pooled buffer, which
+// saves allocating a buffer per file when streaming millions of tiny files.
+// Larger files are streamed through buf, or through the default buffer of io.Copy
+// if buf is empty. The whole content is hashed either way, even if the size
+// changed meanwhile.
+func hashFile(f io.Reader, size int64, h hash.Hash, buf []byte) (string, error) {
+	h.Reset()
+	if size < smallFileSize {
+		bufp := smallFileBufs.Get().(*[]byte)
+		defer smallFileBufs.Put(bufp)
+		n, err := io.ReadFull(f, *bufp)
+		h.Write((*bufp)[:n])
+		switch {
+		case err == io.EOF || err == io.ErrUnexpectedEOF:
+			return hex.EncodeToString(h.Sum(nil)), nil
+		case err != nil:
+			return "", err
+		}
+		// The file grew beyond the buffer, stream the rest.
+	}
+	var err error
+	if len(buf) > 0 {
+		// Hiding a WriterTo implementation of f, like the one of *os.File, makes
+		// io.CopyBuffer actually use buf.
+		_, err = io.CopyBuffer(h, struct{ io.Reader }{f}, buf)
+	} else {
+		_, err = io.Copy(h, f)
+	}
+	if err != nil {
 		return "", err
 	}
 	return hex.EncodeToString(h.Sum(nil)), nil
 }
 
+// sha256AndSHA512HexLen is the length of the hex encoded sums built by sha256And512.
+const sha256AndSHA512HexLen = 2 * (sha256.Size + sha512.Size)
+
+// sha256And512 is a hash.Hash building a SHA-256 and a SHA-512 sum at once.
+// Its sum is the SHA-256 sum followed by the SHA-512 sum.
+type sha256And512 struct {
+	hash.Hash
+	sha512 hash.Hash
+}
+
+func newSHA256And512() *sha256And512 {
+	return &sha256And512{Hash: sha256.New(), sha512: sha512.New()}
+}
+
+func (h *sha256And512) Write(p []byte) (int, error) {
+	h.sha512.Write(p)
+	return h.Hash.Write(p)
+}
+
+func (h *sha256And512) Reset() {
+	h.Hash.Reset()
+	h.sha512.Reset()
+}
+
+func (h *sha256And512) Sum(b []byte) []byte {
+	return h.sha512.Sum(h.Hash.Sum(b))
+}
+
+func (h *sha256And512) Size() int {
+	return sha256.Size + sha512.Size
+}
+
+func (h *sha256And512) BlockSize() int {
+	return sha512.BlockSize
+}
+
+// policyFingerprint returns the SHA-256 fingerprint of the deterministically marshaled policy.
+func policyFingerprint(pol *fspb.Policy) (*fspb.Fingerprint, error) {
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(pol)
+	if err != nil {
+		return nil, err
+	}
+	return &fspb.Fingerprint{
+		Method: fspb.Fingerprint_SHA256,
+		Value:  fmt.Sprintf("%x", sha256.Sum256(b)),
+	}, nil
+}
+
+// ParseFileMode parses an octal file mode like "0400" containing only permission bits.
+// An empty string results in def.
+func ParseFileMode(s string, def os.FileMode) (os.FileMode, error) {
+	if s == "" {
+		return def, nil
+	}
+	m, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || os.FileMode(m)&^os.ModePerm != 0 {
+		return 0, fmt.Errorf("invalid file mode %q: must be octal permission bits like \"0400\"", s)
+	}
+	return os.FileMode(m), nil
+}
+
+// WriteWalk writes the binary proto of walk to path with the given file mode.
+func WriteWalk(path string, walk *fspb.Walk, mode os.FileMode) error {
+	walkBytes, err := proto.Marshal(walk)
+	if err != nil {
+		return err
+	}
+	return writeFile(path, walkBytes, mode)
+}
+
+// writeFile writes data to path, making sure the file ends up with mode
+// regardless of the umask and of whether it existed before.
+func writeFile(path string, data []byte, mode os.FileMode) error {
+	if err := os.WriteFile(path, data, mode); err != nil {
+		return err
+	}
+	return os.Chmod(path, mode)
+}
+
+// readFile reads the content of path, transparently decompressing it if its name ends in ".gz".
+func readFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decompress %q: %v", path, err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+	return io.ReadAll(r)
+}
+
 // readTextProto reads a text format proto buf and unmarshals it into the provided proto message.
+// Files ending in ".gz" are decompressed first.
 func readTextProto(path string, pb proto.Message) error {
-	b, err := os.ReadFile(path)
+	b, err := readFile(path)
 	if err != nil {
 		return err
 	}
 	return prototext.Unmarshal(b, pb)
 }
 
-// writeTextProto writes a text format proto buf for the provided proto message.
-func writeTextProto(path string, pb proto.Message) error {
+// writeTextProto writes a text format proto buf for the provided proto message with the given file mode.
+// Files ending in ".gz" are compressed so they can be read back by readTextProto.
+func writeTextProto(path string, pb proto.Message, mode os.FileMode) error {
 	blob := prototext.Format(pb)
 	// replace message boundary characters as curly braces look nicer (both is fine to parse)
 	blob = strings.Replace(strings.Replace(blob, "<", "{", -1), ">", "}", -1)
-	return os.WriteFile(path, []byte(blob), 0644)
+	if !strings.HasSuffix(path, ".gz") {
+		return writeFile(path, []byte(blob), mode)
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(blob)); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return writeFile(path, buf.Bytes(), mode)
 }
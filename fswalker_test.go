@@ -15,9 +15,12 @@
 package fswalker
 
 import (
+	"bytes"
 	"crypto/sha256"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"testing"
 	"time"
 
@@ -60,6 +63,98 @@ func TestWalkFilename(t *testing.T) {
 	}
 }
 
+func TestWalkFilenameUTC(t *testing.T) {
+	est := time.FixedZone("EST", -5*60*60)
+	testCases := []struct {
+		h        string
+		t        time.Time
+		wantFile string
+	}{
+		{
+			h:        "test-host.google.com",
+			t:        time.Date(2018, 12, 06, 10, 01, 02, 0, time.UTC),
+			wantFile: "test-host.google.com-2018-12-06T10-01-02Z-fswalker-state.pb",
+		}, {
+			h:        "test-host.google.com",
+			t:        time.Date(2018, 12, 31, 22, 01, 02, 0, est),
+			wantFile: "test-host.google.com-2019-01-01T03-01-02Z-fswalker-state.pb",
+		}, {
+			h:        "test-host.google.com",
+			wantFile: "test-host.google.com-*-fswalker-state.pb",
+		}, {
+			wantFile: "*-*-fswalker-state.pb",
+		},
+	}
+
+	for _, tc := range testCases {
+		gotFile := WalkFilenameUTC(tc.h, tc.t)
+		if gotFile != tc.wantFile {
+			t.Errorf("WalkFilenameUTC(%s, %s) = %q; want: %q", tc.h, tc.t, gotFile, tc.wantFile)
+		}
+	}
+}
+
+func TestWalkFilenameUTCOrdering(t *testing.T) {
+	// Times crossing hour, day, month and year boundaries in chronological order.
+	times := []time.Time{
+		time.Date(2018, 12, 31, 9, 59, 59, 0, time.UTC),
+		time.Date(2018, 12, 31, 10, 0, 0, 0, time.UTC),
+		time.Date(2018, 12, 31, 23, 59, 59, 0, time.UTC),
+		time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2019, 1, 9, 12, 0, 0, 0, time.UTC),
+		time.Date(2019, 1, 10, 1, 0, 0, 0, time.UTC),
+		time.Date(2019, 1, 31, 23, 0, 0, 0, time.UTC),
+		time.Date(2019, 2, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC),
+	}
+	var names []string
+	for _, ts := range times {
+		names = append(names, WalkFilenameUTC("host", ts))
+	}
+	if !sort.StringsAreSorted(names) {
+		t.Errorf("WalkFilenameUTC() names are not sorted lexically: %q", names)
+	}
+}
+
+func TestParseWalkFilename(t *testing.T) {
+	testCases := []struct {
+		name     string
+		wantHost string
+		wantTime time.Time
+		wantErr  bool
+	}{
+		{
+			name:     "test-host.google.com-20181206-100102-fswalker-state.pb",
+			wantHost: "test-host.google.com",
+			wantTime: time.Date(2018, 12, 06, 10, 01, 02, 0, time.Local),
+		}, {
+			name:     "/some/dir/test-host.google.com-2018-12-06T10-01-02Z-fswalker-state.pb",
+			wantHost: "test-host.google.com",
+			wantTime: time.Date(2018, 12, 06, 10, 01, 02, 0, time.UTC),
+		}, {
+			name:    "test-host.google.com-fswalker-state.pb",
+			wantErr: true,
+		}, {
+			name:    "test-host.google.com-20181206-100102.pb",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		gotHost, gotTime, err := ParseWalkFilename(tc.name)
+		switch {
+		case tc.wantErr && err == nil:
+			t.Errorf("ParseWalkFilename(%q) no error", tc.name)
+		case !tc.wantErr && err != nil:
+			t.Errorf("ParseWalkFilename(%q) error: %v", tc.name, err)
+		case !tc.wantErr:
+			if gotHost != tc.wantHost || !gotTime.Equal(tc.wantTime) {
+				t.Errorf("ParseWalkFilename(%q) = %q, %s; want %q, %s", tc.name, gotHost, gotTime, tc.wantHost, tc.wantTime)
+			}
+		}
+	}
+}
+
 func TestNormalizePath(t *testing.T) {
 	tests := []struct {
 		// arguments
@@ -87,15 +182,109 @@ func TestNormalizePath(t *testing.T) {
 	}
 }
 
-func TestSha256sum(t *testing.T) {
-	gotHash, err := sha256sum(filepath.Join(testdataDir, "hashSumTest"), sha256.New())
+func TestSha256sumNoFollow(t *testing.T) {
+	gotHash, err := sha256sumNoFollow(filepath.Join(testdataDir, "hashSumTest"), sha256.New(), nil)
 	if err != nil {
-		t.Errorf("sha256sum() error: %v", err)
+		t.Errorf("sha256sumNoFollow() error: %v", err)
 		return
 	}
 	const wantHash = "aeb02544df0ef515b21cab81ad5c0609b774f86879bf7e2e42c88efdaab2c75f"
 	if gotHash != wantHash {
-		t.Errorf("sha256sum() = %q; want: %q", gotHash, wantHash)
+		t.Errorf("sha256sumNoFollow() = %q; want: %q", gotHash, wantHash)
+	}
+}
+
+func TestHashFileSizes(t *testing.T) {
+	dir := t.TempDir()
+	h := sha256.New()
+	for _, size := range []int{0, 1, smallFileSize - 1, smallFileSize, smallFileSize + 1, 3*smallFileSize + 7} {
+		data := make([]byte, size)
+		for i := range data {
+			data[i] = byte(i * 31)
+		}
+		want := fmt.Sprintf("%x", sha256.Sum256(data))
+
+		path := filepath.Join(dir, fmt.Sprint(size))
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		for _, bufSize := range []int{0, 7, 1 << 20} {
+			buf := make([]byte, bufSize)
+			if got, err := sha256sumNoFollow(path, h, buf); err != nil || got != want {
+				t.Errorf("sha256sumNoFollow() of %d bytes with %d byte buffer = %q, %v; want %q", size, bufSize, got, err, want)
+			}
+			// The whole content is hashed no matter the expected size, e.g. if the
+			// file grew after it was stat'ed.
+			for _, expected := range []int64{0, int64(size), smallFileSize} {
+				if got, err := hashFile(bytes.NewReader(data), expected, h, buf); err != nil || got != want {
+					t.Errorf("hashFile() of %d bytes expected to be %d with %d byte buffer = %q, %v; want %q", size, expected, bufSize, got, err, want)
+				}
+			}
+		}
+	}
+}
+
+// BenchmarkHashLargeFile compares hashing a large file with different buffer sizes.
+func BenchmarkHashLargeFile(b *testing.B) {
+	const size = 64 << 20
+	path := filepath.Join(b.TempDir(), "large")
+	if err := os.WriteFile(path, bytes.Repeat([]byte("fswalker"), size/8), 0644); err != nil {
+		b.Fatal(err)
+	}
+	for _, bufSize := range []int{0, 32 << 10, 256 << 10, 1 << 20, 4 << 20} {
+		b.Run(fmt.Sprintf("buffer=%dKB", bufSize>>10), func(b *testing.B) {
+			b.SetBytes(size)
+			h := sha256.New()
+			buf := make([]byte, bufSize)
+			for i := 0; i < b.N; i++ {
+				if _, err := sha256sumNoFollow(path, h, buf); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkHashSmallFiles compares hashing many small files with the buffered
+// fast path against streaming them.
+func BenchmarkHashSmallFiles(b *testing.B) {
+	dir := b.TempDir()
+	var paths []string
+	for i := 0; i < 1000; i++ {
+		path := filepath.Join(dir, fmt.Sprint(i))
+		if err := os.WriteFile(path, bytes.Repeat([]byte{byte(i)}, 512+i), 0644); err != nil {
+			b.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	for _, bc := range []struct {
+		name string
+		// size returns the size to pass to hashFile.
+		size func(int64) int64
+	}{
+		{"buffered", func(size int64) int64 { return size }},
+		{"streamed", func(int64) int64 { return smallFileSize }},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			h := sha256.New()
+			for i := 0; i < b.N; i++ {
+				for _, path := range paths {
+					f, err := os.Open(path)
+					if err != nil {
+						b.Fatal(err)
+					}
+					info, err := f.Stat()
+					if err != nil {
+						b.Fatal(err)
+					}
+					if _, err := hashFile(f, bc.size(info.Size()), h, nil); err != nil {
+						b.Fatal(err)
+					}
+					f.Close()
+				}
+			}
+		})
 	}
 }
 
@@ -208,7 +397,7 @@ func TestWriteTextProtoReviews(t *testing.T) {
 	}
 	defer os.Remove(tmpfile.Name()) // clean up
 
-	if err := writeTextProto(tmpfile.Name(), wantReviews); err != nil {
+	if err := writeTextProto(tmpfile.Name(), wantReviews, defaultReportFileMode); err != nil {
 		t.Errorf("writeTextProto() error: %v", err)
 	}
 
@@ -221,3 +410,56 @@ func TestWriteTextProtoReviews(t *testing.T) {
 		t.Errorf("writeTextProto() reviews: diff (-want +got): \n%s", diff)
 	}
 }
+
+func TestParseFileMode(t *testing.T) {
+	testCases := []struct {
+		in      string
+		want    os.FileMode
+		wantErr bool
+	}{
+		{in: "", want: DefaultWalkFileMode},
+		{in: "0400", want: 0400},
+		{in: "640", want: 0640},
+		{in: "0777", want: 0777},
+		{in: "4755", wantErr: true},
+		{in: "0800", wantErr: true},
+		{in: "rw-r--r--", wantErr: true},
+	}
+	for _, tc := range testCases {
+		got, err := ParseFileMode(tc.in, DefaultWalkFileMode)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("ParseFileMode(%q) error = %v; want error %v", tc.in, err, tc.wantErr)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ParseFileMode(%q) = %#o; want %#o", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestReadWriteTextProtoGzip(t *testing.T) {
+	wantReviews := &fspb.Reviews{
+		Review: map[string]*fspb.Review{
+			"hostname": {WalkID: "id", WalkReference: "reference"},
+		},
+	}
+	path := filepath.Join(t.TempDir(), "reviews.asciipb.gz")
+	if err := writeTextProto(path, wantReviews, defaultReportFileMode); err != nil {
+		t.Fatalf("writeTextProto() error: %v", err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) < 2 || b[0] != 0x1f || b[1] != 0x8b {
+		t.Errorf("writeTextProto(%q) didn't write gzip data", path)
+	}
+
+	gotReviews := &fspb.Reviews{}
+	if err := readTextProto(path, gotReviews); err != nil {
+		t.Fatalf("readTextProto() error: %v", err)
+	}
+	if diff := cmp.Diff(wantReviews, gotReviews, cmp.Comparer(proto.Equal)); diff != "" {
+		t.Errorf("readTextProto() reviews: diff (-want +got):\n%s", diff)
+	}
+}
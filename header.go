@@ -0,0 +1,111 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+// notificationFieldNumber is the field number of Walk.Notification.
+var notificationFieldNumber = (&fspb.Walk{}).ProtoReflect().Descriptor().Fields().ByName("notification").Number()
+
+// WalkHeader holds the top-level metadata of a Walk without its files.
+type WalkHeader struct {
+	// Path is the path the Walk was read from.
+	Path string
+	// ID is the unique ID of the Walk.
+	ID string
+	// Version is the version of the proto structure of the Walk.
+	Version uint32
+	// Hostname is the hostname of the machine the Walk originates from.
+	Hostname string
+	// StartWalk and StopWalk are the start and stop time of the Walk.
+	// They are the zero time if not recorded.
+	StartWalk time.Time
+	StopWalk  time.Time
+	// Policy is the Policy that was used for the Walk.
+	Policy *fspb.Policy
+	// Labels are the operator provided labels of the Walk.
+	Labels map[string]string
+	// FileCount is the number of files in the Walk.
+	FileCount int
+	// NotificationCount is the number of notifications in the Walk.
+	NotificationCount int
+}
+
+// ReadWalkHeader reads the top-level metadata of the Walk stored at path, e.g. to
+// list the available Walks quickly. The files and notifications of the Walk are
+// only counted: they are skipped by their length without being unmarshaled, so
+// the cost of reading a Walk of millions of files is mostly the disk read.
+// Encrypted Walks are not supported as they need to be decrypted as a whole.
+func ReadWalkHeader(path string) (*WalkHeader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	br := bufio.NewReaderSize(f, 64<<10)
+	if header, _ := br.Peek(len(encryptedWalkHeader)); isEncryptedWalk(header) {
+		return nil, fmt.Errorf("walk %q is encrypted, its header can't be read", path)
+	}
+
+	h := &WalkHeader{Path: path}
+	walk := &fspb.Walk{}
+	skip := func(num protowire.Number) bool {
+		return num == fileFieldNumber || num == notificationFieldNumber
+	}
+	var buf []byte
+	for {
+		var num protowire.Number
+		if num, buf, err = readWalkField(br, buf, skip); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("unable to decode walk %q: %v", path, err)
+		}
+		switch {
+		case len(buf) > 0:
+			if err := (proto.UnmarshalOptions{Merge: true}).Unmarshal(buf, walk); err != nil {
+				return nil, fmt.Errorf("unable to decode walk %q: %v", path, err)
+			}
+		case num == fileFieldNumber:
+			h.FileCount++
+		case num == notificationFieldNumber:
+			h.NotificationCount++
+		}
+	}
+
+	h.ID = walk.Id
+	h.Version = walk.Version
+	h.Hostname = walk.Hostname
+	h.Policy = walk.Policy
+	h.Labels = walk.Labels
+	if walk.StartWalk != nil {
+		h.StartWalk = walk.StartWalk.AsTime()
+	}
+	if walk.StopWalk != nil {
+		h.StopWalk = walk.StopWalk.AsTime()
+	}
+	return h, nil
+}
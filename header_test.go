@@ -0,0 +1,100 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	tspb "google.golang.org/protobuf/types/known/timestamppb"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+func TestReadWalkHeader(t *testing.T) {
+	start := time.Date(2018, 1, 1, 10, 0, 0, 0, time.UTC)
+	stop := start.Add(time.Hour)
+	walk := &fspb.Walk{
+		Id:        "header",
+		Version:   1,
+		Hostname:  "testhost",
+		StartWalk: tspb.New(start),
+		StopWalk:  tspb.New(stop),
+		Policy:    &fspb.Policy{Version: 1, Include: []string{"/"}},
+		File: []*fspb.File{
+			{Path: "/etc/hosts", Info: &fspb.FileInfo{Name: "hosts", Size: 10}},
+			{Path: "/etc/passwd", Info: &fspb.FileInfo{Name: "passwd", Size: 20}},
+		},
+		Notification: []*fspb.Notification{
+			{Severity: fspb.Notification_WARNING, Path: "/x", Message: "warning"},
+		},
+	}
+	b, err := proto.Marshal(walk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A File entry which can't be unmarshaled proves that files are skipped
+	// instead of being materialized.
+	b = protowire.AppendTag(b, fileFieldNumber, protowire.BytesType)
+	b = protowire.AppendBytes(b, []byte{0xff, 0xff, 0xff})
+	path := filepath.Join(t.TempDir(), "walk.pb")
+	if err := os.WriteFile(path, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&Reporter{}).ReadWalk(path); err == nil {
+		t.Fatal("ReadWalk() of walk with corrupt file: no error")
+	}
+
+	got, err := ReadWalkHeader(path)
+	if err != nil {
+		t.Fatalf("ReadWalkHeader() error: %v", err)
+	}
+	want := &WalkHeader{
+		Path:              path,
+		ID:                "header",
+		Version:           1,
+		Hostname:          "testhost",
+		StartWalk:         start,
+		StopWalk:          stop,
+		Policy:            walk.Policy,
+		FileCount:         3,
+		NotificationCount: 1,
+	}
+	if diff := cmp.Diff(want, got, cmp.Comparer(proto.Equal)); diff != "" {
+		t.Errorf("ReadWalkHeader(): diff (-want +got):\n%s", diff)
+	}
+
+	if _, err := ReadWalkHeader(path + ".missing"); err == nil {
+		t.Error("ReadWalkHeader() of missing file: no error")
+	}
+	if err := os.WriteFile(path, b[:len(b)-2], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadWalkHeader(path); err == nil {
+		t.Error("ReadWalkHeader() of truncated walk: no error")
+	}
+	key := make([]byte, 32)
+	if err := WriteEncryptedWalk(path, walk, 0644, key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadWalkHeader(path); err == nil {
+		t.Error("ReadWalkHeader() of encrypted walk: no error")
+	}
+}
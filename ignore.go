@@ -0,0 +1,85 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreFileName is the name of the files listing excludes for the subtree they are in.
+const ignoreFileName = ".fswalkerignore"
+
+// ignoreScope holds the excludes of the ignore file in dir.
+type ignoreScope struct {
+	// dir is the normalized directory the ignore file is in.
+	dir string
+	// excludes are the patterns of the ignore file made relative to dir.
+	excludes []string
+}
+
+// contains returns true if the normalized path is in the subtree of the scope.
+func (s ignoreScope) contains(path string) bool {
+	return s.dir == "."+string(filepath.Separator) || strings.HasPrefix(path, s.dir)
+}
+
+// ignoredBy returns the path of the ignore file of scopes excluding path.
+func ignoredBy(path string, scopes []ignoreScope) (string, bool) {
+	for _, s := range scopes {
+		if isExcluded(path, s.excludes) {
+			return filepath.Join(s.dir, ignoreFileName), true
+		}
+	}
+	return "", false
+}
+
+// readIgnoreFile reads the ignore file in dir, if there is one.
+func (w *Walker) readIgnoreFile(dir string) (ignoreScope, error) {
+	scope := ignoreScope{dir: NormalizePath(dir, true)}
+	name := filepath.Join(dir, ignoreFileName)
+	var b []byte
+	var err error
+	if w.FS != nil {
+		b, err = fs.ReadFile(w.FS, name)
+	} else {
+		b, err = os.ReadFile(name)
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return scope, nil
+	}
+	if err != nil {
+		return scope, err
+	}
+
+	sep := string(filepath.Separator)
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		e := filepath.Join(dir, line)
+		if strings.HasSuffix(line, sep) {
+			e += sep
+		}
+		scope.excludes = append(scope.excludes, e)
+	}
+	return scope, s.Err()
+}
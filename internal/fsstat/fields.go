@@ -0,0 +1,27 @@
+package fsstat
+
+// Fields is a set of the fields of fspb.FileStat. Each field is represented by
+// the bit of its field number, e.g. 1<<1 for dev.
+type Fields uint64
+
+// The fields of fspb.FileStat.
+const (
+	FieldDev Fields = 1 << (iota + 1)
+	FieldInode
+	FieldNlink
+	FieldMode
+	FieldUid
+	FieldGid
+	FieldRdev
+	FieldSize
+	FieldBlksize
+	FieldBlocks
+	FieldAtime
+	FieldMtime
+	FieldCtime
+	FieldBtime
+	FieldAttributes
+
+	// AllFields holds all fields of fspb.FileStat.
+	AllFields = ^Fields(0)
+)
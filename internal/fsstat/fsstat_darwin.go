@@ -1,31 +1,63 @@
 package fsstat
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"syscall"
 
+	"golang.org/x/sys/unix"
+	tspb "google.golang.org/protobuf/types/known/timestamppb"
+
 	fspb "github.com/google/fswalker/proto/fswalker"
 )
 
-// ToStat returns a fspb.ToStat with the file info from the given file
-func ToStat(info os.FileInfo) (*fspb.FileStat, error) {
+// ToStat returns a fspb.FileStat with the given fields of the file info from the
+// given file. Fields which aren't requested are left unset and not converted.
+func ToStat(info os.FileInfo, fields Fields) (*fspb.FileStat, error) {
 	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
-		return &fspb.FileStat{
-			Dev:     uint64(stat.Dev),
-			Inode:   stat.Ino,
-			Nlink:   uint64(stat.Nlink),
-			Mode:    uint32(stat.Mode),
-			Uid:     stat.Uid,
-			Gid:     stat.Gid,
-			Rdev:    uint64(stat.Rdev),
-			Size:    stat.Size,
-			Blksize: int64(stat.Blksize),
-			Blocks:  stat.Blocks,
-			Atime:   timespec2Timestamp(stat.Atimespec),
-			Mtime:   timespec2Timestamp(stat.Mtimespec),
-			Ctime:   timespec2Timestamp(stat.Ctimespec),
-		}, nil
+		st := &fspb.FileStat{}
+		if fields&FieldDev != 0 {
+			st.Dev = uint64(stat.Dev)
+		}
+		if fields&FieldInode != 0 {
+			st.Inode = stat.Ino
+		}
+		if fields&FieldNlink != 0 {
+			st.Nlink = uint64(stat.Nlink)
+		}
+		if fields&FieldMode != 0 {
+			st.Mode = uint32(stat.Mode)
+		}
+		if fields&FieldUid != 0 {
+			st.Uid = stat.Uid
+		}
+		if fields&FieldGid != 0 {
+			st.Gid = stat.Gid
+		}
+		if fields&FieldRdev != 0 {
+			st.Rdev = uint64(stat.Rdev)
+		}
+		if fields&FieldSize != 0 {
+			st.Size = stat.Size
+		}
+		if fields&FieldBlksize != 0 {
+			st.Blksize = int64(stat.Blksize)
+		}
+		if fields&FieldBlocks != 0 {
+			st.Blocks = stat.Blocks
+		}
+		if fields&FieldAtime != 0 {
+			st.Atime = timespec2Timestamp(stat.Atimespec)
+		}
+		if fields&FieldMtime != 0 {
+			st.Mtime = timespec2Timestamp(stat.Mtimespec)
+		}
+		if fields&FieldCtime != 0 {
+			st.Ctime = timespec2Timestamp(stat.Ctimespec)
+		}
+		return st, nil
 	}
 
 	return nil, fmt.Errorf("unable to get file stat for %#v", info)
@@ -37,3 +69,64 @@ func Dev(info os.FileInfo) (uint64, bool) {
 	}
 	return 0, false
 }
+
+// BirthTime returns the birth time of the file from its stat info.
+// It returns nil without an error if it is not available.
+func BirthTime(path string, info os.FileInfo) (*tspb.Timestamp, error) {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return timespec2Timestamp(stat.Birthtimespec), nil
+	}
+	return nil, nil
+}
+
+// Attributes returns the statx(2) attributes of the file at path, which are only available on Linux.
+func Attributes(path string) (uint64, *fspb.Fingerprint, error) {
+	return 0, nil, nil
+}
+
+// resourceForkXattr is the extended attribute the resource fork of a file is exposed as.
+const resourceForkXattr = "com.apple.ResourceFork"
+
+// maxResourceForkReads is how often reading a resource fork which keeps growing
+// between getting its size and reading it is attempted.
+const maxResourceForkReads = 3
+
+// DataStreams returns the resource fork of the file at path with its SHA-256
+// fingerprint. Resource forks larger than maxHashSize are recorded with their
+// size only, as they are read into memory at once. It returns nil without an
+// error if the file has none.
+func DataStreams(path string, maxHashSize uint64) ([]*fspb.DataStream, error) {
+	for i := 0; ; i++ {
+		size, err := unix.Lgetxattr(path, resourceForkXattr, nil)
+		if err == unix.ENOATTR || err == unix.ENOTSUP {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to get resource fork size of %q: %v", path, err)
+		}
+		if uint64(size) > maxHashSize {
+			return []*fspb.DataStream{{Name: resourceForkXattr, Size: int64(size)}}, nil
+		}
+		buf := make([]byte, size)
+		size, err = unix.Lgetxattr(path, resourceForkXattr, buf)
+		switch {
+		case err == unix.ERANGE && i < maxResourceForkReads-1:
+			// The resource fork grew since getting its size.
+			continue
+		case err == unix.ENOATTR:
+			// The resource fork was removed since getting its size.
+			return nil, nil
+		case err != nil:
+			return nil, fmt.Errorf("unable to read resource fork of %q: %v", path, err)
+		}
+		sum := sha256.Sum256(buf[:size])
+		return []*fspb.DataStream{{
+			Name: resourceForkXattr,
+			Size: int64(size),
+			Fingerprint: &fspb.Fingerprint{
+				Method: fspb.Fingerprint_SHA256,
+				Value:  hex.EncodeToString(sum[:]),
+			},
+		}}, nil
+	}
+}
@@ -1,31 +1,63 @@
 package fsstat
 
 import (
+	"encoding/hex"
 	"fmt"
 	"os"
 	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+	tspb "google.golang.org/protobuf/types/known/timestamppb"
 
 	fspb "github.com/google/fswalker/proto/fswalker"
 )
 
-// ToStat returns a fspb.ToStat with the file info from the given file
-func ToStat(info os.FileInfo) (*fspb.FileStat, error) {
+// ToStat returns a fspb.FileStat with the given fields of the file info from the
+// given file. Fields which aren't requested are left unset and not converted.
+func ToStat(info os.FileInfo, fields Fields) (*fspb.FileStat, error) {
 	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
-		return &fspb.FileStat{
-			Dev:     stat.Dev,
-			Inode:   stat.Ino,
-			Nlink:   stat.Nlink,
-			Mode:    stat.Mode,
-			Uid:     stat.Uid,
-			Gid:     stat.Gid,
-			Rdev:    stat.Rdev,
-			Size:    stat.Size,
-			Blksize: stat.Blksize,
-			Blocks:  stat.Blocks,
-			Atime:   timespec2Timestamp(stat.Atim),
-			Mtime:   timespec2Timestamp(stat.Mtim),
-			Ctime:   timespec2Timestamp(stat.Ctim),
-		}, nil
+		st := &fspb.FileStat{}
+		if fields&FieldDev != 0 {
+			st.Dev = stat.Dev
+		}
+		if fields&FieldInode != 0 {
+			st.Inode = stat.Ino
+		}
+		if fields&FieldNlink != 0 {
+			st.Nlink = stat.Nlink
+		}
+		if fields&FieldMode != 0 {
+			st.Mode = stat.Mode
+		}
+		if fields&FieldUid != 0 {
+			st.Uid = stat.Uid
+		}
+		if fields&FieldGid != 0 {
+			st.Gid = stat.Gid
+		}
+		if fields&FieldRdev != 0 {
+			st.Rdev = stat.Rdev
+		}
+		if fields&FieldSize != 0 {
+			st.Size = stat.Size
+		}
+		if fields&FieldBlksize != 0 {
+			st.Blksize = stat.Blksize
+		}
+		if fields&FieldBlocks != 0 {
+			st.Blocks = stat.Blocks
+		}
+		if fields&FieldAtime != 0 {
+			st.Atime = timespec2Timestamp(stat.Atim)
+		}
+		if fields&FieldMtime != 0 {
+			st.Mtime = timespec2Timestamp(stat.Mtim)
+		}
+		if fields&FieldCtime != 0 {
+			st.Ctime = timespec2Timestamp(stat.Ctim)
+		}
+		return st, nil
 	}
 
 	return nil, fmt.Errorf("unable to get file stat for %#v", info)
@@ -37,3 +69,92 @@ func Dev(info os.FileInfo) (uint64, bool) {
 	}
 	return 0, false
 }
+
+// BirthTime returns the birth time of the file at path using statx(2).
+// It returns nil without an error if the kernel or filesystem doesn't support it.
+func BirthTime(path string, info os.FileInfo) (*tspb.Timestamp, error) {
+	var stx unix.Statx_t
+	err := unix.Statx(unix.AT_FDCWD, path, unix.AT_SYMLINK_NOFOLLOW|unix.AT_STATX_DONT_SYNC, unix.STATX_BTIME, &stx)
+	if err == unix.ENOSYS || err == unix.EOPNOTSUPP {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to get birth time for %q: %v", path, err)
+	}
+	if stx.Mask&unix.STATX_BTIME == 0 {
+		return nil, nil
+	}
+	return &tspb.Timestamp{Seconds: stx.Btime.Sec, Nanos: int32(stx.Btime.Nsec)}, nil
+}
+
+// statx and measureVerity are replaced in tests.
+var (
+	statx         = unix.Statx
+	measureVerity = measureVerityDigest
+)
+
+// Attributes returns the STATX_ATTR_* flags of the file at path using statx(2).
+// For files with fs-verity enabled, the digest measured by the kernel is returned as well.
+// It returns no attributes without an error if the kernel doesn't support them.
+func Attributes(path string) (uint64, *fspb.Fingerprint, error) {
+	var stx unix.Statx_t
+	err := statx(unix.AT_FDCWD, path, unix.AT_SYMLINK_NOFOLLOW|unix.AT_STATX_DONT_SYNC, unix.STATX_BASIC_STATS, &stx)
+	if err == unix.ENOSYS || err == unix.EOPNOTSUPP {
+		return 0, nil, nil
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("unable to get attributes for %q: %v", path, err)
+	}
+	attrs := stx.Attributes & stx.Attributes_mask
+	if attrs&unix.STATX_ATTR_VERITY == 0 || stx.Mode&unix.S_IFMT != unix.S_IFREG {
+		return attrs, nil, nil
+	}
+
+	alg, digest, err := measureVerity(path)
+	if err != nil {
+		return attrs, nil, fmt.Errorf("unable to measure fs-verity digest for %q: %v", path, err)
+	}
+	var method fspb.Fingerprint_Method
+	switch alg {
+	case unix.FS_VERITY_HASH_ALG_SHA256:
+		method = fspb.Fingerprint_FSVERITY_SHA256
+	case unix.FS_VERITY_HASH_ALG_SHA512:
+		method = fspb.Fingerprint_FSVERITY_SHA512
+	default:
+		return attrs, nil, fmt.Errorf("unknown fs-verity hash algorithm %d for %q", alg, path)
+	}
+	return attrs, &fspb.Fingerprint{Method: method, Value: hex.EncodeToString(digest)}, nil
+}
+
+// DataStreams returns the named data streams of the file at path besides its
+// content. Linux filesystems have none, so it always returns nil.
+func DataStreams(path string, maxHashSize uint64) ([]*fspb.DataStream, error) {
+	return nil, nil
+}
+
+// maxVerityDigestSize is the size of the largest digest supported by fs-verity (SHA-512).
+const maxVerityDigestSize = 64
+
+// measureVerityDigest returns the hash algorithm and fs-verity digest of the file at path.
+func measureVerityDigest(path string) (uint16, []byte, error) {
+	fd, err := unix.Open(path, unix.O_RDONLY|unix.O_NOFOLLOW|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer unix.Close(fd)
+
+	// struct fsverity_digest { __u16 digest_algorithm; __u16 digest_size; __u8 digest[]; }
+	var buf struct {
+		alg    uint16
+		size   uint16
+		digest [maxVerityDigestSize]byte
+	}
+	buf.size = maxVerityDigestSize
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), unix.FS_IOC_MEASURE_VERITY, uintptr(unsafe.Pointer(&buf))); errno != 0 {
+		return 0, nil, errno
+	}
+	if buf.size > maxVerityDigestSize {
+		return 0, nil, fmt.Errorf("invalid digest size %d", buf.size)
+	}
+	return buf.alg, buf.digest[:buf.size], nil
+}
@@ -0,0 +1,118 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fsstat
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/sys/unix"
+	"google.golang.org/protobuf/proto"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+func TestAttributes(t *testing.T) {
+	digest := bytes.Repeat([]byte{0xab}, 32)
+	testCases := []struct {
+		desc      string
+		stx       unix.Statx_t
+		statxErr  error
+		wantAttrs uint64
+		wantFp    *fspb.Fingerprint
+	}{
+		{
+			desc: "verity file",
+			stx: unix.Statx_t{
+				Mode:            unix.S_IFREG | 0644,
+				Attributes:      unix.STATX_ATTR_VERITY | unix.STATX_ATTR_ENCRYPTED,
+				Attributes_mask: unix.STATX_ATTR_VERITY | unix.STATX_ATTR_ENCRYPTED | unix.STATX_ATTR_DAX,
+			},
+			wantAttrs: unix.STATX_ATTR_VERITY | unix.STATX_ATTR_ENCRYPTED,
+			wantFp: &fspb.Fingerprint{
+				Method: fspb.Fingerprint_FSVERITY_SHA256,
+				Value:  hex.EncodeToString(digest),
+			},
+		}, {
+			desc: "attributes outside of mask are ignored",
+			stx: unix.Statx_t{
+				Mode:            unix.S_IFREG | 0644,
+				Attributes:      unix.STATX_ATTR_VERITY | unix.STATX_ATTR_DAX,
+				Attributes_mask: unix.STATX_ATTR_DAX,
+			},
+			wantAttrs: unix.STATX_ATTR_DAX,
+		}, {
+			desc: "verity directory",
+			stx: unix.Statx_t{
+				Mode:            unix.S_IFDIR | 0755,
+				Attributes:      unix.STATX_ATTR_VERITY,
+				Attributes_mask: unix.STATX_ATTR_VERITY,
+			},
+			wantAttrs: unix.STATX_ATTR_VERITY,
+		}, {
+			desc:     "no statx support",
+			statxErr: unix.ENOSYS,
+		},
+	}
+
+	origStatx, origMeasure := statx, measureVerity
+	defer func() { statx, measureVerity = origStatx, origMeasure }()
+	measureVerity = func(string) (uint16, []byte, error) {
+		return unix.FS_VERITY_HASH_ALG_SHA256, digest, nil
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			statx = func(dirfd int, path string, flags int, mask int, stx *unix.Statx_t) error {
+				*stx = tc.stx
+				return tc.statxErr
+			}
+			gotAttrs, gotFp, err := Attributes("/file")
+			if err != nil {
+				t.Fatalf("Attributes() error: %v", err)
+			}
+			if gotAttrs != tc.wantAttrs {
+				t.Errorf("Attributes() attributes = %#x; want %#x", gotAttrs, tc.wantAttrs)
+			}
+			if !proto.Equal(gotFp, tc.wantFp) {
+				t.Errorf("Attributes() fingerprint = %v; want %v", gotFp, tc.wantFp)
+			}
+		})
+	}
+}
+
+func TestToStatFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(path, []byte("content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	st, err := ToStat(info, FieldSize|FieldCtime)
+	if err != nil {
+		t.Fatalf("ToStat() error: %v", err)
+	}
+	if st.Size != 7 || st.Ctime == nil {
+		t.Errorf("ToStat() = %v; want size and ctime", st)
+	}
+	if st.Inode != 0 || st.Mode != 0 || st.Atime != nil || st.Mtime != nil {
+		t.Errorf("ToStat() = %v; want only size and ctime", st)
+	}
+}
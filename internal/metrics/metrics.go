@@ -15,13 +15,19 @@
 // Package metrics implements generic metrics.
 package metrics
 
+import "sync"
+
 // Counter keeps count of metrics for parallel running routines.
+// All methods are safe for concurrent use.
 type Counter struct {
+	mu     sync.Mutex
 	counts map[string]int64
 }
 
 // Add adds count to metric. If metric doesn't exist, it creates it.
 func (c *Counter) Add(count int64, metric string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.counts == nil {
 		c.counts = make(map[string]int64)
 	}
@@ -31,6 +37,8 @@ func (c *Counter) Add(count int64, metric string) {
 
 // Metrics returns a slice of metrics which are tracked.
 func (c *Counter) Metrics() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	var metrics []string
 	for m := range c.counts {
 		metrics = append(metrics, m)
@@ -42,6 +50,20 @@ func (c *Counter) Metrics() []string {
 // Get returns the value of a specific metric based on its name as well
 // as a bool indicating the value was read successfully.
 func (c *Counter) Get(name string) (int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	val, ok := c.counts[name]
 	return val, ok
 }
+
+// Snapshot returns a point-in-time copy of all metrics and their values.
+// It can be called while other routines are still adding to the Counter.
+func (c *Counter) Snapshot() map[string]int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	snapshot := make(map[string]int64, len(c.counts))
+	for m, v := range c.counts {
+		snapshot[m] = v
+	}
+	return snapshot
+}
@@ -46,3 +46,47 @@ func TestCounter(t *testing.T) {
 		t.Errorf("c.Metrics()[0] = %q; want %q", m[0], wantMetric)
 	}
 }
+
+func TestCounterSnapshot(t *testing.T) {
+	const workers = 10
+	const adds = 1000
+	c := &Counter{}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < adds; j++ {
+				// Both metrics are always updated together, so a consistent
+				// snapshot never has "b" ahead of "a".
+				c.Add(1, "a")
+				c.Add(1, "b")
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+		}
+		s := c.Snapshot()
+		if s["b"] > s["a"] {
+			t.Fatalf("c.Snapshot() = %v; want b <= a", s)
+		}
+		s["a"] = -1 // Modifying the snapshot must not affect the Counter.
+	}
+
+	want := map[string]int64{"a": workers * adds, "b": workers * adds}
+	got := c.Snapshot()
+	if len(got) != len(want) || got["a"] != want["a"] || got["b"] != want["b"] {
+		t.Errorf("c.Snapshot() = %v; want %v", got, want)
+	}
+}
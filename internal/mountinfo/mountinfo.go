@@ -0,0 +1,211 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mountinfo parses the Linux mount table as found in /proc/self/mountinfo.
+package mountinfo
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Mount is a single entry of the mount table.
+type Mount struct {
+	ID       int
+	ParentID int
+	// Major and Minor are the device numbers of the mounted filesystem.
+	Major uint32
+	Minor uint32
+	// Root is the path within the filesystem which forms the root of this mount.
+	// It is "/" unless the mount is a bind mount of a subdirectory.
+	Root       string
+	MountPoint string
+	FSType     string
+	Source     string
+}
+
+// Dev returns the device number of the mount as reported by stat(2) on Linux.
+func (m *Mount) Dev() uint64 {
+	return Mkdev(m.Major, m.Minor)
+}
+
+// Mkdev returns the Linux device number for the given major and minor numbers.
+func Mkdev(major, minor uint32) uint64 {
+	return uint64(minor&0xff) | uint64(major&0xfff)<<8 | uint64(minor&^0xff)<<12 | uint64(major&^0xfff)<<32
+}
+
+// Parse reads a mount table in the format of /proc/self/mountinfo.
+func Parse(r io.Reader) ([]*Mount, error) {
+	var mounts []*Mount
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := s.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		m, err := parseLine(line)
+		if err != nil {
+			return nil, err
+		}
+		mounts = append(mounts, m)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return mounts, nil
+}
+
+// parseLine parses a single mountinfo line, e.g.:
+// 36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+func parseLine(line string) (*Mount, error) {
+	fields := strings.Fields(line)
+	// The optional fields are terminated by a single hyphen.
+	sep := -1
+	for i := 6; i < len(fields); i++ {
+		if fields[i] == "-" {
+			sep = i
+			break
+		}
+	}
+	if len(fields) < 6 || sep < 0 || len(fields) < sep+3 {
+		return nil, fmt.Errorf("malformed mountinfo line %q", line)
+	}
+
+	id, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return nil, fmt.Errorf("malformed mount ID in %q: %v", line, err)
+	}
+	parentID, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed parent ID in %q: %v", line, err)
+	}
+	majStr, minStr, ok := strings.Cut(fields[2], ":")
+	if !ok {
+		return nil, fmt.Errorf("malformed device number in %q", line)
+	}
+	major, err := strconv.ParseUint(majStr, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("malformed major device number in %q: %v", line, err)
+	}
+	minor, err := strconv.ParseUint(minStr, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("malformed minor device number in %q: %v", line, err)
+	}
+
+	return &Mount{
+		ID:         id,
+		ParentID:   parentID,
+		Major:      uint32(major),
+		Minor:      uint32(minor),
+		Root:       unescape(fields[3]),
+		MountPoint: unescape(fields[4]),
+		FSType:     fields[sep+1],
+		Source:     unescape(fields[sep+2]),
+	}, nil
+}
+
+// unescape replaces the octal escapes the kernel uses for whitespace and
+// backslashes in paths (e.g. "\040" for a space).
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				sb.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+// ByDevice indexes the given mounts by their device number.
+// If multiple mounts share a device (e.g. bind mounts), the first one is kept.
+func ByDevice(mounts []*Mount) map[uint64]*Mount {
+	byDev := make(map[uint64]*Mount, len(mounts))
+	for _, m := range mounts {
+		if _, ok := byDev[m.Dev()]; !ok {
+			byDev[m.Dev()] = m
+		}
+	}
+	return byDev
+}
+
+// BindSources returns the source path of all bind mounts keyed by their mount point.
+// A mount is considered a bind mount if it either mounts a subdirectory of a
+// filesystem or if the same filesystem root is already mounted elsewhere.
+// The source is resolved against another mount of the same device which contains
+// the bind mount's root.
+func BindSources(mounts []*Mount) map[string]string {
+	sources := map[string]string{}
+	for _, m := range mounts {
+		var src *Mount
+		for _, o := range mounts {
+			if o == m || o.Dev() != m.Dev() || !within(m.Root, o.Root) {
+				continue
+			}
+			// A mount of the filesystem root is only a bind mount of another one
+			// mounted before it.
+			if m.Root == o.Root && o.ID > m.ID {
+				continue
+			}
+			// Prefer the mount exposing the largest part of the filesystem.
+			if src == nil || len(o.Root) < len(src.Root) {
+				src = o
+			}
+		}
+		if src != nil {
+			rel := strings.TrimPrefix(strings.TrimPrefix(m.Root, src.Root), "/")
+			sources[m.MountPoint] = path.Join(src.MountPoint, rel)
+		}
+	}
+	return sources
+}
+
+// Containing returns the mount of mounts whose mount point contains path, i.e.
+// the mount path is on, or nil if there is none. Of several mounts on the same
+// mount point, the last one is returned as it covers the others.
+func Containing(mounts []*Mount, path string) *Mount {
+	var found *Mount
+	for _, m := range mounts {
+		if within(path, m.MountPoint) && (found == nil || len(m.MountPoint) >= len(found.MountPoint)) {
+			found = m
+		}
+	}
+	return found
+}
+
+// within returns true if p is dir or a path below it.
+func within(p, dir string) bool {
+	return p == dir || dir == "/" || strings.HasPrefix(p, dir+"/")
+}
+
+// IsVirtual returns true if the filesystem type is an overlay or FUSE filesystem.
+// These are commonly used by container runtimes and have synthetic device numbers.
+func IsVirtual(fsType string) bool {
+	switch fsType {
+	case "overlay", "aufs":
+		return true
+	}
+	return fsType == "fuse" || strings.HasPrefix(fsType, "fuse.") || fsType == "fuseblk"
+}
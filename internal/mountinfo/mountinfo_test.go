@@ -0,0 +1,124 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mountinfo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testMountInfo = `22 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw
+36 22 0:52 / /var/lib/docker/overlay2/abc/merged rw,relatime - overlay overlay rw,lowerdir=/l,upperdir=/u,workdir=/w
+37 22 8:1 /srv/data /mnt/my\040data rw,relatime shared:1 master:2 - ext4 /dev/sda1 rw
+38 22 0:60 / /home/user/remote rw,nosuid - fuse.sshfs user@host:/ rw
+`
+
+func TestParse(t *testing.T) {
+	want := []*Mount{
+		{ID: 22, ParentID: 1, Major: 8, Minor: 1, Root: "/", MountPoint: "/", FSType: "ext4", Source: "/dev/sda1"},
+		{ID: 36, ParentID: 22, Major: 0, Minor: 52, Root: "/", MountPoint: "/var/lib/docker/overlay2/abc/merged", FSType: "overlay", Source: "overlay"},
+		{ID: 37, ParentID: 22, Major: 8, Minor: 1, Root: "/srv/data", MountPoint: "/mnt/my data", FSType: "ext4", Source: "/dev/sda1"},
+		{ID: 38, ParentID: 22, Major: 0, Minor: 60, Root: "/", MountPoint: "/home/user/remote", FSType: "fuse.sshfs", Source: "user@host:/"},
+	}
+	got, err := Parse(strings.NewReader(testMountInfo))
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Parse(): diff (-want +got):\n%s", diff)
+	}
+
+	if _, err := Parse(strings.NewReader("22 1 8:1 / / rw\n")); err == nil {
+		t.Error("Parse() of malformed line: no error")
+	}
+}
+
+func TestByDevice(t *testing.T) {
+	mounts, err := Parse(strings.NewReader(testMountInfo))
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	byDev := ByDevice(mounts)
+	if n := len(byDev); n != 3 {
+		t.Errorf("len(ByDevice()) = %d; want 3", n)
+	}
+	if m := byDev[Mkdev(8, 1)]; m == nil || m.MountPoint != "/" {
+		t.Errorf("ByDevice()[8:1] = %+v; want mount of /", m)
+	}
+	// Device numbers above 255 use the split encoding.
+	if got, want := Mkdev(259, 300), uint64(0x11032c); got != want {
+		t.Errorf("Mkdev(259, 300) = %#x; want %#x", got, want)
+	}
+}
+
+func TestContaining(t *testing.T) {
+	mounts, err := Parse(strings.NewReader(testMountInfo))
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	for path, want := range map[string]string{
+		"/":                         "/",
+		"/etc/passwd":               "/",
+		"/home/user/remote":         "/home/user/remote",
+		"/home/user/remote/a/b":     "/home/user/remote",
+		"/home/user/remote-backup/": "/",
+	} {
+		if m := Containing(mounts, path); m == nil || m.MountPoint != want {
+			t.Errorf("Containing(%q) = %+v; want mount of %q", path, m, want)
+		}
+	}
+	if m := Containing(nil, "/"); m != nil {
+		t.Errorf("Containing(nil) = %+v; want nil", m)
+	}
+}
+
+func TestIsVirtual(t *testing.T) {
+	for fsType, want := range map[string]bool{
+		"overlay":    true,
+		"fuse.sshfs": true,
+		"fuse":       true,
+		"ext4":       false,
+		"nfs":        false,
+		"fusectl":    false,
+	} {
+		if got := IsVirtual(fsType); got != want {
+			t.Errorf("IsVirtual(%q) = %v; want %v", fsType, got, want)
+		}
+	}
+}
+
+func TestBindSources(t *testing.T) {
+	mounts, err := Parse(strings.NewReader(`22 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw
+23 22 8:2 /@home /home rw,relatime - btrfs /dev/sda2 rw
+24 22 8:2 /@ /data rw,relatime - btrfs /dev/sda2 rw
+36 22 0:52 / /merged rw,relatime - overlay overlay rw
+37 22 8:1 /srv/data /mnt/my\040data rw,relatime - ext4 /dev/sda1 rw
+38 22 8:1 / /mnt/root rw,relatime - ext4 /dev/sda1 rw
+39 22 8:2 /@home/user /mnt/user rw,relatime - btrfs /dev/sda2 rw
+`))
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	want := map[string]string{
+		"/mnt/my data": "/srv/data",
+		"/mnt/root":    "/",
+		"/mnt/user":    "/home/user",
+	}
+	if diff := cmp.Diff(want, BindSources(mounts)); diff != "" {
+		t.Errorf("BindSources(): diff (-want +got):\n%s", diff)
+	}
+}
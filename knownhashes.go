@@ -0,0 +1,87 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// KnownHashes is a database of known (typically known bad) file hashes.
+// It maps lower case hex encoded hashes to a description, which may be empty.
+type KnownHashes map[string]string
+
+// ReadKnownHashes reads a known hashes database from path. Every line holds a
+// hex encoded hash, optionally followed by whitespace and a description, e.g.
+// the name of the malware. Empty lines and lines starting with "#" are skipped.
+// Files ending in ".gz" are decompressed.
+func ReadKnownHashes(path string) (KnownHashes, error) {
+	b, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	known := KnownHashes{}
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		hash, desc := line, ""
+		if i := strings.IndexAny(line, " \t"); i >= 0 {
+			hash, desc = line[:i], strings.TrimSpace(line[i:])
+		}
+		hash = strings.ToLower(hash)
+		if _, err := hex.DecodeString(hash); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid hash %q", path, n, hash)
+		}
+		known[hash] = desc
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return known, nil
+}
+
+// CheckKnownHashes looks up the fingerprints of all files of the after Walk in
+// known and records the files matching any of them in KnownBad.
+func (r *Report) CheckKnownHashes(known KnownHashes) {
+	r.KnownBad = nil
+	for _, f := range r.WalkAfter.GetFile() {
+		for _, fp := range f.Fingerprint {
+			desc, ok := known[strings.ToLower(fp.Value)]
+			if !ok {
+				continue
+			}
+			diff := fmt.Sprintf("known hash %s", fp.Value)
+			if desc != "" {
+				diff += fmt.Sprintf(" (%s)", desc)
+			}
+			r.KnownBad = append(r.KnownBad, ActionData{After: f, Diff: diff})
+			break
+		}
+	}
+	slices.SortFunc(r.KnownBad, func(a, b ActionData) bool {
+		return a.After.Path < b.After.Path
+	})
+	if r.Counter != nil {
+		r.Counter.Add(int64(len(r.KnownBad)), "after-files-known-hash")
+	}
+}
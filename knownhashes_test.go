@@ -0,0 +1,89 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+func TestCheckKnownHashes(t *testing.T) {
+	const badHash = "4c1f8a3be4d2dd6d6ccb5f2c8cd4f5e3e1b0c9d3e8b5d35a7d2e0c5f4b6a9e21"
+	path := filepath.Join(t.TempDir(), "known-bad.txt")
+	db := "# known bad hashes\n\n" + strings.ToUpper(badHash) + "  Linux.Backdoor\n"
+	if err := os.WriteFile(path, []byte(db), 0644); err != nil {
+		t.Fatal(err)
+	}
+	known, err := ReadKnownHashes(path)
+	if err != nil {
+		t.Fatalf("ReadKnownHashes() error: %v", err)
+	}
+	if diff := cmp.Diff(KnownHashes{badHash: "Linux.Backdoor"}, known); diff != "" {
+		t.Errorf("ReadKnownHashes(): diff (-want +got):\n%s", diff)
+	}
+
+	before := &fspb.Walk{Id: "1", File: []*fspb.File{{
+		Path:        "/usr/bin/ls",
+		Info:        &fspb.FileInfo{},
+		Fingerprint: []*fspb.Fingerprint{{Method: fspb.Fingerprint_SHA256, Value: badHash}},
+	}}}
+	after := &fspb.Walk{Id: "2", File: []*fspb.File{
+		{
+			Path:        "/usr/bin/ls",
+			Info:        &fspb.FileInfo{},
+			Fingerprint: []*fspb.Fingerprint{{Method: fspb.Fingerprint_SHA256, Value: badHash}},
+		}, {
+			Path:        "/usr/bin/cat",
+			Info:        &fspb.FileInfo{},
+			Fingerprint: []*fspb.Fingerprint{{Method: fspb.Fingerprint_SHA256, Value: strings.Repeat("0", 64)}},
+		},
+	}}
+
+	r := &Reporter{config: &fspb.ReportConfig{}, KnownHashes: known}
+	report, err := r.Compare(before, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	if len(report.KnownBad) != 1 || report.KnownBad[0].After.Path != "/usr/bin/ls" {
+		t.Fatalf("Compare() KnownBad = %v; want only /usr/bin/ls", report.KnownBad)
+	}
+	if n, _ := report.Counter.Get("after-files-known-hash"); n != 1 {
+		t.Errorf("after-files-known-hash = %d; want 1", n)
+	}
+	if report.Empty() {
+		t.Error("report.Empty() = true; want false with a known bad file")
+	}
+	out := captureStdout(t, func() { r.PrintDiffSummary(report) })
+	want := "/usr/bin/ls: known hash " + badHash + " (Linux.Backdoor) (HIGH SEVERITY)"
+	if !strings.Contains(out, want) {
+		t.Errorf("PrintDiffSummary() output doesn't contain %q:\n%s", want, out)
+	}
+}
+
+func TestReadKnownHashesInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "known-bad.txt")
+	if err := os.WriteFile(path, []byte("not-a-hash\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadKnownHashes(path); err == nil {
+		t.Error("ReadKnownHashes() with an invalid hash succeeded; want error")
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+// ManifestMethods returns the fingerprint methods manifests can be written for,
+// i.e. the methods the walker hashes file contents with. SHA512 fingerprints are
+// only recorded if hashSha512 is set in the policy. Walks hold no fingerprints
+// of other methods usable for a manifest.
+func ManifestMethods() []fspb.Fingerprint_Method {
+	return []fspb.Fingerprint_Method{fspb.Fingerprint_SHA256, fspb.Fingerprint_SHA512}
+}
+
+// WriteManifest writes a checksum manifest of the files in walk to w in the format
+// of coreutils (e.g. "sha256sum -c"). For every file the fingerprint with the given
+// method is used; files without such a fingerprint are skipped.
+// It returns the number of files written.
+func WriteManifest(w io.Writer, walk *fspb.Walk, method fspb.Fingerprint_Method) (int, error) {
+	var lines []string
+	for _, f := range walk.File {
+		for _, fp := range f.Fingerprint {
+			if fp.Method == method {
+				lines = append(lines, manifestLine(fp.Value, f.Path))
+				break
+			}
+		}
+	}
+	// Sort by path to make the manifest reproducible.
+	slices.SortFunc(lines, func(a, b string) bool {
+		return manifestPath(a) < manifestPath(b)
+	})
+
+	bw := bufio.NewWriter(w)
+	for _, l := range lines {
+		if _, err := bw.WriteString(l); err != nil {
+			return 0, err
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return 0, err
+	}
+	return len(lines), nil
+}
+
+// manifestLine formats a single manifest line. Like coreutils, paths containing
+// a backslash, newline or carriage return are escaped and the line is prefixed with a backslash.
+func manifestLine(sum, path string) string {
+	if !strings.ContainsAny(path, "\\\n\r") {
+		return fmt.Sprintf("%s  %s\n", sum, path)
+	}
+	path = strings.NewReplacer("\\", "\\\\", "\n", "\\n", "\r", "\\r").Replace(path)
+	return fmt.Sprintf("\\%s  %s\n", sum, path)
+}
+
+// manifestPath returns the (escaped) path of a manifest line.
+func manifestPath(line string) string {
+	if i := strings.Index(line, "  "); i >= 0 {
+		return line[i+2:]
+	}
+	return line
+}
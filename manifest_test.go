@@ -0,0 +1,110 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"crypto/sha512"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+func TestWriteManifest(t *testing.T) {
+	walk := &fspb.Walk{
+		File: []*fspb.File{
+			{
+				Path: "/usr/bin/b",
+				Fingerprint: []*fspb.Fingerprint{
+					{Method: fspb.Fingerprint_SHA256, Value: "b256"},
+					{Method: fspb.Fingerprint_SHA512, Value: "b512"},
+				},
+			}, {
+				Path: "/usr/bin/a",
+				Fingerprint: []*fspb.Fingerprint{
+					{Method: fspb.Fingerprint_SHA512, Value: "a512"},
+					{Method: fspb.Fingerprint_SHA256, Value: "a256"},
+				},
+			}, {
+				Path:        "/usr/bin/only256",
+				Fingerprint: []*fspb.Fingerprint{{Method: fspb.Fingerprint_SHA256, Value: "c256"}},
+			}, {
+				Path: "/usr/bin/",
+			}, {
+				Path:        "/tmp/back\\slash",
+				Fingerprint: []*fspb.Fingerprint{{Method: fspb.Fingerprint_SHA512, Value: "d512"}},
+			}, {
+				Path:        "/tmp/carriage\rreturn",
+				Fingerprint: []*fspb.Fingerprint{{Method: fspb.Fingerprint_SHA256, Value: "e256"}},
+			},
+		},
+	}
+
+	testCases := []struct {
+		method fspb.Fingerprint_Method
+		want   string
+	}{
+		{
+			method: fspb.Fingerprint_SHA512,
+			want:   "\\d512  /tmp/back\\\\slash\na512  /usr/bin/a\nb512  /usr/bin/b\n",
+		}, {
+			method: fspb.Fingerprint_SHA256,
+			want:   "\\e256  /tmp/carriage\\rreturn\na256  /usr/bin/a\nb256  /usr/bin/b\nc256  /usr/bin/only256\n",
+		},
+	}
+	for _, tc := range testCases {
+		var sb strings.Builder
+		n, err := WriteManifest(&sb, walk, tc.method)
+		if err != nil {
+			t.Fatalf("WriteManifest(%s) error: %v", tc.method, err)
+		}
+		if got := sb.String(); got != tc.want {
+			t.Errorf("WriteManifest(%s) = %q; want %q", tc.method, got, tc.want)
+		}
+		if want := strings.Count(tc.want, "\n"); n != want {
+			t.Errorf("WriteManifest(%s) = %d files; want %d", tc.method, n, want)
+		}
+	}
+}
+
+func TestWriteManifestSHA512(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"a": "alpha", "b": "beta"})
+	walk := runWalk(t, &fspb.Policy{
+		Version:         1,
+		Include:         []string{dir},
+		MaxHashFileSize: 1048576,
+		HashSha512:      true,
+	})
+
+	var sb strings.Builder
+	n, err := WriteManifest(&sb, walk, fspb.Fingerprint_SHA512)
+	if err != nil {
+		t.Fatalf("WriteManifest() error: %v", err)
+	}
+	want := fmt.Sprintf("%x  %s\n%x  %s\n",
+		sha512.Sum512([]byte("alpha")), filepath.Join(dir, "a"),
+		sha512.Sum512([]byte("beta")), filepath.Join(dir, "b"))
+	if got := sb.String(); got != want || n != 2 {
+		t.Errorf("WriteManifest() = %d, %q; want 2, %q", n, got, want)
+	}
+	for _, f := range walk.File {
+		if !f.Info.IsDir && len(f.Fingerprint) != 2 {
+			t.Errorf("%s fingerprints = %v; want SHA256 and SHA512", f.Path, f.Fingerprint)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// WritePatch writes the metadata changes of the modified files of report to w in
+// the unified diff format, for tools consuming patches. Every file gets a block
+// with a single hunk holding a "-" and a "+" line per changed field, e.g.:
+//
+//	--- /etc/passwd (before)
+//	+++ /etc/passwd (after)
+//	@@ -1,2 +1,2 @@
+//	-mode: 420
+//	+mode: 384
+//	-size: 1024
+//	+size: 2048
+//
+// Added and deleted files are not included. It returns the number of files written.
+func WritePatch(w io.Writer, report *Report) (int, error) {
+	bw := bufio.NewWriter(w)
+	n := 0
+	for _, m := range report.Modified {
+		if len(m.Fields) == 0 {
+			continue
+		}
+		fmt.Fprintf(bw, "--- %s (before)\n", m.Before.Path)
+		fmt.Fprintf(bw, "+++ %s (after)\n", m.After.Path)
+		fmt.Fprintf(bw, "@@ -1,%d +1,%d @@\n", len(m.Fields), len(m.Fields))
+		for _, d := range m.Fields {
+			before, after := d.values()
+			fmt.Fprintf(bw, "-%s: %s\n", d.Field, before)
+			fmt.Fprintf(bw, "+%s: %s\n", d.Field, after)
+		}
+		n++
+	}
+	if err := bw.Flush(); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
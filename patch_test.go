@@ -0,0 +1,67 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+func TestWritePatch(t *testing.T) {
+	before := &fspb.Walk{
+		Id: "before",
+		File: []*fspb.File{
+			{Path: "/etc/passwd", Info: &fspb.FileInfo{Name: "passwd", Size: 1024, Mode: 0644}},
+			{Path: "/etc/hosts", Info: &fspb.FileInfo{Name: "hosts", Size: 10, Mode: 0644}},
+		},
+	}
+	after := &fspb.Walk{
+		Id: "after",
+		File: []*fspb.File{
+			{Path: "/etc/passwd", Info: &fspb.FileInfo{Name: "passwd", Size: 2048, Mode: 0600}},
+			{Path: "/etc/hosts", Info: &fspb.FileInfo{Name: "hosts", Size: 10, Mode: 0644}},
+			{Path: "/etc/new", Info: &fspb.FileInfo{Name: "new", Size: 1, Mode: 0644}},
+		},
+	}
+	r := &Reporter{config: &fspb.ReportConfig{}}
+	report, err := r.Compare(before, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+
+	var sb strings.Builder
+	n, err := WritePatch(&sb, report)
+	if err != nil {
+		t.Fatalf("WritePatch() error: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("WritePatch() = %d; want 1", n)
+	}
+	want := `--- /etc/passwd (before)
++++ /etc/passwd (after)
+@@ -1,2 +1,2 @@
+-mode: 420
++mode: 384
+-size: 1024
++size: 2048
+`
+	if diff := cmp.Diff(want, sb.String()); diff != "" {
+		t.Errorf("WritePatch() output: diff (-want +got):\n%s", diff)
+	}
+}
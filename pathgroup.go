@@ -0,0 +1,126 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+
+	"golang.org/x/exp/slices"
+)
+
+// PathChurn is the number of changed files of a group of paths.
+type PathChurn struct {
+	// Group is the name of the group, see the pathGroups of the report config.
+	Group    string
+	Added    int
+	Deleted  int
+	Modified int
+}
+
+// Total returns the number of changed files of the group.
+func (c PathChurn) Total() int {
+	return c.Added + c.Deleted + c.Modified
+}
+
+// pathGroup is a compiled PathGroup of the report config.
+type pathGroup struct {
+	re    *regexp.Regexp
+	group string
+}
+
+// pathGroups compiles the pathGroups of the report config.
+func (r *Reporter) pathGroups() ([]pathGroup, error) {
+	var groups []pathGroup
+	for _, g := range r.config.GetPathGroups() {
+		re, err := regexp.Compile(g.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pathGroups pattern %q: %v", g.Pattern, err)
+		}
+		groups = append(groups, pathGroup{re, g.Group})
+	}
+	return groups, nil
+}
+
+// groupOf returns the group of path, which is its directory if no group matches.
+func groupOf(groups []pathGroup, path string) string {
+	for _, g := range groups {
+		if m := g.re.FindStringSubmatchIndex(path); m != nil {
+			return string(g.re.ExpandString(nil, g.group, path, m))
+		}
+	}
+	return filepath.Dir(path)
+}
+
+// ChurnByGroup returns the number of added, removed and modified files of the
+// Report per group of the pathGroups of the report config, sorted by the total
+// number of changed files in descending order.
+func (r *Reporter) ChurnByGroup(report *Report) ([]PathChurn, error) {
+	groups, err := r.pathGroups()
+	if err != nil {
+		return nil, err
+	}
+	churn := map[string]*PathChurn{}
+	count := func(path string) *PathChurn {
+		g := groupOf(groups, path)
+		if churn[g] == nil {
+			churn[g] = &PathChurn{Group: g}
+		}
+		return churn[g]
+	}
+	for _, a := range report.Added {
+		count(a.After.Path).Added++
+	}
+	for _, a := range report.Deleted {
+		count(a.Before.Path).Deleted++
+	}
+	for _, a := range report.Modified {
+		count(a.After.Path).Modified++
+	}
+
+	var churns []PathChurn
+	for _, c := range churn {
+		churns = append(churns, *c)
+	}
+	slices.SortFunc(churns, func(a, b PathChurn) bool {
+		if a.Total() != b.Total() {
+			return a.Total() > b.Total()
+		}
+		return a.Group < b.Group
+	})
+	return churns, nil
+}
+
+// printChurnByGroup prints the churn per group if the report config sets pathGroups.
+func (r *Reporter) printChurnByGroup(report *Report) {
+	if len(r.config.GetPathGroups()) == 0 || report.Empty() {
+		return
+	}
+	churns, err := r.ChurnByGroup(report)
+	if err != nil {
+		fmt.Printf("Unable to group changes: %v\n\n", err)
+		return
+	}
+	fmt.Printf("Changes by Group (%d):\n", len(churns))
+	for i, c := range churns {
+		if r.MaxDiffs > 0 && i == r.MaxDiffs {
+			fmt.Printf("... and %d more\n", len(churns)-i)
+			break
+		}
+		fmt.Printf("%s: %d added, %d removed, %d modified\n", c.Group, c.Added, c.Deleted, c.Modified)
+	}
+	fmt.Println()
+}
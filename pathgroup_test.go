@@ -0,0 +1,85 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+func TestChurnByGroup(t *testing.T) {
+	r, err := ReporterFromConfigBytes([]byte(`
+version = 1
+
+[[pathGroups]]
+pattern = "^/app/v[0-9]+/"
+group = "/app/<ver>"
+
+[[pathGroups]]
+pattern = "^/home/([^/]+)/"
+group = "/home/$1"
+`))
+	if err != nil {
+		t.Fatalf("ReporterFromConfigBytes() error: %v", err)
+	}
+	before := &fspb.Walk{
+		Id: "1",
+		File: []*fspb.File{
+			{Path: "/app/v1/bin/tool", Info: &fspb.FileInfo{Size: 1}},
+			{Path: "/app/v1/lib/old.so", Info: &fspb.FileInfo{Size: 1}},
+			{Path: "/home/alice/notes", Info: &fspb.FileInfo{Size: 1}},
+			{Path: "/etc/hosts", Info: &fspb.FileInfo{Size: 1}},
+		},
+	}
+	after := &fspb.Walk{
+		Id: "2",
+		File: []*fspb.File{
+			{Path: "/app/v1/bin/tool", Info: &fspb.FileInfo{Size: 2}},
+			{Path: "/app/v2/bin/tool", Info: &fspb.FileInfo{Size: 2}},
+			{Path: "/home/alice/notes", Info: &fspb.FileInfo{Size: 2}},
+			{Path: "/etc/hosts", Info: &fspb.FileInfo{Size: 2}},
+		},
+	}
+	report, err := r.Compare(before, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	got, err := r.ChurnByGroup(report)
+	if err != nil {
+		t.Fatalf("ChurnByGroup() error: %v", err)
+	}
+	want := []PathChurn{
+		{Group: "/app/<ver>", Added: 1, Deleted: 1, Modified: 1},
+		{Group: "/etc", Modified: 1},
+		{Group: "/home/alice", Modified: 1},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ChurnByGroup(): diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestChurnByGroupInvalidPattern(t *testing.T) {
+	_, err := ReporterFromConfigBytes([]byte(`
+[[pathGroups]]
+pattern = "^/app/(v"
+group = "/app"
+`))
+	if err == nil {
+		t.Error("ReporterFromConfigBytes() with an invalid pathGroups pattern succeeded")
+	}
+}
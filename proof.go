@@ -0,0 +1,162 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"google.golang.org/protobuf/proto"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+// Proof proves the metadata and content of a single file of a Walk against the
+// tree digest of the topmost directory above it, without disclosing the other
+// files: only their entries in the digests of the directories along the path
+// are included, which hold their name, mode and fingerprint but not their path.
+type Proof struct {
+	// File is the proven file, reduced to the fields its tree digest entry covers.
+	File *fspb.File
+	// Levels hold one level per directory from the parent of File up to the root.
+	Levels []ProofLevel
+}
+
+// ProofLevel is a directory along the path of a proven file.
+type ProofLevel struct {
+	// Path, Name and Mode are those of the directory.
+	Path string
+	Name string
+	Mode uint32
+	// Siblings are the tree digest entries of all children of the directory
+	// other than the one on the path to the proven file.
+	Siblings []string
+}
+
+// ExportProof returns the Proof for the file at path of walk. The walk needs to
+// be taken with computeTreeDigests, the Proof reaches up to the topmost directory
+// of the walk above path. Use VerifyProof to check it against the path and tree
+// digest of that directory.
+func ExportProof(walk *fspb.Walk, path string) (*Proof, error) {
+	files := map[string]*fspb.File{}
+	children := map[string][]*fspb.File{}
+	for _, f := range walk.File {
+		if f.Info == nil {
+			continue
+		}
+		p := filepath.Clean(f.Path)
+		files[p] = f
+		if parent := filepath.Dir(p); parent != p {
+			children[parent] = append(children[parent], f)
+		}
+	}
+	cur := filepath.Clean(path)
+	f, ok := files[cur]
+	if !ok {
+		return nil, fmt.Errorf("%q is not part of the walk", path)
+	}
+
+	proof := &Proof{File: proofFile(f)}
+	for {
+		parent := filepath.Dir(cur)
+		d, ok := files[parent]
+		if parent == cur || !ok {
+			break
+		}
+		if d.TreeDigest == nil {
+			return nil, fmt.Errorf("directory %q has no tree digest, the walk needs to be taken with computeTreeDigests", d.Path)
+		}
+		level := ProofLevel{Path: d.Path, Name: d.Info.Name, Mode: d.Info.Mode}
+		for _, c := range children[parent] {
+			if filepath.Clean(c.Path) != cur {
+				level.Siblings = append(level.Siblings, treeDigestEntry(c))
+			}
+		}
+		proof.Levels = append(proof.Levels, level)
+		cur = parent
+	}
+	if len(proof.Levels) == 0 {
+		return nil, fmt.Errorf("the walk has no directory above %q", path)
+	}
+
+	// A walk modified after computing its digests would yield a useless proof.
+	if err := VerifyProof(proof, files[cur].Path, files[cur].TreeDigest); err != nil {
+		return nil, fmt.Errorf("the tree digests of the walk are inconsistent: %v", err)
+	}
+	return proof, nil
+}
+
+// proofFile returns a copy of f with only the fields covered by its tree digest entry.
+// Like in treeDigestEntry, the size is only covered without a tree digest or
+// fingerprint, and whether f is a directory only by its mode.
+func proofFile(f *fspb.File) *fspb.File {
+	pf := &fspb.File{
+		Path: f.Path,
+		Info: &fspb.FileInfo{
+			Name: f.Info.Name,
+			Mode: f.Info.Mode,
+		},
+	}
+	switch {
+	case f.TreeDigest != nil:
+		pf.TreeDigest = proto.Clone(f.TreeDigest).(*fspb.Fingerprint)
+	case len(f.Fingerprint) > 0:
+		pf.Fingerprint = []*fspb.Fingerprint{proto.Clone(f.Fingerprint[0]).(*fspb.Fingerprint)}
+	default:
+		pf.Info.Size = f.Info.Size
+	}
+	return pf
+}
+
+// VerifyProof checks that proof reconstructs rootDigest, the trusted tree digest of
+// the topmost directory of the proof at rootPath, e.g. both taken from a signed Walk.
+// It also checks that the names along the proof match the path of the proven file.
+func VerifyProof(proof *Proof, rootPath string, rootDigest *fspb.Fingerprint) error {
+	if proof.File == nil || proof.File.Info == nil || len(proof.Levels) == 0 {
+		return fmt.Errorf("incomplete proof")
+	}
+	if rootDigest == nil || rootDigest.Method != fspb.Fingerprint_SHA256 {
+		return fmt.Errorf("unsupported root digest %v", rootDigest)
+	}
+	cur := filepath.Clean(proof.File.Path)
+	if name := filepath.Base(cur); proof.File.Info.Name != name {
+		return fmt.Errorf("name %q of the proven file doesn't match its path %q", proof.File.Info.Name, proof.File.Path)
+	}
+
+	entry := treeDigestEntry(proof.File)
+	var digest *fspb.Fingerprint
+	for i, level := range proof.Levels {
+		cur = filepath.Dir(cur)
+		if filepath.Clean(level.Path) != cur {
+			return fmt.Errorf("level %d: directory %q isn't the parent %q", i, level.Path, cur)
+		}
+		digest = treeDigestOfEntries(append([]string{entry}, level.Siblings...))
+		// The name of the root doesn't affect the digest, but all others do.
+		if i < len(proof.Levels)-1 && level.Name != filepath.Base(cur) {
+			return fmt.Errorf("level %d: name %q of directory doesn't match its path %q", i, level.Name, level.Path)
+		}
+		entry = treeDigestEntry(&fspb.File{
+			Info:       &fspb.FileInfo{Name: level.Name, Mode: level.Mode},
+			TreeDigest: digest,
+		})
+	}
+	if cur != filepath.Clean(rootPath) {
+		return fmt.Errorf("proof of %q reaches up to %q; want %q", proof.File.Path, cur, rootPath)
+	}
+	if digest.Value != rootDigest.Value {
+		return fmt.Errorf("proof of %q results in digest %s; want %s", proof.File.Path, digest.Value, rootDigest.Value)
+	}
+	return nil
+}
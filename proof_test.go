@@ -0,0 +1,103 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+func TestExportVerifyProof(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"top":          "top",
+		"a/other":      "other",
+		"a/b/secret":   "secret",
+		"a/b/neighbor": "neighbor",
+		"c/far":        "far",
+	})
+	walk := runWalk(t, &fspb.Policy{
+		Include:            []string{root},
+		MaxHashFileSize:    1024,
+		ComputeTreeDigests: true,
+	})
+	var rootDigest *fspb.Fingerprint
+	for _, f := range walk.File {
+		if f.Path == root {
+			rootDigest = f.TreeDigest
+		}
+	}
+	if rootDigest == nil {
+		t.Fatal("walk has no tree digest for its root")
+	}
+
+	path := filepath.Join(root, "a", "b", "secret")
+	proof, err := ExportProof(walk, path)
+	if err != nil {
+		t.Fatalf("ExportProof() error: %v", err)
+	}
+	if len(proof.Levels) != 3 {
+		t.Errorf("ExportProof() has %d levels; want 3", len(proof.Levels))
+	}
+	if proof.File.Stat != nil || proof.File.Info.Size != 0 || len(proof.File.Fingerprint) != 1 {
+		t.Errorf("ExportProof() file = %v; want only the fields of its digest entry", proof.File)
+	}
+	if err := VerifyProof(proof, root, rootDigest); err != nil {
+		t.Errorf("VerifyProof() error: %v", err)
+	}
+	if err := VerifyProof(proof, filepath.Join(root, "a"), rootDigest); err == nil {
+		t.Error("VerifyProof() against another root path: no error")
+	}
+
+	// Any change to the proof or the root digest needs to be detected.
+	for _, tc := range []struct {
+		desc   string
+		tamper func(p *Proof, d *fspb.Fingerprint)
+	}{
+		{"fingerprint", func(p *Proof, _ *fspb.Fingerprint) { p.File.Fingerprint[0].Value = "00" }},
+		{"mode", func(p *Proof, _ *fspb.Fingerprint) { p.File.Info.Mode |= 0002 }},
+		{"path", func(p *Proof, _ *fspb.Fingerprint) {
+			p.File.Path = filepath.Join(root, "a", "b", "neighbor")
+			p.File.Info.Name = "neighbor"
+		}},
+		{"sibling", func(p *Proof, _ *fspb.Fingerprint) { p.Levels[0].Siblings = p.Levels[0].Siblings[1:] }},
+		{"directory name", func(p *Proof, _ *fspb.Fingerprint) { p.Levels[1].Name = "z" }},
+		{"missing level", func(p *Proof, _ *fspb.Fingerprint) { p.Levels = p.Levels[:2] }},
+		{"root digest", func(_ *Proof, d *fspb.Fingerprint) { d.Value = "00" }},
+	} {
+		p := &Proof{File: proto.Clone(proof.File).(*fspb.File)}
+		for _, l := range proof.Levels {
+			l.Siblings = append([]string{}, l.Siblings...)
+			p.Levels = append(p.Levels, l)
+		}
+		d := proto.Clone(rootDigest).(*fspb.Fingerprint)
+		tc.tamper(p, d)
+		if err := VerifyProof(p, root, d); err == nil {
+			t.Errorf("VerifyProof() with tampered %s: no error", tc.desc)
+		}
+	}
+
+	if _, err := ExportProof(walk, filepath.Join(root, "missing")); err == nil {
+		t.Error("ExportProof() of missing file: no error")
+	}
+	noDigests := runWalk(t, &fspb.Policy{Include: []string{root}, MaxHashFileSize: 1024})
+	if _, err := ExportProof(noDigests, path); err == nil {
+		t.Error("ExportProof() of walk without tree digests: no error")
+	}
+}
@@ -85,7 +85,7 @@ func (x Notification_Severity) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use Notification_Severity.Descriptor instead.
 func (Notification_Severity) EnumDescriptor() ([]byte, []int) {
-	return file_proto_fswalker_fswalker_proto_rawDescGZIP(), []int{5, 0}
+	return file_proto_fswalker_fswalker_proto_rawDescGZIP(), []int{8, 0}
 }
 
 type Fingerprint_Method int32
@@ -93,6 +93,11 @@ type Fingerprint_Method int32
 const (
 	Fingerprint_UNKNOWN Fingerprint_Method = 0
 	Fingerprint_SHA256  Fingerprint_Method = 1
+	// The fs-verity digests are measured by the kernel instead of reading
+	// the file content.
+	Fingerprint_FSVERITY_SHA256 Fingerprint_Method = 2
+	Fingerprint_FSVERITY_SHA512 Fingerprint_Method = 3
+	Fingerprint_SHA512          Fingerprint_Method = 4
 )
 
 // Enum value maps for Fingerprint_Method.
@@ -100,10 +105,16 @@ var (
 	Fingerprint_Method_name = map[int32]string{
 		0: "UNKNOWN",
 		1: "SHA256",
+		2: "FSVERITY_SHA256",
+		3: "FSVERITY_SHA512",
+		4: "SHA512",
 	}
 	Fingerprint_Method_value = map[string]int32{
-		"UNKNOWN": 0,
-		"SHA256":  1,
+		"UNKNOWN":         0,
+		"SHA256":          1,
+		"FSVERITY_SHA256": 2,
+		"FSVERITY_SHA512": 3,
+		"SHA512":          4,
 	}
 )
 
@@ -131,7 +142,7 @@ func (x Fingerprint_Method) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use Fingerprint_Method.Descriptor instead.
 func (Fingerprint_Method) EnumDescriptor() ([]byte, []int) {
-	return file_proto_fswalker_fswalker_proto_rawDescGZIP(), []int{8, 0}
+	return file_proto_fswalker_fswalker_proto_rawDescGZIP(), []int{11, 0}
 }
 
 // Reviews is a collection of "known good" states, one per host.
@@ -250,6 +261,122 @@ func (x *Review) GetFingerprint() *Fingerprint {
 	return nil
 }
 
+// AcceptedDiffs are the changes an operator reviewed and accepted, so they are
+// not reported again as long as they stay the same.
+type AcceptedDiffs struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Diff []*AcceptedDiff `protobuf:"bytes,1,rep,name=diff,proto3" json:"diff,omitempty"`
+}
+
+func (x *AcceptedDiffs) Reset() {
+	*x = AcceptedDiffs{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_fswalker_fswalker_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AcceptedDiffs) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AcceptedDiffs) ProtoMessage() {}
+
+func (x *AcceptedDiffs) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_fswalker_fswalker_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AcceptedDiffs.ProtoReflect.Descriptor instead.
+func (*AcceptedDiffs) Descriptor() ([]byte, []int) {
+	return file_proto_fswalker_fswalker_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AcceptedDiffs) GetDiff() []*AcceptedDiff {
+	if x != nil {
+		return x.Diff
+	}
+	return nil
+}
+
+type AcceptedDiff struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// path of the changed file.
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// kind of the change, i.e. "added", "removed" or "modified".
+	Kind string `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	// hash identifying the exact change, e.g. over the before and after values
+	// of all changed fields of a modified file.
+	Hash string `protobuf:"bytes,3,opt,name=hash,proto3" json:"hash,omitempty"`
+}
+
+func (x *AcceptedDiff) Reset() {
+	*x = AcceptedDiff{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_fswalker_fswalker_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AcceptedDiff) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AcceptedDiff) ProtoMessage() {}
+
+func (x *AcceptedDiff) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_fswalker_fswalker_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AcceptedDiff.ProtoReflect.Descriptor instead.
+func (*AcceptedDiff) Descriptor() ([]byte, []int) {
+	return file_proto_fswalker_fswalker_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AcceptedDiff) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *AcceptedDiff) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *AcceptedDiff) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
 type ReportConfig struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -262,12 +389,72 @@ type ReportConfig struct {
 	// client policy so more things can be recorded (but ignored in the default
 	// report).
 	Exclude []string `protobuf:"bytes,2,rep,name=exclude,proto3" json:"exclude,omitempty"`
+	// outputFileMode is the octal file mode (e.g. "0600") of files written by
+	// the reporter, like the reviews file. Defaults to "0644".
+	OutputFileMode string `protobuf:"bytes,3,opt,name=outputFileMode,proto3" json:"outputFileMode,omitempty"`
+	// mtimeGranularity is the precision at which modification times are
+	// compared, e.g. "1s". Differences smaller than it are not reported, which
+	// avoids noise when comparing walks of file systems storing mtime at
+	// different granularities. Change and birth times are compared at the same
+	// precision. Defaults to comparing exactly.
+	MtimeGranularity string `protobuf:"bytes,4,opt,name=mtimeGranularity,proto3" json:"mtimeGranularity,omitempty"`
+	// flagWorldWritable reports files becoming writable by others as a high
+	// severity change ("perm-other: -w => +w").
+	FlagWorldWritable bool `protobuf:"varint,5,opt,name=flagWorldWritable,proto3" json:"flagWorldWritable,omitempty"`
+	// reportWorldWritable lists all files which are writable by others in the
+	// later walk, whether they changed or not.
+	ReportWorldWritable bool `protobuf:"varint,6,opt,name=reportWorldWritable,proto3" json:"reportWorldWritable,omitempty"`
+	// modifiedOnly limits the report to files present in both walks, i.e. added
+	// and removed files are counted but not listed. This is useful for paths
+	// where files come and go all the time, like logs.
+	ModifiedOnly bool `protobuf:"varint,7,opt,name=modifiedOnly,proto3" json:"modifiedOnly,omitempty"`
+	// reportInodeChanges reports files whose inode number changed. This means a
+	// file was replaced (e.g. by an atomic rename) rather than edited in place,
+	// even if its content is the same.
+	ReportInodeChanges bool `protobuf:"varint,8,opt,name=reportInodeChanges,proto3" json:"reportInodeChanges,omitempty"`
+	// criticalPaths is a list of paths which must never change, e.g.
+	// "/etc/sudoers". If any of them is modified or removed, the report is
+	// marked as having a critical change. Same format as exclude.
+	CriticalPaths []string `protobuf:"bytes,9,rep,name=criticalPaths,proto3" json:"criticalPaths,omitempty"`
+	// metadataOnly ignores changes of file content, i.e. of fingerprints and
+	// tree digests, and only reports changes of file metadata like permissions,
+	// ownership and timestamps.
+	MetadataOnly bool `protobuf:"varint,10,opt,name=metadataOnly,proto3" json:"metadataOnly,omitempty"`
+	// reportPermissionChanges additionally lists the modified files of which
+	// only permissions or ownership changed, to tell configuration drift apart
+	// from changes of content. They are counted either way.
+	ReportPermissionChanges bool `protobuf:"varint,11,opt,name=reportPermissionChanges,proto3" json:"reportPermissionChanges,omitempty"`
+	// suppressRecurringNotifications only prints the notifications of the after
+	// walk which the before walk didn't record as well, to focus on newly
+	// appearing problems instead of recurring ones like permanently unreadable
+	// paths. The notifications of the before walk are not printed then.
+	SuppressRecurringNotifications bool `protobuf:"varint,12,opt,name=suppressRecurringNotifications,proto3" json:"suppressRecurringNotifications,omitempty"`
+	// displayTimezone is the IANA name of the time zone timestamps are printed
+	// in, e.g. "America/New_York". Defaults to UTC, which is also used with a
+	// warning if the zone is unknown.
+	DisplayTimezone string `protobuf:"bytes,13,opt,name=displayTimezone,proto3" json:"displayTimezone,omitempty"`
+	// flagPermissionLoosening reports files whose permissions grant more than
+	// before, e.g. "0600 => 0644" or a newly set setuid bit, as a high severity
+	// change ("perm-loosened"). Tightened permissions remain a normal mode change.
+	FlagPermissionLoosening bool `protobuf:"varint,14,opt,name=flagPermissionLoosening,proto3" json:"flagPermissionLoosening,omitempty"`
+	// flagEntropyIncrease reports files whose content entropy grew by at least
+	// this many bits per byte as a high severity change ("entropy-increase"),
+	// e.g. a text file which got encrypted in place. Only files with an entropy
+	// recorded in both Walks are compared, see recordEntropy of the policy.
+	// 0 disables it.
+	FlagEntropyIncrease float64 `protobuf:"fixed64,15,opt,name=flagEntropyIncrease,proto3" json:"flagEntropyIncrease,omitempty"`
+	// pathGroups groups the changed files by path to summarize the churn per
+	// group, e.g. of all versions of a versioned directory together. A file
+	// belongs to the first group whose pattern matches its path, files matching
+	// none are grouped by their directory. Only the summary is affected, not the
+	// comparison itself.
+	PathGroups []*PathGroup `protobuf:"bytes,16,rep,name=pathGroups,proto3" json:"pathGroups,omitempty"`
 }
 
 func (x *ReportConfig) Reset() {
 	*x = ReportConfig{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_fswalker_fswalker_proto_msgTypes[2]
+		mi := &file_proto_fswalker_fswalker_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -280,7 +467,7 @@ func (x *ReportConfig) String() string {
 func (*ReportConfig) ProtoMessage() {}
 
 func (x *ReportConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_fswalker_fswalker_proto_msgTypes[2]
+	mi := &file_proto_fswalker_fswalker_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -293,7 +480,7 @@ func (x *ReportConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ReportConfig.ProtoReflect.Descriptor instead.
 func (*ReportConfig) Descriptor() ([]byte, []int) {
-	return file_proto_fswalker_fswalker_proto_rawDescGZIP(), []int{2}
+	return file_proto_fswalker_fswalker_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *ReportConfig) GetVersion() uint32 {
@@ -310,6 +497,164 @@ func (x *ReportConfig) GetExclude() []string {
 	return nil
 }
 
+func (x *ReportConfig) GetOutputFileMode() string {
+	if x != nil {
+		return x.OutputFileMode
+	}
+	return ""
+}
+
+func (x *ReportConfig) GetMtimeGranularity() string {
+	if x != nil {
+		return x.MtimeGranularity
+	}
+	return ""
+}
+
+func (x *ReportConfig) GetFlagWorldWritable() bool {
+	if x != nil {
+		return x.FlagWorldWritable
+	}
+	return false
+}
+
+func (x *ReportConfig) GetReportWorldWritable() bool {
+	if x != nil {
+		return x.ReportWorldWritable
+	}
+	return false
+}
+
+func (x *ReportConfig) GetModifiedOnly() bool {
+	if x != nil {
+		return x.ModifiedOnly
+	}
+	return false
+}
+
+func (x *ReportConfig) GetReportInodeChanges() bool {
+	if x != nil {
+		return x.ReportInodeChanges
+	}
+	return false
+}
+
+func (x *ReportConfig) GetCriticalPaths() []string {
+	if x != nil {
+		return x.CriticalPaths
+	}
+	return nil
+}
+
+func (x *ReportConfig) GetMetadataOnly() bool {
+	if x != nil {
+		return x.MetadataOnly
+	}
+	return false
+}
+
+func (x *ReportConfig) GetReportPermissionChanges() bool {
+	if x != nil {
+		return x.ReportPermissionChanges
+	}
+	return false
+}
+
+func (x *ReportConfig) GetSuppressRecurringNotifications() bool {
+	if x != nil {
+		return x.SuppressRecurringNotifications
+	}
+	return false
+}
+
+func (x *ReportConfig) GetDisplayTimezone() string {
+	if x != nil {
+		return x.DisplayTimezone
+	}
+	return ""
+}
+
+func (x *ReportConfig) GetFlagPermissionLoosening() bool {
+	if x != nil {
+		return x.FlagPermissionLoosening
+	}
+	return false
+}
+
+func (x *ReportConfig) GetFlagEntropyIncrease() float64 {
+	if x != nil {
+		return x.FlagEntropyIncrease
+	}
+	return 0
+}
+
+func (x *ReportConfig) GetPathGroups() []*PathGroup {
+	if x != nil {
+		return x.PathGroups
+	}
+	return nil
+}
+
+// PathGroup names the group of the paths matching a pattern, e.g. pattern
+// "^/app/v[0-9]+/" and group "/app/<ver>".
+type PathGroup struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// pattern is a regular expression (RE2 syntax) matched against the path.
+	Pattern string `protobuf:"bytes,1,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	// group is the name of the group. $1, ${name} etc. are replaced by the
+	// submatches of pattern, see regexp.Regexp.Expand.
+	Group string `protobuf:"bytes,2,opt,name=group,proto3" json:"group,omitempty"`
+}
+
+func (x *PathGroup) Reset() {
+	*x = PathGroup{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_fswalker_fswalker_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PathGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PathGroup) ProtoMessage() {}
+
+func (x *PathGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_fswalker_fswalker_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PathGroup.ProtoReflect.Descriptor instead.
+func (*PathGroup) Descriptor() ([]byte, []int) {
+	return file_proto_fswalker_fswalker_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PathGroup) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *PathGroup) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
 type Policy struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -324,8 +669,13 @@ type Policy struct {
 	// exclude is a list of paths which will be excluded from being
 	// walked. Note that if a path ends in a slash it will be treated as a directory,
 	// otherwise as a file.
+	// Entries may be glob patterns (e.g. "/home/*/.cache/" or "/var/lib/**/*.db")
+	// where "**" matches any number of path elements. Patterns without a slash
+	// are matched against the base name only (e.g. "*.iso").
 	Exclude []string `protobuf:"bytes,3,rep,name=exclude,proto3" json:"exclude,omitempty"`
 	// excludeHashing is a list of paths that will be excluded from being hashed.
+	// It supports the same syntax as exclude. Files are not hashed if they are
+	// either excluded here or larger than maxHashFileSize.
 	ExcludeHashing []string `protobuf:"bytes,4,rep,name=excludeHashing,proto3" json:"excludeHashing,omitempty"`
 	// maxHashFileSize controls what files will be hashed.
 	MaxHashFileSize uint64 `protobuf:"varint,30,opt,name=maxHashFileSize,proto3" json:"maxHashFileSize,omitempty"`
@@ -341,92 +691,444 @@ type Policy struct {
 	// walk into an included directory.
 	// Defaults to no restriction on depth (i.e. go all the way).
 	MaxDirectoryDepth uint32 `protobuf:"varint,33,opt,name=maxDirectoryDepth,proto3" json:"maxDirectoryDepth,omitempty"`
+	// maxHashFileSizeByExtension overrides maxHashFileSize for files with the
+	// given extension (including the leading dot, e.g. ".log").
+	// Files with extensions not listed fall back to maxHashFileSize.
+	MaxHashFileSizeByExtension map[string]uint64 `protobuf:"bytes,34,rep,name=maxHashFileSizeByExtension,proto3" json:"maxHashFileSizeByExtension,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	// computeTreeDigests controls whether a digest over the names, modes and
+	// fingerprints of all children is computed for every walked directory.
+	// Since child directories contribute their own digest, a change anywhere in
+	// a subtree changes the digests of all its ancestors.
+	ComputeTreeDigests bool `protobuf:"varint,35,opt,name=computeTreeDigests,proto3" json:"computeTreeDigests,omitempty"`
+	// relativeTo, if set, is a directory which all file paths recorded in the
+	// walk are made relative to (e.g. "/mnt/image"). This allows comparing the
+	// same tree mounted at different locations. Include and exclude entries
+	// still need to be absolute and files outside of it keep their absolute path.
+	RelativeTo string `protobuf:"bytes,36,opt,name=relativeTo,proto3" json:"relativeTo,omitempty"`
+	// walkCrossVirtualDevice controls whether files on overlay and FUSE
+	// filesystems are walked even if walkCrossDevice is false. Container runtimes
+	// commonly use these with synthetic device numbers, while real (bind) mounts
+	// are still skipped. The filesystem types are read from /proc/self/mountinfo,
+	// so this is only supported on Linux.
+	WalkCrossVirtualDevice bool `protobuf:"varint,37,opt,name=walkCrossVirtualDevice,proto3" json:"walkCrossVirtualDevice,omitempty"`
+	// collectBirthTime controls whether the birth (creation) time of files is
+	// recorded. It is only available on some platforms and filesystems (statx
+	// on Linux, stat on macOS) and left unset otherwise.
+	CollectBirthTime bool `protobuf:"varint,38,opt,name=collectBirthTime,proto3" json:"collectBirthTime,omitempty"`
+	// maxWalkDuration limits how long a walk may take, e.g. "30m" or "2h".
+	// Once exceeded, no new files are discovered and the walk finishes with the
+	// files found so far, recording a warning that it is incomplete.
+	// Defaults to no limit.
+	MaxWalkDuration string `protobuf:"bytes,39,opt,name=maxWalkDuration,proto3" json:"maxWalkDuration,omitempty"`
+	// recordBindMounts controls whether directories which are bind mounts are
+	// annotated with the path they are mounted from. The mount table is read from
+	// /proc/self/mountinfo, so this is only supported on Linux.
+	RecordBindMounts bool `protobuf:"varint,40,opt,name=recordBindMounts,proto3" json:"recordBindMounts,omitempty"`
+	// outputFileMode is the octal file mode (e.g. "0400") the walk file is
+	// written with. As walks reveal the layout of the file system, restricting
+	// it to the owner might be desired. Defaults to "0444".
+	OutputFileMode string `protobuf:"bytes,41,opt,name=outputFileMode,proto3" json:"outputFileMode,omitempty"`
+	// skipHidden controls whether files and directories whose name starts with a
+	// dot are skipped. Explicitly included paths are always walked.
+	SkipHidden bool `protobuf:"varint,42,opt,name=skipHidden,proto3" json:"skipHidden,omitempty"`
+	// collectAttributes controls whether the statx(2) attributes of files are
+	// recorded and, for files with fs-verity enabled, the kernel-measured
+	// fs-verity digest is added as a fingerprint. Only supported on Linux.
+	CollectAttributes bool `protobuf:"varint,43,opt,name=collectAttributes,proto3" json:"collectAttributes,omitempty"`
+	// excludeContents is a list of directories which are recorded themselves but
+	// whose contents are not walked. Same format as exclude.
+	ExcludeContents []string `protobuf:"bytes,44,rep,name=excludeContents,proto3" json:"excludeContents,omitempty"`
+	// hashChangedOnly controls whether files which didn't change compared to the
+	// baseline Walk given to the walker (same size, mtime and ctime) get the
+	// fingerprints of the baseline instead of being hashed again. Files outside
+	// of the includes and excludes of the baseline's policy, which a comparison
+	// with it doesn't report, aren't hashed at all.
+	HashChangedOnly bool `protobuf:"varint,45,opt,name=hashChangedOnly,proto3" json:"hashChangedOnly,omitempty"`
+	// honorIgnoreFiles controls whether directories may contain a
+	// ".fswalkerignore" file listing exclude patterns, one per line, for their
+	// subtree. Patterns have the same format as exclude but are relative to the
+	// directory of the ignore file. Empty lines and lines starting with "#" are
+	// skipped.
+	HonorIgnoreFiles bool `protobuf:"varint,46,opt,name=honorIgnoreFiles,proto3" json:"honorIgnoreFiles,omitempty"`
+	// recordEntryCount controls whether the number of entries of every walked
+	// directory is recorded. A changed count is a quick signal that files were
+	// added to or removed from a directory.
+	RecordEntryCount bool `protobuf:"varint,47,opt,name=recordEntryCount,proto3" json:"recordEntryCount,omitempty"`
+	// sortOutput controls whether the files of the walk are sorted by path and
+	// its notifications by severity, path and message. Otherwise their order
+	// depends on the concurrent processing, which makes walks of unchanged
+	// files differ.
+	SortOutput bool `protobuf:"varint,48,opt,name=sortOutput,proto3" json:"sortOutput,omitempty"`
+	// maxOpenFiles limits the number of files opened concurrently, e.g. for
+	// hashing or reading attributes, independent of the number of workers. If unset, it defaults to half of
+	// the soft limit of open file descriptors of the process.
+	MaxOpenFiles uint32 `protobuf:"varint,49,opt,name=maxOpenFiles,proto3" json:"maxOpenFiles,omitempty"`
+	// minDirectoryDepth controls how many levels into an included directory
+	// files need to be to be recorded, e.g. 1 skips the included directory
+	// itself and 2 also its direct entries. Shallower directories are still
+	// walked into. Together with maxDirectoryDepth it defines a depth band.
+	// Defaults to recording files at all depths.
+	MinDirectoryDepth uint32 `protobuf:"varint,50,opt,name=minDirectoryDepth,proto3" json:"minDirectoryDepth,omitempty"`
+	// hashBufferSize is the size in bytes of the buffer every worker reads
+	// files into for hashing. Larger buffers, e.g. 1MB, need fewer read calls
+	// on big files on fast storage. Defaults to the 32KB buffer of io.Copy and
+	// must not exceed 64MB.
+	HashBufferSize uint32 `protobuf:"varint,51,opt,name=hashBufferSize,proto3" json:"hashBufferSize,omitempty"`
+	// excludeHashingFilesystems is a list of filesystem types (e.g. "nfs",
+	// "cifs") whose files are recorded without being hashed, as hashing them is
+	// slow over the network. "fuse" also matches all FUSE subtypes like
+	// "fuse.sshfs" and "nfs" all versions like "nfs4". The filesystem of a file is looked up in
+	// /proc/self/mountinfo, so this is only supported on Linux.
+	ExcludeHashingFilesystems []string `protobuf:"bytes,52,rep,name=excludeHashingFilesystems,proto3" json:"excludeHashingFilesystems,omitempty"`
+	// mtimeReference is an RFC 3339 timestamp, e.g. "2018-01-01T00:00:00Z".
+	// If set, modification times are recorded relative to it, as if it was
+	// the Unix epoch, so trees built at different times but with the same
+	// relative timestamps compare equal.
+	MtimeReference string `protobuf:"bytes,53,opt,name=mtimeReference,proto3" json:"mtimeReference,omitempty"`
+	// zeroMtime controls whether modification times are recorded as the Unix
+	// epoch, for comparisons focused on content. It takes precedence over
+	// mtimeReference.
+	ZeroMtime bool `protobuf:"varint,54,opt,name=zeroMtime,proto3" json:"zeroMtime,omitempty"`
+	// hashBlockDevices controls whether the content of block devices is hashed,
+	// e.g. to detect tampering with firmware or partitions of appliances. This
+	// usually requires root and reading whole disks takes long, see
+	// maxBlockDeviceHashSize. Excludes of excludeHashing still apply.
+	HashBlockDevices bool `protobuf:"varint,55,opt,name=hashBlockDevices,proto3" json:"hashBlockDevices,omitempty"`
+	// maxBlockDeviceHashSize limits hashing block devices to their first bytes,
+	// e.g. 1048576 to cover the partition table and boot loader. Defaults to
+	// hashing the whole device. Must not exceed 2^63-1.
+	MaxBlockDeviceHashSize uint64 `protobuf:"varint,56,opt,name=maxBlockDeviceHashSize,proto3" json:"maxBlockDeviceHashSize,omitempty"`
+	// isolateIncludes controls whether the includes are walked concurrently,
+	// so a slow include, e.g. on a network mount, doesn't hold up discovering
+	// the files of the others. By default the includes are walked one after
+	// another. Either way, all includes share a single pool of workers.
+	IsolateIncludes bool `protobuf:"varint,57,opt,name=isolateIncludes,proto3" json:"isolateIncludes,omitempty"`
+	// computeDirectorySizes controls whether the aggregate size of every walked
+	// directory is computed, i.e. the total size of all files recorded below
+	// it, so the reporter can rank directories by how much they grew or shrank.
+	ComputeDirectorySizes bool `protobuf:"varint,58,opt,name=computeDirectorySizes,proto3" json:"computeDirectorySizes,omitempty"`
+	// recordEntropy controls whether the Shannon entropy of the content of every
+	// hashed file is recorded. It is computed while hashing, reading the file
+	// only once, but costs some additional CPU time.
+	RecordEntropy bool `protobuf:"varint,59,opt,name=recordEntropy,proto3" json:"recordEntropy,omitempty"`
+	// collectDataStreams controls whether the named data streams of regular
+	// files besides their content are recorded with their fingerprints, which
+	// are easily overlooked places to hide data in. Streams larger than the
+	// maxHashFileSize of their file are recorded with their size only. Only
+	// resource forks on macOS are supported, NTFS alternate data streams are not
+	// as the walker doesn't support Windows.
+	CollectDataStreams bool `protobuf:"varint,60,opt,name=collectDataStreams,proto3" json:"collectDataStreams,omitempty"`
+	// statFields lists the fields of the FileStat recorded for every file, e.g.
+	// ["uid", "gid", "mtime"], to shrink walks to what is actually compared.
+	// The reporter ignores fields omitted by either compared walk. Defaults to
+	// all fields. btime and attributes additionally need to be
+	// requested by collectBirthTime and collectAttributes. hashChangedOnly
+	// needs ctime to tell unchanged files apart.
+	StatFields []string `protobuf:"bytes,61,rep,name=statFields,proto3" json:"statFields,omitempty"`
+	// hashSha512 controls whether hashed files are additionally fingerprinted
+	// with SHA-512, e.g. to write manifests for "sha512sum -c" with the
+	// reporter. Both sums are built while reading the file once.
+	HashSha512 bool `protobuf:"varint,62,opt,name=hashSha512,proto3" json:"hashSha512,omitempty"`
+	// queueDepth is the number of discovered files queued for the workers. A
+	// deeper queue keeps the directory traversal from stalling while workers
+	// hash slow files, a shallower one saves memory. Defaults to 64.
+	QueueDepth uint32 `protobuf:"varint,63,opt,name=queueDepth,proto3" json:"queueDepth,omitempty"`
+}
+
+func (x *Policy) Reset() {
+	*x = Policy{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_fswalker_fswalker_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Policy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Policy) ProtoMessage() {}
+
+func (x *Policy) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_fswalker_fswalker_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Policy.ProtoReflect.Descriptor instead.
+func (*Policy) Descriptor() ([]byte, []int) {
+	return file_proto_fswalker_fswalker_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Policy) GetVersion() uint32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Policy) GetInclude() []string {
+	if x != nil {
+		return x.Include
+	}
+	return nil
+}
+
+func (x *Policy) GetExclude() []string {
+	if x != nil {
+		return x.Exclude
+	}
+	return nil
+}
+
+func (x *Policy) GetExcludeHashing() []string {
+	if x != nil {
+		return x.ExcludeHashing
+	}
+	return nil
+}
+
+func (x *Policy) GetMaxHashFileSize() uint64 {
+	if x != nil {
+		return x.MaxHashFileSize
+	}
+	return 0
+}
+
+func (x *Policy) GetWalkCrossDevice() bool {
+	if x != nil {
+		return x.WalkCrossDevice
+	}
+	return false
+}
+
+func (x *Policy) GetIgnoreIrregularFiles() bool {
+	if x != nil {
+		return x.IgnoreIrregularFiles
+	}
+	return false
+}
+
+func (x *Policy) GetMaxDirectoryDepth() uint32 {
+	if x != nil {
+		return x.MaxDirectoryDepth
+	}
+	return 0
+}
+
+func (x *Policy) GetMaxHashFileSizeByExtension() map[string]uint64 {
+	if x != nil {
+		return x.MaxHashFileSizeByExtension
+	}
+	return nil
+}
+
+func (x *Policy) GetComputeTreeDigests() bool {
+	if x != nil {
+		return x.ComputeTreeDigests
+	}
+	return false
+}
+
+func (x *Policy) GetRelativeTo() string {
+	if x != nil {
+		return x.RelativeTo
+	}
+	return ""
+}
+
+func (x *Policy) GetWalkCrossVirtualDevice() bool {
+	if x != nil {
+		return x.WalkCrossVirtualDevice
+	}
+	return false
+}
+
+func (x *Policy) GetCollectBirthTime() bool {
+	if x != nil {
+		return x.CollectBirthTime
+	}
+	return false
+}
+
+func (x *Policy) GetMaxWalkDuration() string {
+	if x != nil {
+		return x.MaxWalkDuration
+	}
+	return ""
+}
+
+func (x *Policy) GetRecordBindMounts() bool {
+	if x != nil {
+		return x.RecordBindMounts
+	}
+	return false
+}
+
+func (x *Policy) GetOutputFileMode() string {
+	if x != nil {
+		return x.OutputFileMode
+	}
+	return ""
+}
+
+func (x *Policy) GetSkipHidden() bool {
+	if x != nil {
+		return x.SkipHidden
+	}
+	return false
+}
+
+func (x *Policy) GetCollectAttributes() bool {
+	if x != nil {
+		return x.CollectAttributes
+	}
+	return false
+}
+
+func (x *Policy) GetExcludeContents() []string {
+	if x != nil {
+		return x.ExcludeContents
+	}
+	return nil
+}
+
+func (x *Policy) GetHashChangedOnly() bool {
+	if x != nil {
+		return x.HashChangedOnly
+	}
+	return false
+}
+
+func (x *Policy) GetHonorIgnoreFiles() bool {
+	if x != nil {
+		return x.HonorIgnoreFiles
+	}
+	return false
+}
+
+func (x *Policy) GetRecordEntryCount() bool {
+	if x != nil {
+		return x.RecordEntryCount
+	}
+	return false
+}
+
+func (x *Policy) GetSortOutput() bool {
+	if x != nil {
+		return x.SortOutput
+	}
+	return false
+}
+
+func (x *Policy) GetMaxOpenFiles() uint32 {
+	if x != nil {
+		return x.MaxOpenFiles
+	}
+	return 0
 }
 
-func (x *Policy) Reset() {
-	*x = Policy{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_fswalker_fswalker_proto_msgTypes[3]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
+func (x *Policy) GetMinDirectoryDepth() uint32 {
+	if x != nil {
+		return x.MinDirectoryDepth
 	}
+	return 0
 }
 
-func (x *Policy) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *Policy) GetHashBufferSize() uint32 {
+	if x != nil {
+		return x.HashBufferSize
+	}
+	return 0
 }
 
-func (*Policy) ProtoMessage() {}
+func (x *Policy) GetExcludeHashingFilesystems() []string {
+	if x != nil {
+		return x.ExcludeHashingFilesystems
+	}
+	return nil
+}
 
-func (x *Policy) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_fswalker_fswalker_proto_msgTypes[3]
-	if protoimpl.UnsafeEnabled && x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+func (x *Policy) GetMtimeReference() string {
+	if x != nil {
+		return x.MtimeReference
 	}
-	return mi.MessageOf(x)
+	return ""
 }
 
-// Deprecated: Use Policy.ProtoReflect.Descriptor instead.
-func (*Policy) Descriptor() ([]byte, []int) {
-	return file_proto_fswalker_fswalker_proto_rawDescGZIP(), []int{3}
+func (x *Policy) GetZeroMtime() bool {
+	if x != nil {
+		return x.ZeroMtime
+	}
+	return false
 }
 
-func (x *Policy) GetVersion() uint32 {
+func (x *Policy) GetHashBlockDevices() bool {
 	if x != nil {
-		return x.Version
+		return x.HashBlockDevices
 	}
-	return 0
+	return false
 }
 
-func (x *Policy) GetInclude() []string {
+func (x *Policy) GetMaxBlockDeviceHashSize() uint64 {
 	if x != nil {
-		return x.Include
+		return x.MaxBlockDeviceHashSize
 	}
-	return nil
+	return 0
 }
 
-func (x *Policy) GetExclude() []string {
+func (x *Policy) GetIsolateIncludes() bool {
 	if x != nil {
-		return x.Exclude
+		return x.IsolateIncludes
 	}
-	return nil
+	return false
 }
 
-func (x *Policy) GetExcludeHashing() []string {
+func (x *Policy) GetComputeDirectorySizes() bool {
 	if x != nil {
-		return x.ExcludeHashing
+		return x.ComputeDirectorySizes
 	}
-	return nil
+	return false
 }
 
-func (x *Policy) GetMaxHashFileSize() uint64 {
+func (x *Policy) GetRecordEntropy() bool {
 	if x != nil {
-		return x.MaxHashFileSize
+		return x.RecordEntropy
 	}
-	return 0
+	return false
 }
 
-func (x *Policy) GetWalkCrossDevice() bool {
+func (x *Policy) GetCollectDataStreams() bool {
 	if x != nil {
-		return x.WalkCrossDevice
+		return x.CollectDataStreams
 	}
 	return false
 }
 
-func (x *Policy) GetIgnoreIrregularFiles() bool {
+func (x *Policy) GetStatFields() []string {
 	if x != nil {
-		return x.IgnoreIrregularFiles
+		return x.StatFields
+	}
+	return nil
+}
+
+func (x *Policy) GetHashSha512() bool {
+	if x != nil {
+		return x.HashSha512
 	}
 	return false
 }
 
-func (x *Policy) GetMaxDirectoryDepth() uint32 {
+func (x *Policy) GetQueueDepth() uint32 {
 	if x != nil {
-		return x.MaxDirectoryDepth
+		return x.QueueDepth
 	}
 	return 0
 }
@@ -446,6 +1148,18 @@ type Walk struct {
 	File []*File `protobuf:"bytes,4,rep,name=file,proto3" json:"file,omitempty"`
 	// notification is a list of notifications that occurred during a walk.
 	Notification []*Notification `protobuf:"bytes,5,rep,name=notification,proto3" json:"notification,omitempty"`
+	// policyFingerprint is the fingerprint of the deterministically marshaled
+	// policy, allowing to quickly tell whether two walks used the same policy.
+	PolicyFingerprint *Fingerprint `protobuf:"bytes,6,opt,name=policyFingerprint,proto3" json:"policyFingerprint,omitempty"`
+	// labels annotate the walk with arbitrary context for audit trails, e.g.
+	// the change ticket it was taken for or the operator who started it.
+	Labels map[string]string `protobuf:"bytes,7,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// dataStreamsCollected is set if the data streams of files were recorded,
+	// telling files without any apart from files of walks not collecting them.
+	DataStreamsCollected bool `protobuf:"varint,8,opt,name=dataStreamsCollected,proto3" json:"dataStreamsCollected,omitempty"`
+	// incomplete is set if the walk was interrupted or exceeded its maximum
+	// duration, so files it didn't reach are missing from it.
+	Incomplete bool `protobuf:"varint,9,opt,name=incomplete,proto3" json:"incomplete,omitempty"`
 	// hostname of the machine the walk originates from.
 	Hostname string `protobuf:"bytes,10,opt,name=hostname,proto3" json:"hostname,omitempty"`
 	// start and stop time of the walk.
@@ -456,7 +1170,7 @@ type Walk struct {
 func (x *Walk) Reset() {
 	*x = Walk{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_fswalker_fswalker_proto_msgTypes[4]
+		mi := &file_proto_fswalker_fswalker_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -469,7 +1183,7 @@ func (x *Walk) String() string {
 func (*Walk) ProtoMessage() {}
 
 func (x *Walk) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_fswalker_fswalker_proto_msgTypes[4]
+	mi := &file_proto_fswalker_fswalker_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -482,7 +1196,7 @@ func (x *Walk) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Walk.ProtoReflect.Descriptor instead.
 func (*Walk) Descriptor() ([]byte, []int) {
-	return file_proto_fswalker_fswalker_proto_rawDescGZIP(), []int{4}
+	return file_proto_fswalker_fswalker_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *Walk) GetId() string {
@@ -520,6 +1234,34 @@ func (x *Walk) GetNotification() []*Notification {
 	return nil
 }
 
+func (x *Walk) GetPolicyFingerprint() *Fingerprint {
+	if x != nil {
+		return x.PolicyFingerprint
+	}
+	return nil
+}
+
+func (x *Walk) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Walk) GetDataStreamsCollected() bool {
+	if x != nil {
+		return x.DataStreamsCollected
+	}
+	return false
+}
+
+func (x *Walk) GetIncomplete() bool {
+	if x != nil {
+		return x.Incomplete
+	}
+	return false
+}
+
 func (x *Walk) GetHostname() string {
 	if x != nil {
 		return x.Hostname
@@ -556,7 +1298,7 @@ type Notification struct {
 func (x *Notification) Reset() {
 	*x = Notification{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_fswalker_fswalker_proto_msgTypes[5]
+		mi := &file_proto_fswalker_fswalker_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -569,7 +1311,7 @@ func (x *Notification) String() string {
 func (*Notification) ProtoMessage() {}
 
 func (x *Notification) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_fswalker_fswalker_proto_msgTypes[5]
+	mi := &file_proto_fswalker_fswalker_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -582,7 +1324,7 @@ func (x *Notification) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Notification.ProtoReflect.Descriptor instead.
 func (*Notification) Descriptor() ([]byte, []int) {
-	return file_proto_fswalker_fswalker_proto_rawDescGZIP(), []int{5}
+	return file_proto_fswalker_fswalker_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *Notification) GetSeverity() Notification_Severity {
@@ -626,7 +1368,7 @@ type FileInfo struct {
 func (x *FileInfo) Reset() {
 	*x = FileInfo{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_fswalker_fswalker_proto_msgTypes[6]
+		mi := &file_proto_fswalker_fswalker_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -639,7 +1381,7 @@ func (x *FileInfo) String() string {
 func (*FileInfo) ProtoMessage() {}
 
 func (x *FileInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_fswalker_fswalker_proto_msgTypes[6]
+	mi := &file_proto_fswalker_fswalker_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -652,7 +1394,7 @@ func (x *FileInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileInfo.ProtoReflect.Descriptor instead.
 func (*FileInfo) Descriptor() ([]byte, []int) {
-	return file_proto_fswalker_fswalker_proto_rawDescGZIP(), []int{6}
+	return file_proto_fswalker_fswalker_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *FileInfo) GetName() string {
@@ -708,12 +1450,19 @@ type FileStat struct {
 	Atime   *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=atime,proto3" json:"atime,omitempty"`
 	Mtime   *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=mtime,proto3" json:"mtime,omitempty"`
 	Ctime   *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=ctime,proto3" json:"ctime,omitempty"`
+	// btime is the birth (creation) time, only set if requested by the policy
+	// and supported by the platform and filesystem.
+	Btime *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=btime,proto3" json:"btime,omitempty"`
+	// attributes are the STATX_ATTR_* flags reported by statx(2) (e.g.
+	// encrypted, verity, dax), only set if requested by the policy and
+	// supported by the platform.
+	Attributes uint64 `protobuf:"varint,15,opt,name=attributes,proto3" json:"attributes,omitempty"`
 }
 
 func (x *FileStat) Reset() {
 	*x = FileStat{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_fswalker_fswalker_proto_msgTypes[7]
+		mi := &file_proto_fswalker_fswalker_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -726,7 +1475,7 @@ func (x *FileStat) String() string {
 func (*FileStat) ProtoMessage() {}
 
 func (x *FileStat) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_fswalker_fswalker_proto_msgTypes[7]
+	mi := &file_proto_fswalker_fswalker_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -739,7 +1488,7 @@ func (x *FileStat) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileStat.ProtoReflect.Descriptor instead.
 func (*FileStat) Descriptor() ([]byte, []int) {
-	return file_proto_fswalker_fswalker_proto_rawDescGZIP(), []int{7}
+	return file_proto_fswalker_fswalker_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *FileStat) GetDev() uint64 {
@@ -833,6 +1582,20 @@ func (x *FileStat) GetCtime() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *FileStat) GetBtime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Btime
+	}
+	return nil
+}
+
+func (x *FileStat) GetAttributes() uint64 {
+	if x != nil {
+		return x.Attributes
+	}
+	return 0
+}
+
 // Fingerprint is a unique identifier for a given File.
 // It consists of a Method (e.g. SHA256) and a value.
 type Fingerprint struct {
@@ -847,7 +1610,7 @@ type Fingerprint struct {
 func (x *Fingerprint) Reset() {
 	*x = Fingerprint{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_fswalker_fswalker_proto_msgTypes[8]
+		mi := &file_proto_fswalker_fswalker_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -860,7 +1623,7 @@ func (x *Fingerprint) String() string {
 func (*Fingerprint) ProtoMessage() {}
 
 func (x *Fingerprint) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_fswalker_fswalker_proto_msgTypes[8]
+	mi := &file_proto_fswalker_fswalker_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -873,7 +1636,7 @@ func (x *Fingerprint) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Fingerprint.ProtoReflect.Descriptor instead.
 func (*Fingerprint) Descriptor() ([]byte, []int) {
-	return file_proto_fswalker_fswalker_proto_rawDescGZIP(), []int{8}
+	return file_proto_fswalker_fswalker_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *Fingerprint) GetMethod() Fingerprint_Method {
@@ -903,12 +1666,31 @@ type File struct {
 	Stat *FileStat `protobuf:"bytes,4,opt,name=stat,proto3" json:"stat,omitempty"`
 	// fingerprint is optionally set when requested for the specific file.
 	Fingerprint []*Fingerprint `protobuf:"bytes,5,rep,name=fingerprint,proto3" json:"fingerprint,omitempty"`
+	// treeDigest is optionally set for directories when requested by the policy.
+	// It summarizes the directory's entire subtree.
+	TreeDigest *Fingerprint `protobuf:"bytes,6,opt,name=treeDigest,proto3" json:"treeDigest,omitempty"`
+	// bindMountSource is the path a directory is bind mounted from, i.e. its
+	// content is the same as the content of that path.
+	// It is only set when requested by the policy.
+	BindMountSource string `protobuf:"bytes,7,opt,name=bindMountSource,proto3" json:"bindMountSource,omitempty"`
+	// entryCount is the number of entries of a directory, including the ones
+	// which weren't walked. It is only set when requested by the policy.
+	EntryCount *uint64 `protobuf:"varint,8,opt,name=entryCount,proto3,oneof" json:"entryCount,omitempty"`
+	// aggregateSize is the total size of all files recorded below a directory.
+	// It is only set when requested by the policy.
+	AggregateSize *uint64 `protobuf:"varint,9,opt,name=aggregateSize,proto3,oneof" json:"aggregateSize,omitempty"`
+	// entropy is the Shannon entropy of the file content in bits per byte, from
+	// 0 to 8. It is only set for hashed files when requested by the policy.
+	Entropy *float64 `protobuf:"fixed64,10,opt,name=entropy,proto3,oneof" json:"entropy,omitempty"`
+	// dataStream lists the named data streams of the file besides its content,
+	// e.g. its resource fork. It is only set when requested by the policy.
+	DataStream []*DataStream `protobuf:"bytes,11,rep,name=dataStream,proto3" json:"dataStream,omitempty"`
 }
 
 func (x *File) Reset() {
 	*x = File{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_fswalker_fswalker_proto_msgTypes[9]
+		mi := &file_proto_fswalker_fswalker_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -921,7 +1703,7 @@ func (x *File) String() string {
 func (*File) ProtoMessage() {}
 
 func (x *File) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_fswalker_fswalker_proto_msgTypes[9]
+	mi := &file_proto_fswalker_fswalker_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -934,7 +1716,7 @@ func (x *File) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use File.ProtoReflect.Descriptor instead.
 func (*File) Descriptor() ([]byte, []int) {
-	return file_proto_fswalker_fswalker_proto_rawDescGZIP(), []int{9}
+	return file_proto_fswalker_fswalker_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *File) GetVersion() uint32 {
@@ -972,6 +1754,114 @@ func (x *File) GetFingerprint() []*Fingerprint {
 	return nil
 }
 
+func (x *File) GetTreeDigest() *Fingerprint {
+	if x != nil {
+		return x.TreeDigest
+	}
+	return nil
+}
+
+func (x *File) GetBindMountSource() string {
+	if x != nil {
+		return x.BindMountSource
+	}
+	return ""
+}
+
+func (x *File) GetEntryCount() uint64 {
+	if x != nil && x.EntryCount != nil {
+		return *x.EntryCount
+	}
+	return 0
+}
+
+func (x *File) GetAggregateSize() uint64 {
+	if x != nil && x.AggregateSize != nil {
+		return *x.AggregateSize
+	}
+	return 0
+}
+
+func (x *File) GetEntropy() float64 {
+	if x != nil && x.Entropy != nil {
+		return *x.Entropy
+	}
+	return 0
+}
+
+func (x *File) GetDataStream() []*DataStream {
+	if x != nil {
+		return x.DataStream
+	}
+	return nil
+}
+
+// DataStream is a named data stream of a file besides its content, e.g. the
+// resource fork of a file on macOS.
+type DataStream struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// name identifies the stream, e.g. "com.apple.ResourceFork".
+	Name        string       `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Size        int64        `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	Fingerprint *Fingerprint `protobuf:"bytes,3,opt,name=fingerprint,proto3" json:"fingerprint,omitempty"`
+}
+
+func (x *DataStream) Reset() {
+	*x = DataStream{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_fswalker_fswalker_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DataStream) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DataStream) ProtoMessage() {}
+
+func (x *DataStream) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_fswalker_fswalker_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DataStream.ProtoReflect.Descriptor instead.
+func (*DataStream) Descriptor() ([]byte, []int) {
+	return file_proto_fswalker_fswalker_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *DataStream) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DataStream) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *DataStream) GetFingerprint() *Fingerprint {
+	if x != nil {
+		return x.Fingerprint
+	}
+	return nil
+}
+
 var File_proto_fswalker_fswalker_proto protoreflect.FileDescriptor
 
 var file_proto_fswalker_fswalker_proto_rawDesc = []byte{
@@ -996,118 +1886,312 @@ var file_proto_fswalker_fswalker_proto_rawDesc = []byte{
 	0x63, 0x65, 0x12, 0x37, 0x0a, 0x0b, 0x66, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72, 0x69, 0x6e,
 	0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x66, 0x73, 0x77, 0x61, 0x6c, 0x6b,
 	0x65, 0x72, 0x2e, 0x46, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x52, 0x0b,
-	0x66, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x22, 0x42, 0x0a, 0x0c, 0x52,
-	0x65, 0x70, 0x6f, 0x72, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x18, 0x0a, 0x07, 0x76,
-	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x76, 0x65,
-	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65,
-	0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x22,
-	0xb4, 0x02, 0x0a, 0x06, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65,
-	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x76, 0x65, 0x72,
-	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x18,
-	0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x12, 0x18,
-	0x0a, 0x07, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52,
-	0x07, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x12, 0x26, 0x0a, 0x0e, 0x65, 0x78, 0x63, 0x6c,
-	0x75, 0x64, 0x65, 0x48, 0x61, 0x73, 0x68, 0x69, 0x6e, 0x67, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09,
-	0x52, 0x0e, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x48, 0x61, 0x73, 0x68, 0x69, 0x6e, 0x67,
-	0x12, 0x28, 0x0a, 0x0f, 0x6d, 0x61, 0x78, 0x48, 0x61, 0x73, 0x68, 0x46, 0x69, 0x6c, 0x65, 0x53,
-	0x69, 0x7a, 0x65, 0x18, 0x1e, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0f, 0x6d, 0x61, 0x78, 0x48, 0x61,
-	0x73, 0x68, 0x46, 0x69, 0x6c, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x28, 0x0a, 0x0f, 0x77, 0x61,
-	0x6c, 0x6b, 0x43, 0x72, 0x6f, 0x73, 0x73, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x18, 0x1f, 0x20,
-	0x01, 0x28, 0x08, 0x52, 0x0f, 0x77, 0x61, 0x6c, 0x6b, 0x43, 0x72, 0x6f, 0x73, 0x73, 0x44, 0x65,
-	0x76, 0x69, 0x63, 0x65, 0x12, 0x32, 0x0a, 0x14, 0x69, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x49, 0x72,
-	0x72, 0x65, 0x67, 0x75, 0x6c, 0x61, 0x72, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x20, 0x20, 0x01,
-	0x28, 0x08, 0x52, 0x14, 0x69, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x49, 0x72, 0x72, 0x65, 0x67, 0x75,
-	0x6c, 0x61, 0x72, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x2c, 0x0a, 0x11, 0x6d, 0x61, 0x78, 0x44,
-	0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x44, 0x65, 0x70, 0x74, 0x68, 0x18, 0x21, 0x20,
-	0x01, 0x28, 0x0d, 0x52, 0x11, 0x6d, 0x61, 0x78, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72,
-	0x79, 0x44, 0x65, 0x70, 0x74, 0x68, 0x22, 0xc8, 0x02, 0x0a, 0x04, 0x57, 0x61, 0x6c, 0x6b, 0x12,
-	0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12,
-	0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d,
-	0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x28, 0x0a, 0x06, 0x70, 0x6f, 0x6c,
-	0x69, 0x63, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x66, 0x73, 0x77, 0x61,
-	0x6c, 0x6b, 0x65, 0x72, 0x2e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x06, 0x70, 0x6f, 0x6c,
-	0x69, 0x63, 0x79, 0x12, 0x22, 0x0a, 0x04, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x03, 0x28,
-	0x0b, 0x32, 0x0e, 0x2e, 0x66, 0x73, 0x77, 0x61, 0x6c, 0x6b, 0x65, 0x72, 0x2e, 0x46, 0x69, 0x6c,
-	0x65, 0x52, 0x04, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x3a, 0x0a, 0x0c, 0x6e, 0x6f, 0x74, 0x69, 0x66,
-	0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e,
-	0x66, 0x73, 0x77, 0x61, 0x6c, 0x6b, 0x65, 0x72, 0x2e, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x63,
-	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x6e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74,
-	0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x18,
-	0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x12,
-	0x38, 0x0a, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x57, 0x61, 0x6c, 0x6b, 0x18, 0x0b, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09,
-	0x73, 0x74, 0x61, 0x72, 0x74, 0x57, 0x61, 0x6c, 0x6b, 0x12, 0x36, 0x0a, 0x08, 0x73, 0x74, 0x6f,
-	0x70, 0x57, 0x61, 0x6c, 0x6b, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f,
-	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69,
-	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x73, 0x74, 0x6f, 0x70, 0x57, 0x61, 0x6c,
-	0x6b, 0x22, 0xb4, 0x01, 0x0a, 0x0c, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69,
-	0x6f, 0x6e, 0x12, 0x3b, 0x0a, 0x08, 0x73, 0x65, 0x76, 0x65, 0x72, 0x69, 0x74, 0x79, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x0e, 0x32, 0x1f, 0x2e, 0x66, 0x73, 0x77, 0x61, 0x6c, 0x6b, 0x65, 0x72, 0x2e,
-	0x4e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x53, 0x65, 0x76,
-	0x65, 0x72, 0x69, 0x74, 0x79, 0x52, 0x08, 0x73, 0x65, 0x76, 0x65, 0x72, 0x69, 0x74, 0x79, 0x12,
-	0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70,
-	0x61, 0x74, 0x68, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x03,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x39, 0x0a,
-	0x08, 0x53, 0x65, 0x76, 0x65, 0x72, 0x69, 0x74, 0x79, 0x12, 0x0b, 0x0a, 0x07, 0x55, 0x4e, 0x4b,
-	0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x49, 0x4e, 0x46, 0x4f, 0x10, 0x01,
-	0x12, 0x0b, 0x0a, 0x07, 0x57, 0x41, 0x52, 0x4e, 0x49, 0x4e, 0x47, 0x10, 0x02, 0x12, 0x09, 0x0a,
-	0x05, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x03, 0x22, 0x94, 0x01, 0x0a, 0x08, 0x46, 0x69, 0x6c,
-	0x65, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a,
-	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x12, 0x0a,
-	0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x6d, 0x6f, 0x64,
-	0x65, 0x12, 0x36, 0x0a, 0x08, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64, 0x18, 0x04, 0x20,
+	0x66, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x22, 0x3b, 0x0a, 0x0d, 0x41,
+	0x63, 0x63, 0x65, 0x70, 0x74, 0x65, 0x64, 0x44, 0x69, 0x66, 0x66, 0x73, 0x12, 0x2a, 0x0a, 0x04,
+	0x64, 0x69, 0x66, 0x66, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x66, 0x73, 0x77,
+	0x61, 0x6c, 0x6b, 0x65, 0x72, 0x2e, 0x41, 0x63, 0x63, 0x65, 0x70, 0x74, 0x65, 0x64, 0x44, 0x69,
+	0x66, 0x66, 0x52, 0x04, 0x64, 0x69, 0x66, 0x66, 0x22, 0x4a, 0x0a, 0x0c, 0x41, 0x63, 0x63, 0x65,
+	0x70, 0x74, 0x65, 0x64, 0x44, 0x69, 0x66, 0x66, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x12, 0x0a, 0x04,
+	0x6b, 0x69, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64,
+	0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x68, 0x61, 0x73, 0x68, 0x22, 0xe1, 0x05, 0x0a, 0x0c, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x43,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x18, 0x0a, 0x07, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x07, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x12, 0x26, 0x0a, 0x0e, 0x6f, 0x75, 0x74,
+	0x70, 0x75, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0e, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x4d, 0x6f, 0x64,
+	0x65, 0x12, 0x2a, 0x0a, 0x10, 0x6d, 0x74, 0x69, 0x6d, 0x65, 0x47, 0x72, 0x61, 0x6e, 0x75, 0x6c,
+	0x61, 0x72, 0x69, 0x74, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x6d, 0x74, 0x69,
+	0x6d, 0x65, 0x47, 0x72, 0x61, 0x6e, 0x75, 0x6c, 0x61, 0x72, 0x69, 0x74, 0x79, 0x12, 0x2c, 0x0a,
+	0x11, 0x66, 0x6c, 0x61, 0x67, 0x57, 0x6f, 0x72, 0x6c, 0x64, 0x57, 0x72, 0x69, 0x74, 0x61, 0x62,
+	0x6c, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x11, 0x66, 0x6c, 0x61, 0x67, 0x57, 0x6f,
+	0x72, 0x6c, 0x64, 0x57, 0x72, 0x69, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x30, 0x0a, 0x13, 0x72,
+	0x65, 0x70, 0x6f, 0x72, 0x74, 0x57, 0x6f, 0x72, 0x6c, 0x64, 0x57, 0x72, 0x69, 0x74, 0x61, 0x62,
+	0x6c, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x13, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74,
+	0x57, 0x6f, 0x72, 0x6c, 0x64, 0x57, 0x72, 0x69, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x22, 0x0a,
+	0x0c, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64, 0x4f, 0x6e, 0x6c, 0x79, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x0c, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64, 0x4f, 0x6e, 0x6c,
+	0x79, 0x12, 0x2e, 0x0a, 0x12, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x49, 0x6e, 0x6f, 0x64, 0x65,
+	0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x12, 0x72,
+	0x65, 0x70, 0x6f, 0x72, 0x74, 0x49, 0x6e, 0x6f, 0x64, 0x65, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65,
+	0x73, 0x12, 0x24, 0x0a, 0x0d, 0x63, 0x72, 0x69, 0x74, 0x69, 0x63, 0x61, 0x6c, 0x50, 0x61, 0x74,
+	0x68, 0x73, 0x18, 0x09, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0d, 0x63, 0x72, 0x69, 0x74, 0x69, 0x63,
+	0x61, 0x6c, 0x50, 0x61, 0x74, 0x68, 0x73, 0x12, 0x22, 0x0a, 0x0c, 0x6d, 0x65, 0x74, 0x61, 0x64,
+	0x61, 0x74, 0x61, 0x4f, 0x6e, 0x6c, 0x79, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x6d,
+	0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x4f, 0x6e, 0x6c, 0x79, 0x12, 0x38, 0x0a, 0x17, 0x72,
+	0x65, 0x70, 0x6f, 0x72, 0x74, 0x50, 0x65, 0x72, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x43,
+	0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x08, 0x52, 0x17, 0x72, 0x65,
+	0x70, 0x6f, 0x72, 0x74, 0x50, 0x65, 0x72, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x43, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x73, 0x12, 0x46, 0x0a, 0x1e, 0x73, 0x75, 0x70, 0x70, 0x72, 0x65, 0x73,
+	0x73, 0x52, 0x65, 0x63, 0x75, 0x72, 0x72, 0x69, 0x6e, 0x67, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x69,
+	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x08, 0x52, 0x1e, 0x73,
+	0x75, 0x70, 0x70, 0x72, 0x65, 0x73, 0x73, 0x52, 0x65, 0x63, 0x75, 0x72, 0x72, 0x69, 0x6e, 0x67,
+	0x4e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x28, 0x0a,
+	0x0f, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x54, 0x69, 0x6d, 0x65, 0x7a, 0x6f, 0x6e, 0x65,
+	0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x54,
+	0x69, 0x6d, 0x65, 0x7a, 0x6f, 0x6e, 0x65, 0x12, 0x38, 0x0a, 0x17, 0x66, 0x6c, 0x61, 0x67, 0x50,
+	0x65, 0x72, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x4c, 0x6f, 0x6f, 0x73, 0x65, 0x6e, 0x69,
+	0x6e, 0x67, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x08, 0x52, 0x17, 0x66, 0x6c, 0x61, 0x67, 0x50, 0x65,
+	0x72, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x4c, 0x6f, 0x6f, 0x73, 0x65, 0x6e, 0x69, 0x6e,
+	0x67, 0x12, 0x30, 0x0a, 0x13, 0x66, 0x6c, 0x61, 0x67, 0x45, 0x6e, 0x74, 0x72, 0x6f, 0x70, 0x79,
+	0x49, 0x6e, 0x63, 0x72, 0x65, 0x61, 0x73, 0x65, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x01, 0x52, 0x13,
+	0x66, 0x6c, 0x61, 0x67, 0x45, 0x6e, 0x74, 0x72, 0x6f, 0x70, 0x79, 0x49, 0x6e, 0x63, 0x72, 0x65,
+	0x61, 0x73, 0x65, 0x12, 0x33, 0x0a, 0x0a, 0x70, 0x61, 0x74, 0x68, 0x47, 0x72, 0x6f, 0x75, 0x70,
+	0x73, 0x18, 0x10, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x66, 0x73, 0x77, 0x61, 0x6c, 0x6b,
+	0x65, 0x72, 0x2e, 0x50, 0x61, 0x74, 0x68, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x52, 0x0a, 0x70, 0x61,
+	0x74, 0x68, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x22, 0x3b, 0x0a, 0x09, 0x50, 0x61, 0x74, 0x68,
+	0x47, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x12,
+	0x14, 0x0a, 0x05, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x67, 0x72, 0x6f, 0x75, 0x70, 0x22, 0xb9, 0x0d, 0x0a, 0x06, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79,
+	0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x69, 0x6e,
+	0x63, 0x6c, 0x75, 0x64, 0x65, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x69, 0x6e, 0x63,
+	0x6c, 0x75, 0x64, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x18,
+	0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x12, 0x26,
+	0x0a, 0x0e, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x48, 0x61, 0x73, 0x68, 0x69, 0x6e, 0x67,
+	0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0e, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x48,
+	0x61, 0x73, 0x68, 0x69, 0x6e, 0x67, 0x12, 0x28, 0x0a, 0x0f, 0x6d, 0x61, 0x78, 0x48, 0x61, 0x73,
+	0x68, 0x46, 0x69, 0x6c, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x1e, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x0f, 0x6d, 0x61, 0x78, 0x48, 0x61, 0x73, 0x68, 0x46, 0x69, 0x6c, 0x65, 0x53, 0x69, 0x7a, 0x65,
+	0x12, 0x28, 0x0a, 0x0f, 0x77, 0x61, 0x6c, 0x6b, 0x43, 0x72, 0x6f, 0x73, 0x73, 0x44, 0x65, 0x76,
+	0x69, 0x63, 0x65, 0x18, 0x1f, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x77, 0x61, 0x6c, 0x6b, 0x43,
+	0x72, 0x6f, 0x73, 0x73, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x12, 0x32, 0x0a, 0x14, 0x69, 0x67,
+	0x6e, 0x6f, 0x72, 0x65, 0x49, 0x72, 0x72, 0x65, 0x67, 0x75, 0x6c, 0x61, 0x72, 0x46, 0x69, 0x6c,
+	0x65, 0x73, 0x18, 0x20, 0x20, 0x01, 0x28, 0x08, 0x52, 0x14, 0x69, 0x67, 0x6e, 0x6f, 0x72, 0x65,
+	0x49, 0x72, 0x72, 0x65, 0x67, 0x75, 0x6c, 0x61, 0x72, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x2c,
+	0x0a, 0x11, 0x6d, 0x61, 0x78, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x44, 0x65,
+	0x70, 0x74, 0x68, 0x18, 0x21, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x11, 0x6d, 0x61, 0x78, 0x44, 0x69,
+	0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x44, 0x65, 0x70, 0x74, 0x68, 0x12, 0x70, 0x0a, 0x1a,
+	0x6d, 0x61, 0x78, 0x48, 0x61, 0x73, 0x68, 0x46, 0x69, 0x6c, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x42,
+	0x79, 0x45, 0x78, 0x74, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x22, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x30, 0x2e, 0x66, 0x73, 0x77, 0x61, 0x6c, 0x6b, 0x65, 0x72, 0x2e, 0x50, 0x6f, 0x6c, 0x69,
+	0x63, 0x79, 0x2e, 0x4d, 0x61, 0x78, 0x48, 0x61, 0x73, 0x68, 0x46, 0x69, 0x6c, 0x65, 0x53, 0x69,
+	0x7a, 0x65, 0x42, 0x79, 0x45, 0x78, 0x74, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x52, 0x1a, 0x6d, 0x61, 0x78, 0x48, 0x61, 0x73, 0x68, 0x46, 0x69, 0x6c, 0x65, 0x53,
+	0x69, 0x7a, 0x65, 0x42, 0x79, 0x45, 0x78, 0x74, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x2e,
+	0x0a, 0x12, 0x63, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x65, 0x54, 0x72, 0x65, 0x65, 0x44, 0x69, 0x67,
+	0x65, 0x73, 0x74, 0x73, 0x18, 0x23, 0x20, 0x01, 0x28, 0x08, 0x52, 0x12, 0x63, 0x6f, 0x6d, 0x70,
+	0x75, 0x74, 0x65, 0x54, 0x72, 0x65, 0x65, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x73, 0x12, 0x1e,
+	0x0a, 0x0a, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x76, 0x65, 0x54, 0x6f, 0x18, 0x24, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0a, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x76, 0x65, 0x54, 0x6f, 0x12, 0x36,
+	0x0a, 0x16, 0x77, 0x61, 0x6c, 0x6b, 0x43, 0x72, 0x6f, 0x73, 0x73, 0x56, 0x69, 0x72, 0x74, 0x75,
+	0x61, 0x6c, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x18, 0x25, 0x20, 0x01, 0x28, 0x08, 0x52, 0x16,
+	0x77, 0x61, 0x6c, 0x6b, 0x43, 0x72, 0x6f, 0x73, 0x73, 0x56, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c,
+	0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x12, 0x2a, 0x0a, 0x10, 0x63, 0x6f, 0x6c, 0x6c, 0x65, 0x63,
+	0x74, 0x42, 0x69, 0x72, 0x74, 0x68, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x26, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x10, 0x63, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x42, 0x69, 0x72, 0x74, 0x68, 0x54, 0x69,
+	0x6d, 0x65, 0x12, 0x28, 0x0a, 0x0f, 0x6d, 0x61, 0x78, 0x57, 0x61, 0x6c, 0x6b, 0x44, 0x75, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x27, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x6d, 0x61, 0x78,
+	0x57, 0x61, 0x6c, 0x6b, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2a, 0x0a, 0x10,
+	0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x42, 0x69, 0x6e, 0x64, 0x4d, 0x6f, 0x75, 0x6e, 0x74, 0x73,
+	0x18, 0x28, 0x20, 0x01, 0x28, 0x08, 0x52, 0x10, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x42, 0x69,
+	0x6e, 0x64, 0x4d, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x12, 0x26, 0x0a, 0x0e, 0x6f, 0x75, 0x74, 0x70,
+	0x75, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x18, 0x29, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0e, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x4d, 0x6f, 0x64, 0x65,
+	0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x6b, 0x69, 0x70, 0x48, 0x69, 0x64, 0x64, 0x65, 0x6e, 0x18, 0x2a,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x73, 0x6b, 0x69, 0x70, 0x48, 0x69, 0x64, 0x64, 0x65, 0x6e,
+	0x12, 0x2c, 0x0a, 0x11, 0x63, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x41, 0x74, 0x74, 0x72, 0x69,
+	0x62, 0x75, 0x74, 0x65, 0x73, 0x18, 0x2b, 0x20, 0x01, 0x28, 0x08, 0x52, 0x11, 0x63, 0x6f, 0x6c,
+	0x6c, 0x65, 0x63, 0x74, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x12, 0x28,
+	0x0a, 0x0f, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74,
+	0x73, 0x18, 0x2c, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0f, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65,
+	0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x28, 0x0a, 0x0f, 0x68, 0x61, 0x73, 0x68,
+	0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x4f, 0x6e, 0x6c, 0x79, 0x18, 0x2d, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x0f, 0x68, 0x61, 0x73, 0x68, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x4f, 0x6e,
+	0x6c, 0x79, 0x12, 0x2a, 0x0a, 0x10, 0x68, 0x6f, 0x6e, 0x6f, 0x72, 0x49, 0x67, 0x6e, 0x6f, 0x72,
+	0x65, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x2e, 0x20, 0x01, 0x28, 0x08, 0x52, 0x10, 0x68, 0x6f,
+	0x6e, 0x6f, 0x72, 0x49, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x2a,
+	0x0a, 0x10, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x43, 0x6f, 0x75,
+	0x6e, 0x74, 0x18, 0x2f, 0x20, 0x01, 0x28, 0x08, 0x52, 0x10, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x6f,
+	0x72, 0x74, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x18, 0x30, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a,
+	0x73, 0x6f, 0x72, 0x74, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x22, 0x0a, 0x0c, 0x6d, 0x61,
+	0x78, 0x4f, 0x70, 0x65, 0x6e, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x31, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x0c, 0x6d, 0x61, 0x78, 0x4f, 0x70, 0x65, 0x6e, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x2c,
+	0x0a, 0x11, 0x6d, 0x69, 0x6e, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x44, 0x65,
+	0x70, 0x74, 0x68, 0x18, 0x32, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x11, 0x6d, 0x69, 0x6e, 0x44, 0x69,
+	0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x44, 0x65, 0x70, 0x74, 0x68, 0x12, 0x26, 0x0a, 0x0e,
+	0x68, 0x61, 0x73, 0x68, 0x42, 0x75, 0x66, 0x66, 0x65, 0x72, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x33,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x0e, 0x68, 0x61, 0x73, 0x68, 0x42, 0x75, 0x66, 0x66, 0x65, 0x72,
+	0x53, 0x69, 0x7a, 0x65, 0x12, 0x3c, 0x0a, 0x19, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x48,
+	0x61, 0x73, 0x68, 0x69, 0x6e, 0x67, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d,
+	0x73, 0x18, 0x34, 0x20, 0x03, 0x28, 0x09, 0x52, 0x19, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65,
+	0x48, 0x61, 0x73, 0x68, 0x69, 0x6e, 0x67, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65,
+	0x6d, 0x73, 0x12, 0x26, 0x0a, 0x0e, 0x6d, 0x74, 0x69, 0x6d, 0x65, 0x52, 0x65, 0x66, 0x65, 0x72,
+	0x65, 0x6e, 0x63, 0x65, 0x18, 0x35, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x6d, 0x74, 0x69, 0x6d,
+	0x65, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x7a, 0x65,
+	0x72, 0x6f, 0x4d, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x36, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x7a,
+	0x65, 0x72, 0x6f, 0x4d, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x2a, 0x0a, 0x10, 0x68, 0x61, 0x73, 0x68,
+	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x18, 0x37, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x10, 0x68, 0x61, 0x73, 0x68, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x44, 0x65, 0x76,
+	0x69, 0x63, 0x65, 0x73, 0x12, 0x36, 0x0a, 0x16, 0x6d, 0x61, 0x78, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
+	0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x48, 0x61, 0x73, 0x68, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x38,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x16, 0x6d, 0x61, 0x78, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x44, 0x65,
+	0x76, 0x69, 0x63, 0x65, 0x48, 0x61, 0x73, 0x68, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x28, 0x0a, 0x0f,
+	0x69, 0x73, 0x6f, 0x6c, 0x61, 0x74, 0x65, 0x49, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x73, 0x18,
+	0x39, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x69, 0x73, 0x6f, 0x6c, 0x61, 0x74, 0x65, 0x49, 0x6e,
+	0x63, 0x6c, 0x75, 0x64, 0x65, 0x73, 0x12, 0x34, 0x0a, 0x15, 0x63, 0x6f, 0x6d, 0x70, 0x75, 0x74,
+	0x65, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x53, 0x69, 0x7a, 0x65, 0x73, 0x18,
+	0x3a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x15, 0x63, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x65, 0x44, 0x69,
+	0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x53, 0x69, 0x7a, 0x65, 0x73, 0x12, 0x24, 0x0a, 0x0d,
+	0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x45, 0x6e, 0x74, 0x72, 0x6f, 0x70, 0x79, 0x18, 0x3b, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x0d, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x45, 0x6e, 0x74, 0x72, 0x6f,
+	0x70, 0x79, 0x12, 0x2e, 0x0a, 0x12, 0x63, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x44, 0x61, 0x74,
+	0x61, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x18, 0x3c, 0x20, 0x01, 0x28, 0x08, 0x52, 0x12,
+	0x63, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x44, 0x61, 0x74, 0x61, 0x53, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x74, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x73,
+	0x18, 0x3d, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x74, 0x61, 0x74, 0x46, 0x69, 0x65, 0x6c,
+	0x64, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x68, 0x61, 0x73, 0x68, 0x53, 0x68, 0x61, 0x35, 0x31, 0x32,
+	0x18, 0x3e, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x68, 0x61, 0x73, 0x68, 0x53, 0x68, 0x61, 0x35,
+	0x31, 0x32, 0x12, 0x1e, 0x0a, 0x0a, 0x71, 0x75, 0x65, 0x75, 0x65, 0x44, 0x65, 0x70, 0x74, 0x68,
+	0x18, 0x3f, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x71, 0x75, 0x65, 0x75, 0x65, 0x44, 0x65, 0x70,
+	0x74, 0x68, 0x1a, 0x4d, 0x0a, 0x1f, 0x4d, 0x61, 0x78, 0x48, 0x61, 0x73, 0x68, 0x46, 0x69, 0x6c,
+	0x65, 0x53, 0x69, 0x7a, 0x65, 0x42, 0x79, 0x45, 0x78, 0x74, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38,
+	0x01, 0x22, 0xd0, 0x04, 0x0a, 0x04, 0x57, 0x61, 0x6c, 0x6b, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x76, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x28, 0x0a, 0x06, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x66, 0x73, 0x77, 0x61, 0x6c, 0x6b, 0x65, 0x72, 0x2e,
+	0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x06, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x22,
+	0x0a, 0x04, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x66,
+	0x73, 0x77, 0x61, 0x6c, 0x6b, 0x65, 0x72, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x04, 0x66, 0x69,
+	0x6c, 0x65, 0x12, 0x3a, 0x0a, 0x0c, 0x6e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x66, 0x73, 0x77, 0x61, 0x6c,
+	0x6b, 0x65, 0x72, 0x2e, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x0c, 0x6e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x43,
+	0x0a, 0x11, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x46, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72,
+	0x69, 0x6e, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x66, 0x73, 0x77, 0x61,
+	0x6c, 0x6b, 0x65, 0x72, 0x2e, 0x46, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72, 0x69, 0x6e, 0x74,
+	0x52, 0x11, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x46, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72,
+	0x69, 0x6e, 0x74, 0x12, 0x32, 0x0a, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x18, 0x07, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x66, 0x73, 0x77, 0x61, 0x6c, 0x6b, 0x65, 0x72, 0x2e, 0x57,
+	0x61, 0x6c, 0x6b, 0x2e, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52,
+	0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x12, 0x32, 0x0a, 0x14, 0x64, 0x61, 0x74, 0x61, 0x53,
+	0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x65, 0x64, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x14, 0x64, 0x61, 0x74, 0x61, 0x53, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x73, 0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x65, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x69,
+	0x6e, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x0a, 0x69, 0x6e, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x68,
+	0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x68,
+	0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x38, 0x0a, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74,
+	0x57, 0x61, 0x6c, 0x6b, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x57, 0x61, 0x6c,
+	0x6b, 0x12, 0x36, 0x0a, 0x08, 0x73, 0x74, 0x6f, 0x70, 0x57, 0x61, 0x6c, 0x6b, 0x18, 0x0c, 0x20,
 	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
 	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
-	0x08, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x73, 0x44,
-	0x69, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x69, 0x73, 0x44, 0x69, 0x72, 0x22,
-	0xf0, 0x02, 0x0a, 0x08, 0x46, 0x69, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x12, 0x10, 0x0a, 0x03,
-	0x64, 0x65, 0x76, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x03, 0x64, 0x65, 0x76, 0x12, 0x14,
-	0x0a, 0x05, 0x69, 0x6e, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x69,
-	0x6e, 0x6f, 0x64, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x6c, 0x69, 0x6e, 0x6b, 0x18, 0x03, 0x20,
-	0x01, 0x28, 0x04, 0x52, 0x05, 0x6e, 0x6c, 0x69, 0x6e, 0x6b, 0x12, 0x12, 0x0a, 0x04, 0x6d, 0x6f,
-	0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x12, 0x10,
-	0x0a, 0x03, 0x75, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x75, 0x69, 0x64,
-	0x12, 0x10, 0x0a, 0x03, 0x67, 0x69, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x67,
-	0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x64, 0x65, 0x76, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04,
-	0x52, 0x04, 0x72, 0x64, 0x65, 0x76, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x08,
-	0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x62, 0x6c,
-	0x6b, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x62, 0x6c, 0x6b,
-	0x73, 0x69, 0x7a, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x0a,
-	0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x12, 0x30, 0x0a, 0x05,
-	0x61, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f,
-	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69,
-	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x05, 0x61, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x30,
-	0x0a, 0x05, 0x6d, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
-	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
-	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x05, 0x6d, 0x74, 0x69, 0x6d, 0x65,
-	0x12, 0x30, 0x0a, 0x05, 0x63, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
-	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x05, 0x63, 0x74, 0x69,
-	0x6d, 0x65, 0x22, 0x7c, 0x0a, 0x0b, 0x46, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72, 0x69, 0x6e,
-	0x74, 0x12, 0x34, 0x0a, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0e, 0x32, 0x1c, 0x2e, 0x66, 0x73, 0x77, 0x61, 0x6c, 0x6b, 0x65, 0x72, 0x2e, 0x46, 0x69, 0x6e,
-	0x67, 0x65, 0x72, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x2e, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x52,
-	0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x21, 0x0a,
-	0x06, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x12, 0x0b, 0x0a, 0x07, 0x55, 0x4e, 0x4b, 0x4e, 0x4f,
-	0x57, 0x4e, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x53, 0x48, 0x41, 0x32, 0x35, 0x36, 0x10, 0x01,
-	0x22, 0xbd, 0x01, 0x0a, 0x04, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72,
-	0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73,
-	0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x26, 0x0a, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x18,
-	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x66, 0x73, 0x77, 0x61, 0x6c, 0x6b, 0x65, 0x72,
-	0x2e, 0x46, 0x69, 0x6c, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x12,
-	0x26, 0x0a, 0x04, 0x73, 0x74, 0x61, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e,
-	0x66, 0x73, 0x77, 0x61, 0x6c, 0x6b, 0x65, 0x72, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x53, 0x74, 0x61,
-	0x74, 0x52, 0x04, 0x73, 0x74, 0x61, 0x74, 0x12, 0x37, 0x0a, 0x0b, 0x66, 0x69, 0x6e, 0x67, 0x65,
-	0x72, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x66,
-	0x73, 0x77, 0x61, 0x6c, 0x6b, 0x65, 0x72, 0x2e, 0x46, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72,
-	0x69, 0x6e, 0x74, 0x52, 0x0b, 0x66, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72, 0x69, 0x6e, 0x74,
-	0x42, 0x1c, 0x5a, 0x1a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67,
-	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x66, 0x73, 0x77, 0x61, 0x6c, 0x6b, 0x65, 0x72, 0x62, 0x06,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x08, 0x73, 0x74, 0x6f, 0x70, 0x57, 0x61, 0x6c, 0x6b, 0x1a, 0x39, 0x0a, 0x0b, 0x4c, 0x61, 0x62,
+	0x65, 0x6c, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x3a, 0x02, 0x38, 0x01, 0x22, 0xb4, 0x01, 0x0a, 0x0c, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x63,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x3b, 0x0a, 0x08, 0x73, 0x65, 0x76, 0x65, 0x72, 0x69, 0x74,
+	0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1f, 0x2e, 0x66, 0x73, 0x77, 0x61, 0x6c, 0x6b,
+	0x65, 0x72, 0x2e, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e,
+	0x53, 0x65, 0x76, 0x65, 0x72, 0x69, 0x74, 0x79, 0x52, 0x08, 0x73, 0x65, 0x76, 0x65, 0x72, 0x69,
+	0x74, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x22, 0x39, 0x0a, 0x08, 0x53, 0x65, 0x76, 0x65, 0x72, 0x69, 0x74, 0x79, 0x12, 0x0b, 0x0a, 0x07,
+	0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x49, 0x4e, 0x46,
+	0x4f, 0x10, 0x01, 0x12, 0x0b, 0x0a, 0x07, 0x57, 0x41, 0x52, 0x4e, 0x49, 0x4e, 0x47, 0x10, 0x02,
+	0x12, 0x09, 0x0a, 0x05, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x03, 0x22, 0x94, 0x01, 0x0a, 0x08,
+	0x46, 0x69, 0x6c, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04,
+	0x73, 0x69, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65,
+	0x12, 0x12, 0x0a, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04,
+	0x6d, 0x6f, 0x64, 0x65, 0x12, 0x36, 0x0a, 0x08, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x52, 0x08, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64, 0x12, 0x14, 0x0a, 0x05,
+	0x69, 0x73, 0x44, 0x69, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x69, 0x73, 0x44,
+	0x69, 0x72, 0x22, 0xc2, 0x03, 0x0a, 0x08, 0x46, 0x69, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x12,
+	0x10, 0x0a, 0x03, 0x64, 0x65, 0x76, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x03, 0x64, 0x65,
+	0x76, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x05, 0x69, 0x6e, 0x6f, 0x64, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x6c, 0x69, 0x6e, 0x6b,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x6e, 0x6c, 0x69, 0x6e, 0x6b, 0x12, 0x12, 0x0a,
+	0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x6d, 0x6f, 0x64,
+	0x65, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03,
+	0x75, 0x69, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x67, 0x69, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x03, 0x67, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x64, 0x65, 0x76, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x04, 0x72, 0x64, 0x65, 0x76, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a,
+	0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x18, 0x0a,
+	0x07, 0x62, 0x6c, 0x6b, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07,
+	0x62, 0x6c, 0x6b, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b,
+	0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x12,
+	0x30, 0x0a, 0x05, 0x61, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x05, 0x61, 0x74, 0x69, 0x6d,
+	0x65, 0x12, 0x30, 0x0a, 0x05, 0x6d, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x05, 0x6d, 0x74,
+	0x69, 0x6d, 0x65, 0x12, 0x30, 0x0a, 0x05, 0x63, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x0d, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x05,
+	0x63, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x30, 0x0a, 0x05, 0x62, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x0e,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x52, 0x05, 0x62, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x61, 0x74, 0x74, 0x72, 0x69,
+	0x62, 0x75, 0x74, 0x65, 0x73, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x61, 0x74, 0x74,
+	0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x22, 0xb2, 0x01, 0x0a, 0x0b, 0x46, 0x69, 0x6e, 0x67,
+	0x65, 0x72, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x12, 0x34, 0x0a, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1c, 0x2e, 0x66, 0x73, 0x77, 0x61, 0x6c, 0x6b,
+	0x65, 0x72, 0x2e, 0x46, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x2e, 0x4d,
+	0x65, 0x74, 0x68, 0x6f, 0x64, 0x52, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x12, 0x14, 0x0a,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x22, 0x57, 0x0a, 0x06, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x12, 0x0b, 0x0a,
+	0x07, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x53, 0x48,
+	0x41, 0x32, 0x35, 0x36, 0x10, 0x01, 0x12, 0x13, 0x0a, 0x0f, 0x46, 0x53, 0x56, 0x45, 0x52, 0x49,
+	0x54, 0x59, 0x5f, 0x53, 0x48, 0x41, 0x32, 0x35, 0x36, 0x10, 0x02, 0x12, 0x13, 0x0a, 0x0f, 0x46,
+	0x53, 0x56, 0x45, 0x52, 0x49, 0x54, 0x59, 0x5f, 0x53, 0x48, 0x41, 0x35, 0x31, 0x32, 0x10, 0x03,
+	0x12, 0x0a, 0x0a, 0x06, 0x53, 0x48, 0x41, 0x35, 0x31, 0x32, 0x10, 0x04, 0x22, 0xf0, 0x03, 0x0a,
+	0x04, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70,
+	0x61, 0x74, 0x68, 0x12, 0x26, 0x0a, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x12, 0x2e, 0x66, 0x73, 0x77, 0x61, 0x6c, 0x6b, 0x65, 0x72, 0x2e, 0x46, 0x69, 0x6c,
+	0x65, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x12, 0x26, 0x0a, 0x04, 0x73,
+	0x74, 0x61, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x66, 0x73, 0x77, 0x61,
+	0x6c, 0x6b, 0x65, 0x72, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x52, 0x04, 0x73,
+	0x74, 0x61, 0x74, 0x12, 0x37, 0x0a, 0x0b, 0x66, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72, 0x69,
+	0x6e, 0x74, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x66, 0x73, 0x77, 0x61, 0x6c,
+	0x6b, 0x65, 0x72, 0x2e, 0x46, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x52,
+	0x0b, 0x66, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x12, 0x35, 0x0a, 0x0a,
+	0x74, 0x72, 0x65, 0x65, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x15, 0x2e, 0x66, 0x73, 0x77, 0x61, 0x6c, 0x6b, 0x65, 0x72, 0x2e, 0x46, 0x69, 0x6e, 0x67,
+	0x65, 0x72, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x52, 0x0a, 0x74, 0x72, 0x65, 0x65, 0x44, 0x69, 0x67,
+	0x65, 0x73, 0x74, 0x12, 0x28, 0x0a, 0x0f, 0x62, 0x69, 0x6e, 0x64, 0x4d, 0x6f, 0x75, 0x6e, 0x74,
+	0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x62, 0x69,
+	0x6e, 0x64, 0x4d, 0x6f, 0x75, 0x6e, 0x74, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x23, 0x0a,
+	0x0a, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x04, 0x48, 0x00, 0x52, 0x0a, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x88,
+	0x01, 0x01, 0x12, 0x29, 0x0a, 0x0d, 0x61, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x65, 0x53,
+	0x69, 0x7a, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x04, 0x48, 0x01, 0x52, 0x0d, 0x61, 0x67, 0x67,
+	0x72, 0x65, 0x67, 0x61, 0x74, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x88, 0x01, 0x01, 0x12, 0x1d, 0x0a,
+	0x07, 0x65, 0x6e, 0x74, 0x72, 0x6f, 0x70, 0x79, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x01, 0x48, 0x02,
+	0x52, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x6f, 0x70, 0x79, 0x88, 0x01, 0x01, 0x12, 0x34, 0x0a, 0x0a,
+	0x64, 0x61, 0x74, 0x61, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x14, 0x2e, 0x66, 0x73, 0x77, 0x61, 0x6c, 0x6b, 0x65, 0x72, 0x2e, 0x44, 0x61, 0x74, 0x61,
+	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x0a, 0x64, 0x61, 0x74, 0x61, 0x53, 0x74, 0x72, 0x65,
+	0x61, 0x6d, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x43, 0x6f, 0x75, 0x6e,
+	0x74, 0x42, 0x10, 0x0a, 0x0e, 0x5f, 0x61, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x65, 0x53,
+	0x69, 0x7a, 0x65, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x65, 0x6e, 0x74, 0x72, 0x6f, 0x70, 0x79, 0x22,
+	0x6d, 0x0a, 0x0a, 0x44, 0x61, 0x74, 0x61, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x12, 0x0a,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x37, 0x0a, 0x0b, 0x66, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70,
+	0x72, 0x69, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x66, 0x73, 0x77,
+	0x61, 0x6c, 0x6b, 0x65, 0x72, 0x2e, 0x46, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72, 0x69, 0x6e,
+	0x74, 0x52, 0x0b, 0x66, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x42, 0x1c,
+	0x5a, 0x1a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2f, 0x66, 0x73, 0x77, 0x61, 0x6c, 0x6b, 0x65, 0x72, 0x62, 0x06, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1123,46 +2207,61 @@ func file_proto_fswalker_fswalker_proto_rawDescGZIP() []byte {
 }
 
 var file_proto_fswalker_fswalker_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_proto_fswalker_fswalker_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_proto_fswalker_fswalker_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
 var file_proto_fswalker_fswalker_proto_goTypes = []interface{}{
 	(Notification_Severity)(0),    // 0: fswalker.Notification.Severity
 	(Fingerprint_Method)(0),       // 1: fswalker.Fingerprint.Method
 	(*Reviews)(nil),               // 2: fswalker.Reviews
 	(*Review)(nil),                // 3: fswalker.Review
-	(*ReportConfig)(nil),          // 4: fswalker.ReportConfig
-	(*Policy)(nil),                // 5: fswalker.Policy
-	(*Walk)(nil),                  // 6: fswalker.Walk
-	(*Notification)(nil),          // 7: fswalker.Notification
-	(*FileInfo)(nil),              // 8: fswalker.FileInfo
-	(*FileStat)(nil),              // 9: fswalker.FileStat
-	(*Fingerprint)(nil),           // 10: fswalker.Fingerprint
-	(*File)(nil),                  // 11: fswalker.File
-	nil,                           // 12: fswalker.Reviews.ReviewEntry
-	(*timestamppb.Timestamp)(nil), // 13: google.protobuf.Timestamp
+	(*AcceptedDiffs)(nil),         // 4: fswalker.AcceptedDiffs
+	(*AcceptedDiff)(nil),          // 5: fswalker.AcceptedDiff
+	(*ReportConfig)(nil),          // 6: fswalker.ReportConfig
+	(*PathGroup)(nil),             // 7: fswalker.PathGroup
+	(*Policy)(nil),                // 8: fswalker.Policy
+	(*Walk)(nil),                  // 9: fswalker.Walk
+	(*Notification)(nil),          // 10: fswalker.Notification
+	(*FileInfo)(nil),              // 11: fswalker.FileInfo
+	(*FileStat)(nil),              // 12: fswalker.FileStat
+	(*Fingerprint)(nil),           // 13: fswalker.Fingerprint
+	(*File)(nil),                  // 14: fswalker.File
+	(*DataStream)(nil),            // 15: fswalker.DataStream
+	nil,                           // 16: fswalker.Reviews.ReviewEntry
+	nil,                           // 17: fswalker.Policy.MaxHashFileSizeByExtensionEntry
+	nil,                           // 18: fswalker.Walk.LabelsEntry
+	(*timestamppb.Timestamp)(nil), // 19: google.protobuf.Timestamp
 }
 var file_proto_fswalker_fswalker_proto_depIdxs = []int32{
-	12, // 0: fswalker.Reviews.review:type_name -> fswalker.Reviews.ReviewEntry
-	10, // 1: fswalker.Review.fingerprint:type_name -> fswalker.Fingerprint
-	5,  // 2: fswalker.Walk.policy:type_name -> fswalker.Policy
-	11, // 3: fswalker.Walk.file:type_name -> fswalker.File
-	7,  // 4: fswalker.Walk.notification:type_name -> fswalker.Notification
-	13, // 5: fswalker.Walk.startWalk:type_name -> google.protobuf.Timestamp
-	13, // 6: fswalker.Walk.stopWalk:type_name -> google.protobuf.Timestamp
-	0,  // 7: fswalker.Notification.severity:type_name -> fswalker.Notification.Severity
-	13, // 8: fswalker.FileInfo.modified:type_name -> google.protobuf.Timestamp
-	13, // 9: fswalker.FileStat.atime:type_name -> google.protobuf.Timestamp
-	13, // 10: fswalker.FileStat.mtime:type_name -> google.protobuf.Timestamp
-	13, // 11: fswalker.FileStat.ctime:type_name -> google.protobuf.Timestamp
-	1,  // 12: fswalker.Fingerprint.method:type_name -> fswalker.Fingerprint.Method
-	8,  // 13: fswalker.File.info:type_name -> fswalker.FileInfo
-	9,  // 14: fswalker.File.stat:type_name -> fswalker.FileStat
-	10, // 15: fswalker.File.fingerprint:type_name -> fswalker.Fingerprint
-	3,  // 16: fswalker.Reviews.ReviewEntry.value:type_name -> fswalker.Review
-	17, // [17:17] is the sub-list for method output_type
-	17, // [17:17] is the sub-list for method input_type
-	17, // [17:17] is the sub-list for extension type_name
-	17, // [17:17] is the sub-list for extension extendee
-	0,  // [0:17] is the sub-list for field type_name
+	16, // 0: fswalker.Reviews.review:type_name -> fswalker.Reviews.ReviewEntry
+	13, // 1: fswalker.Review.fingerprint:type_name -> fswalker.Fingerprint
+	5,  // 2: fswalker.AcceptedDiffs.diff:type_name -> fswalker.AcceptedDiff
+	7,  // 3: fswalker.ReportConfig.pathGroups:type_name -> fswalker.PathGroup
+	17, // 4: fswalker.Policy.maxHashFileSizeByExtension:type_name -> fswalker.Policy.MaxHashFileSizeByExtensionEntry
+	8,  // 5: fswalker.Walk.policy:type_name -> fswalker.Policy
+	14, // 6: fswalker.Walk.file:type_name -> fswalker.File
+	10, // 7: fswalker.Walk.notification:type_name -> fswalker.Notification
+	13, // 8: fswalker.Walk.policyFingerprint:type_name -> fswalker.Fingerprint
+	18, // 9: fswalker.Walk.labels:type_name -> fswalker.Walk.LabelsEntry
+	19, // 10: fswalker.Walk.startWalk:type_name -> google.protobuf.Timestamp
+	19, // 11: fswalker.Walk.stopWalk:type_name -> google.protobuf.Timestamp
+	0,  // 12: fswalker.Notification.severity:type_name -> fswalker.Notification.Severity
+	19, // 13: fswalker.FileInfo.modified:type_name -> google.protobuf.Timestamp
+	19, // 14: fswalker.FileStat.atime:type_name -> google.protobuf.Timestamp
+	19, // 15: fswalker.FileStat.mtime:type_name -> google.protobuf.Timestamp
+	19, // 16: fswalker.FileStat.ctime:type_name -> google.protobuf.Timestamp
+	19, // 17: fswalker.FileStat.btime:type_name -> google.protobuf.Timestamp
+	1,  // 18: fswalker.Fingerprint.method:type_name -> fswalker.Fingerprint.Method
+	11, // 19: fswalker.File.info:type_name -> fswalker.FileInfo
+	12, // 20: fswalker.File.stat:type_name -> fswalker.FileStat
+	13, // 21: fswalker.File.fingerprint:type_name -> fswalker.Fingerprint
+	13, // 22: fswalker.File.treeDigest:type_name -> fswalker.Fingerprint
+	15, // 23: fswalker.File.dataStream:type_name -> fswalker.DataStream
+	13, // 24: fswalker.DataStream.fingerprint:type_name -> fswalker.Fingerprint
+	3,  // 25: fswalker.Reviews.ReviewEntry.value:type_name -> fswalker.Review
+	26, // [26:26] is the sub-list for method output_type
+	26, // [26:26] is the sub-list for method input_type
+	26, // [26:26] is the sub-list for extension type_name
+	26, // [26:26] is the sub-list for extension extendee
+	0,  // [0:26] is the sub-list for field type_name
 }
 
 func init() { file_proto_fswalker_fswalker_proto_init() }
@@ -1196,7 +2295,7 @@ func file_proto_fswalker_fswalker_proto_init() {
 			}
 		}
 		file_proto_fswalker_fswalker_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ReportConfig); i {
+			switch v := v.(*AcceptedDiffs); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1208,7 +2307,7 @@ func file_proto_fswalker_fswalker_proto_init() {
 			}
 		}
 		file_proto_fswalker_fswalker_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Policy); i {
+			switch v := v.(*AcceptedDiff); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1220,7 +2319,7 @@ func file_proto_fswalker_fswalker_proto_init() {
 			}
 		}
 		file_proto_fswalker_fswalker_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Walk); i {
+			switch v := v.(*ReportConfig); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1232,7 +2331,7 @@ func file_proto_fswalker_fswalker_proto_init() {
 			}
 		}
 		file_proto_fswalker_fswalker_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Notification); i {
+			switch v := v.(*PathGroup); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1244,7 +2343,7 @@ func file_proto_fswalker_fswalker_proto_init() {
 			}
 		}
 		file_proto_fswalker_fswalker_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*FileInfo); i {
+			switch v := v.(*Policy); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1256,7 +2355,7 @@ func file_proto_fswalker_fswalker_proto_init() {
 			}
 		}
 		file_proto_fswalker_fswalker_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*FileStat); i {
+			switch v := v.(*Walk); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1268,7 +2367,7 @@ func file_proto_fswalker_fswalker_proto_init() {
 			}
 		}
 		file_proto_fswalker_fswalker_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Fingerprint); i {
+			switch v := v.(*Notification); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1280,6 +2379,42 @@ func file_proto_fswalker_fswalker_proto_init() {
 			}
 		}
 		file_proto_fswalker_fswalker_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FileInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_fswalker_fswalker_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FileStat); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_fswalker_fswalker_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Fingerprint); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_fswalker_fswalker_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*File); i {
 			case 0:
 				return &v.state
@@ -1291,14 +2426,27 @@ func file_proto_fswalker_fswalker_proto_init() {
 				return nil
 			}
 		}
+		file_proto_fswalker_fswalker_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DataStream); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
+	file_proto_fswalker_fswalker_proto_msgTypes[12].OneofWrappers = []interface{}{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_proto_fswalker_fswalker_proto_rawDesc,
 			NumEnums:      2,
-			NumMessages:   11,
+			NumMessages:   17,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -15,21 +15,29 @@
 package fswalker
 
 import (
+	"bufio"
+	"bytes"
 	"crypto/sha256"
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/google/go-cmp/cmp"
 	"golang.org/x/exp/slices"
 	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/encoding/protowire"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
 	tspb "google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/google/fswalker/internal/metrics"
@@ -38,6 +46,9 @@ import (
 
 const (
 	timeReportFormat = "2006-01-02 15:04:05 MST"
+	// timeReportFormatNano is used instead of timeReportFormat for timestamps
+	// which only differ in their fractions of a second and would look the same.
+	timeReportFormatNano = "2006-01-02 15:04:05.000000000 MST"
 )
 
 // WalkFile contains info about a Walk file.
@@ -47,6 +58,61 @@ type WalkFile struct {
 	Fingerprint *fspb.Fingerprint
 }
 
+// severityRank orders notification severities from least to most severe.
+// It is kept explicit rather than relying on the numeric enum values.
+var severityRank = map[fspb.Notification_Severity]int{
+	fspb.Notification_UNKNOWN: 0,
+	fspb.Notification_INFO:    1,
+	fspb.Notification_WARNING: 2,
+	fspb.Notification_ERROR:   3,
+}
+
+// Notifications returns all notifications of the Walk which are at least as severe as minSeverity.
+func (w *WalkFile) Notifications(minSeverity fspb.Notification_Severity) []*fspb.Notification {
+	if w == nil || w.Walk == nil {
+		return nil
+	}
+	var ns []*fspb.Notification
+	for _, n := range w.Walk.Notification {
+		if severityRank[n.Severity] >= severityRank[minSeverity] {
+			ns = append(ns, n)
+		}
+	}
+	return ns
+}
+
+// HasErrors returns true if the Walk recorded at least one notification with ERROR severity.
+func (w *WalkFile) HasErrors() bool {
+	return len(w.Notifications(fspb.Notification_ERROR)) > 0
+}
+
+// FilterFiles returns a shallow copy of the WalkFile whose Walk only contains the files
+// for which pred returns true. All other fields of the Walk are kept as is; Path and
+// Fingerprint still refer to the original file.
+func (w *WalkFile) FilterFiles(pred func(*fspb.File) bool) *WalkFile {
+	walk := &fspb.Walk{}
+	dst := walk.ProtoReflect()
+	w.Walk.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Number() != fileFieldNumber {
+			dst.Set(fd, v)
+		}
+		return true
+	})
+	for _, f := range w.Walk.File {
+		if pred(f) {
+			walk.File = append(walk.File, f)
+		}
+	}
+	return &WalkFile{
+		Path:        w.Path,
+		Walk:        walk,
+		Fingerprint: w.Fingerprint,
+	}
+}
+
+// fileFieldNumber is the field number of Walk.File.
+var fileFieldNumber = (&fspb.Walk{}).ProtoReflect().Descriptor().Fields().ByName("file").Number()
+
 // Report contains the result of the comparison between two Walks.
 type Report struct {
 	Added      []ActionData
@@ -56,25 +122,95 @@ type Report struct {
 	Counter    *metrics.Counter
 	WalkBefore *fspb.Walk
 	WalkAfter  *fspb.Walk
+
+	// Baseline is true if there was no before Walk to compare against.
+	// All files of the after Walk are then reported as Added to create an initial inventory.
+	Baseline bool
+
+	// WorldWritable lists the files writable by others in the after Walk if the
+	// report config sets reportWorldWritable. They don't count as changes.
+	WorldWritable []ActionData
+
+	// PermissionChanges lists the Modified files of which only permissions or
+	// ownership changed if the report config sets reportPermissionChanges.
+	PermissionChanges []ActionData
+
+	// KnownBad lists the files of the after Walk whose fingerprint is in the
+	// known hashes database of the Reporter. Diff names the matching hash.
+	KnownBad []ActionData
+
+	// Warnings are problems with the compared Walks which don't prevent the
+	// comparison but likely make it meaningless, e.g. Walks in the wrong order.
+	Warnings []string
+
+	// CriticalChangeDetected is true if any of the critical paths of the report
+	// config was modified or removed.
+	CriticalChangeDetected bool
+
+	// DirectorySizeChanges lists the directories whose aggregate size changed if
+	// both Walks were taken with computeDirectorySizes, the largest absolute change
+	// first. Fields holds the "aggregate-size" diff. They don't count as changes.
+	DirectorySizeChanges []ActionData
+
+	// pathTransform is the PathTransform of the Reporter, which Invert needs to
+	// recompute the warnings.
+	pathTransform func(string) string
 }
 
-// Empty returns true if there are no additions, no deletions, no modifications and no errors.
+// Empty returns true if there are no additions, no deletions, no modifications,
+// no errors and no files with known bad hashes.
 func (r *Report) Empty() bool {
-	return len(r.Added)+len(r.Deleted)+len(r.Modified)+len(r.Errors) == 0
+	return r.numDiffs() == 0
 }
 
 // ActionData contains a diff between two files in different Walks.
 type ActionData struct {
 	Before *fspb.File
 	After  *fspb.File
-	Diff   string
+	// Diff is the human readable diff, one changed field per line.
+	Diff string
+	// Fields is the structured form of Diff.
+	Fields []FieldDiff
 	Err    error
+	// Critical is set if the file is below one of the criticalPaths of the config.
+	Critical bool
 }
 
+// ReportConfigEnvVar is the environment variable ReporterFromConfigFile reads the
+// config from if no path is given, e.g. in containers where mounting files is awkward.
+const ReportConfigEnvVar = "FSWALKER_REPORT_CONFIG"
+
 // ReporterFromConfigFile creates a new Reporter based on a config path.
+// If path is empty, the config is read from the ReportConfigEnvVar environment variable.
 func ReporterFromConfigFile(path string, verbose bool) (*Reporter, error) {
+	var b []byte
+	if path == "" {
+		config, ok := os.LookupEnv(ReportConfigEnvVar)
+		if !ok {
+			return nil, fmt.Errorf("no report config file given and %s is not set", ReportConfigEnvVar)
+		}
+		b = []byte(config)
+		path = "$" + ReportConfigEnvVar
+	} else {
+		var err error
+		if b, err = readFile(path); err != nil {
+			return nil, err
+		}
+	}
+	r, err := ReporterFromConfigBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	r.configPath = path
+	r.Verbose = verbose
+	return r, nil
+}
+
+// ReporterFromConfigBytes creates a new Reporter based on a config in the format
+// of report config files, e.g. embedded in the binary.
+func ReporterFromConfigBytes(b []byte) (*Reporter, error) {
 	config := &fspb.ReportConfig{}
-	md, err := toml.DecodeFile(path, config)
+	md, err := toml.Decode(string(b), config)
 	if err != nil {
 		return nil, err
 	}
@@ -91,11 +227,15 @@ func ReporterFromConfigFile(path string, verbose bool) (*Reporter, error) {
 		return nil, errors.New(sb.String())
 	}
 
-	return &Reporter{
-		config:     config,
-		configPath: path,
-		Verbose:    verbose,
-	}, nil
+	r := &Reporter{config: config}
+	if _, err := r.pathGroups(); err != nil {
+		return nil, err
+	}
+	if _, err := r.mtimeGranularity(); err != nil {
+		return nil, err
+	}
+	r.displayLocation()
+	return r, nil
 }
 
 // Reporter compares two Walks against each other based on the config provided
@@ -107,6 +247,83 @@ type Reporter struct {
 
 	// Verbose, when true, makes Reporter print more information for all diffs found.
 	Verbose bool
+
+	// VerboseProto, when true, makes PrintDiffSummary print the complete before and
+	// after File of every modified file in text format.
+	VerboseProto bool
+
+	// EncryptionKey is the key used to decrypt encrypted Walks.
+	EncryptionKey []byte
+
+	// MaxDiffs limits the number of entries PrintDiffSummary prints per section.
+	// The Report itself is never truncated. Zero means no limit.
+	MaxDiffs int
+
+	// KnownHashes, if set, is checked for the fingerprints of all files of the
+	// after Walk by Compare. Matches are reported in Report.KnownBad.
+	KnownHashes KnownHashes
+
+	// PathTransform, if set, is applied to the paths of the files of both Walks
+	// before Compare pairs them up, e.g. to strip version numbers from
+	// directory names. Reported files carry the transformed paths and excludes
+	// are matched against them. Files of a Walk mapped to the same path are
+	// reported in Report.Warnings, as only one of them is compared.
+	PathTransform func(string) string
+
+	// UpgradeWalks, when true, makes ReadWalk migrate Walks written by older versions
+	// of the walker with UpgradeWalk, so historical baselines stay comparable.
+	UpgradeWalks bool
+
+	// IgnoreHashMethodChanges, when true, makes Diff skip comparing the fingerprints
+	// of files hashed with different methods in the two Walks, e.g. while migrating
+	// to another hash algorithm, as their values can't be compared. Compare warns
+	// about the number of such files instead of reporting them as modified.
+	IgnoreHashMethodChanges bool
+
+	// CacheDir, if set, is the directory CompareWalkFiles caches Reports in, so
+	// repeated comparisons of the same pair of Walks don't diff them again.
+	// Cached Reports aren't verified, so it needs to be writable only by the
+	// current user, who also needs to own it.
+	CacheDir string
+
+	// location is the location timestamps are printed in, loaded once by displayLocation.
+	// locationErr is why the displayTimezone of the config couldn't be loaded, if so.
+	location     *time.Location
+	locationErr  error
+	locationOnce sync.Once
+}
+
+// loadDisplayLocation returns the location of the displayTimezone tz, UTC if it is unset.
+func loadDisplayLocation(tz string) (*time.Location, error) {
+	if tz == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("unknown displayTimezone %q: %v", tz, err)
+	}
+	return loc, nil
+}
+
+// displayLocation returns the location timestamps are printed in: the displayTimezone
+// of the report config, or UTC if it is unset or unknown. Compare warns about
+// unknown zones with displayLocationWarning.
+func (r *Reporter) displayLocation() *time.Location {
+	r.locationOnce.Do(func() {
+		if r.location, r.locationErr = loadDisplayLocation(r.config.GetDisplayTimezone()); r.locationErr != nil {
+			r.location = time.UTC
+		}
+	})
+	return r.location
+}
+
+// displayLocationWarning returns a warning if the displayTimezone of the config is
+// unknown and timestamps are printed in UTC instead, or "" if it is fine.
+func (r *Reporter) displayLocationWarning() string {
+	if r.displayLocation(); r.locationErr == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v, timestamps are shown in UTC instead", r.locationErr)
 }
 
 func (r *Reporter) verifyFingerprint(goodFp *fspb.Fingerprint, checkFp *fspb.Fingerprint) error {
@@ -135,21 +352,144 @@ func (r *Reporter) fingerprint(b []byte) *fspb.Fingerprint {
 
 // ReadWalk reads a file as marshaled proto in fspb.Walk format.
 func (r *Reporter) ReadWalk(path string) (*WalkFile, error) {
-	b, err := os.ReadFile(path)
+	f, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
-	p := &fspb.Walk{}
-	if err := proto.Unmarshal(b, p); err != nil {
-		return nil, err
+	defer f.Close()
+	return r.ReadWalkFrom(f, path)
+}
+
+// ReadWalkFrom reads a marshaled proto in fspb.Walk format from src, e.g. from stdin.
+// The fingerprint is built over the bytes as they are streamed. path is recorded
+// in the returned WalkFile and used in messages.
+func (r *Reporter) ReadWalkFrom(src io.Reader, path string) (*WalkFile, error) {
+	var rd io.Reader = bufio.NewReader(src)
+	if header, _ := rd.(*bufio.Reader).Peek(len(encryptedWalkHeader)); isEncryptedWalk(header) {
+		if r.EncryptionKey == nil {
+			return nil, fmt.Errorf("walk %q is encrypted but no key is configured", path)
+		}
+		data, err := io.ReadAll(rd)
+		if err != nil {
+			return nil, err
+		}
+		walkBytes, err := decryptWalk(data, r.EncryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read walk %q: %v", path, err)
+		}
+		// The fingerprint is built over the plaintext so it matches the unencrypted Walk.
+		rd = bytes.NewReader(walkBytes)
+	}
+	p, fp, err := decodeWalk(rd)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode walk %q: %v", path, err)
+	}
+	if r.UpgradeWalks {
+		if err := UpgradeWalk(p); err != nil {
+			return nil, fmt.Errorf("unable to upgrade walk %q: %v", path, err)
+		}
 	}
-	fp := r.fingerprint(b)
 	if r.Verbose {
 		fmt.Printf("Loaded file %q with fingerprint: %s(%s)\n", path, fp.Method, fp.Value)
 	}
 	return &WalkFile{Path: path, Walk: p, Fingerprint: fp}, nil
 }
 
+// decodeWalk reads a marshaled Walk from rd and returns it along with the fingerprint
+// over all bytes read. The Walk is decoded one top-level field at a time, so at most a
+// single field (e.g. one File) is held in its marshaled form, and the fingerprint is
+// built in the same pass.
+func decodeWalk(rd io.Reader) (*fspb.Walk, *fspb.Fingerprint, error) {
+	h := sha256.New()
+	br := bufio.NewReader(io.TeeReader(rd, h))
+	walk := &fspb.Walk{}
+	var buf []byte
+	for {
+		var err error
+		if _, buf, err = readWalkField(br, buf, nil); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, err
+		}
+		// Merging a single field appends repeated fields and overwrites scalar ones,
+		// exactly like unmarshaling the whole message at once.
+		if err := (proto.UnmarshalOptions{Merge: true}).Unmarshal(buf, walk); err != nil {
+			return nil, nil, err
+		}
+	}
+	return walk, &fspb.Fingerprint{
+		Method: fspb.Fingerprint_SHA256,
+		Value:  fmt.Sprintf("%x", h.Sum(nil)),
+	}, nil
+}
+
+// readWalkField reads the next top-level field of a marshaled Walk from br and returns
+// its number along with buf[:0] holding the field in its marshaled form. If skip returns
+// true for the field number of a length-delimited field, its value is discarded without
+// being buffered and buf is returned empty. It returns io.EOF if there are no more fields.
+func readWalkField(br *bufio.Reader, buf []byte, skip func(protowire.Number) bool) (protowire.Number, []byte, error) {
+	tag, err := binary.ReadUvarint(br)
+	if err != nil {
+		return 0, nil, err
+	}
+	num, typ := protowire.DecodeTag(tag)
+	buf = protowire.AppendVarint(buf[:0], tag)
+
+	switch typ {
+	case protowire.VarintType:
+		v, err := binary.ReadUvarint(br)
+		if err != nil {
+			return 0, nil, err
+		}
+		buf = protowire.AppendVarint(buf, v)
+	case protowire.Fixed32Type, protowire.Fixed64Type:
+		n := 4
+		if typ == protowire.Fixed64Type {
+			n = 8
+		}
+		if buf, err = readN(br, buf, n); err != nil {
+			return 0, nil, err
+		}
+	case protowire.BytesType:
+		n, err := binary.ReadUvarint(br)
+		if err != nil {
+			return 0, nil, err
+		}
+		if n > math.MaxInt32 {
+			return 0, nil, fmt.Errorf("field length %d too large", n)
+		}
+		if skip != nil && skip(num) {
+			if _, err := br.Discard(int(n)); err != nil {
+				if err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				return 0, nil, err
+			}
+			return num, buf[:0], nil
+		}
+		buf = protowire.AppendVarint(buf, n)
+		if buf, err = readN(br, buf, int(n)); err != nil {
+			return 0, nil, err
+		}
+	default:
+		return 0, nil, fmt.Errorf("unsupported wire type %d", typ)
+	}
+	return num, buf, nil
+}
+
+// readN appends exactly n bytes read from rd to buf.
+func readN(rd io.Reader, buf []byte, n int) ([]byte, error) {
+	l := len(buf)
+	buf = append(buf, make([]byte, n)...)
+	if _, err := io.ReadFull(rd, buf[l:]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return buf, nil
+}
+
 // ReadLatestWalk looks for the latest Walk in a given folder for a given hostname.
 // It returns the file path it ended up reading, the Walk it read and the fingerprint for it.
 func (r *Reporter) ReadLatestWalk(hostname, walkPath string) (*WalkFile, error) {
@@ -161,21 +501,44 @@ func (r *Reporter) ReadLatestWalk(hostname, walkPath string) (*WalkFile, error)
 	if len(names) == 0 {
 		return nil, fmt.Errorf("no files found for %q", matchpath)
 	}
-	slices.Sort(names) // the assumption is that the file names are such that the latest is last.
+	sortWalkFilenames(names)
 	return r.ReadWalk(names[len(names)-1])
 }
 
-// ReadLastGoodWalk reads the designated review file and attempts to find an entry matching
-// the given hostname. Note that if it can't find one but the review file itself was read
-// successfully, it will return an empty Walk and no error.
+// sortWalkFilenames sorts Walk file names chronologically by the time encoded in them.
+// Names which can't be parsed are sorted first so they are never picked as the latest.
+func sortWalkFilenames(names []string) {
+	times := make(map[string]time.Time, len(names))
+	for _, n := range names {
+		_, t, _ := ParseWalkFilename(n)
+		times[n] = t
+	}
+	slices.SortStableFunc(names, func(a, b string) bool {
+		if !times[a].Equal(times[b]) {
+			return times[a].Before(times[b])
+		}
+		return a < b
+	})
+}
+
+// ReadLastGoodWalk reads the designated review files in order and attempts to find an entry
+// matching the given hostname. Note that if it can't find one but the review files themselves
+// were read successfully, it will return an empty Walk and no error.
 // It returns the file path it ended up reading, the Walk it read and the fingerprint for it.
-func (r *Reporter) ReadLastGoodWalk(hostname, reviewFile string) (*WalkFile, error) {
-	reviews := &fspb.Reviews{}
-	if err := readTextProto(reviewFile, reviews); err != nil {
-		return nil, err
+func (r *Reporter) ReadLastGoodWalk(hostname string, reviewFiles ...string) (*WalkFile, error) {
+	var rvws *fspb.Review
+	var reviewFile string
+	for _, path := range reviewFiles {
+		reviews, err := r.ReadReviews(path)
+		if err != nil {
+			return nil, err
+		}
+		if review, ok := reviews.Review[hostname]; ok {
+			rvws, reviewFile = review, path
+			break
+		}
 	}
-	rvws, ok := reviews.Review[hostname]
-	if !ok {
+	if rvws == nil {
 		return nil, nil
 	}
 	wf, err := r.ReadWalk(rvws.WalkReference)
@@ -209,61 +572,302 @@ func (r *Reporter) sanityCheck(before, after *fspb.Walk) error {
 		beforeTs := before.StopWalk.AsTime()
 		afterTs := after.StartWalk.AsTime()
 		if beforeTs.After(afterTs) {
+			if walkOrderWarning(before, after) != "" {
+				return fmt.Errorf("earlier Walk indicates it ended (%s) after later Walk (%s) has started, the Walks are likely passed in the wrong order", beforeTs, afterTs)
+			}
 			return fmt.Errorf("earlier Walk indicates it ended (%s) after later Walk (%s) has started", beforeTs, afterTs)
 		}
 	}
 	return nil
 }
 
-func (r *Reporter) timestampDiff(bt, at *tspb.Timestamp) (string, error) {
+// walkOrderWarning returns a warning if the after Walk started before the before Walk.
+// Unlike an overlap of the Walks this is still reported when the before Walk
+// didn't record when it stopped.
+func walkOrderWarning(before, after *fspb.Walk) string {
+	if before == nil || before.StartWalk == nil || after.StartWalk == nil {
+		return ""
+	}
+	beforeTs := before.StartWalk.AsTime()
+	afterTs := after.StartWalk.AsTime()
+	if !afterTs.Before(beforeTs) {
+		return ""
+	}
+	return fmt.Sprintf("the after Walk (started %s) is older than the before Walk (started %s), the Walks are likely passed in the wrong order", afterTs, beforeTs)
+}
+
+// incompleteWalkWarnings returns a warning for each of the Walks which is incomplete,
+// as the files it is missing are reported as deleted or added.
+func incompleteWalkWarnings(before, after *fspb.Walk) []string {
+	var warnings []string
+	for _, w := range []struct {
+		name string
+		walk *fspb.Walk
+	}{{"before", before}, {"after", after}} {
+		if w.walk.GetIncomplete() {
+			warnings = append(warnings, fmt.Sprintf("the %s Walk is incomplete as it was interrupted, files it didn't reach are reported as deleted or added", w.name))
+		}
+	}
+	return warnings
+}
+
+// duplicatePathWarnings returns a warning for each of the Walks recording a path
+// more than once after applying transform, as only one of the entries of such a
+// path is compared.
+func duplicatePathWarnings(before, after *fspb.Walk, transform func(string) string) []string {
+	var warnings []string
+	for _, w := range []struct {
+		name string
+		walk *fspb.Walk
+	}{{"before", before}, {"after", after}} {
+		dups := duplicatePaths(w.walk.GetFile(), transform)
+		if len(dups) == 0 {
+			continue
+		}
+		if a, b, to, ok := mergedPath(w.walk.GetFile(), transform); ok {
+			warnings = append(warnings, fmt.Sprintf("PathTransform maps different files of the %s Walk to the same path (e.g. %q and %q to %q), only the last file of each such path is compared", w.name, a, b, to))
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("the %s Walk records %d paths more than once (e.g. %q), only the last entry of each is compared", w.name, len(dups), dups[0]))
+	}
+	return warnings
+}
+
+// mergedPath returns the first two different recorded paths of files which
+// transform maps to the same path, and that path.
+func mergedPath(files []*fspb.File, transform func(string) string) (string, string, string, bool) {
+	if transform == nil {
+		return "", "", "", false
+	}
+	recorded := make(map[string]string, len(files))
+	for _, f := range files {
+		p := NormalizePath(f.Path, f.Info.GetIsDir())
+		t := NormalizePath(transform(f.Path), f.Info.GetIsDir())
+		if prev, ok := recorded[t]; !ok {
+			recorded[t] = p
+		} else if prev != p {
+			return prev, p, t, true
+		}
+	}
+	return "", "", "", false
+}
+
+// FieldDiff is a single changed field of a file between two Walks.
+type FieldDiff struct {
+	// Field is the name of the changed field, e.g. "size" or "mtime".
+	Field string
+	// Before is the formatted value in the earlier Walk.
+	Before string
+	// After is the formatted value in the later Walk.
+	After string
+}
+
+// quotedDiffFields are fields whose values are quoted in the human readable diff.
+var quotedDiffFields = map[string]bool{
+	"name":              true,
+	"bind-mount-source": true,
+}
+
+// highSeverityDiffFields are fields whose change is a strong sign of tampering.
+var highSeverityDiffFields = map[string]bool{
+	"type":               true,
+	"truncated-to-empty": true,
+	"perm-other":         true,
+	"perm-loosened":      true,
+	"entropy-increase":   true,
+}
+
+// permissionDiffFields are fields which change with permissions or ownership.
+// The change time is updated by chmod and chown as well.
+var permissionDiffFields = map[string]bool{
+	"mode":          true,
+	"perm-other":    true,
+	"perm-loosened": true,
+	"uid":           true,
+	"gid":           true,
+	"ctime":         true,
+}
+
+// isPermissionChange returns true if diffs only consist of permission or ownership changes.
+func isPermissionChange(diffs []FieldDiff) bool {
+	changed := false
+	for _, d := range diffs {
+		if !permissionDiffFields[d.Field] {
+			return false
+		}
+		if d.Field != "ctime" {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// HighSeverity returns true if the change deserves special attention.
+func (d FieldDiff) HighSeverity() bool {
+	return highSeverityDiffFields[d.Field]
+}
+
+// String returns the human readable representation of the diff.
+func (d FieldDiff) String() string {
+	before, after := d.values()
+	return fmt.Sprintf("%s: %s => %s", d.Field, before, after)
+}
+
+// values returns the before and after values formatted for display.
+func (d FieldDiff) values() (before, after string) {
+	if quotedDiffFields[d.Field] {
+		return strconv.Quote(d.Before), strconv.Quote(d.After)
+	}
+	return d.Before, d.After
+}
+
+// mtimeGranularity returns the parsed mtimeGranularity of the config, 0 if it is unset.
+func (r *Reporter) mtimeGranularity() (time.Duration, error) {
+	g := r.config.GetMtimeGranularity()
+	if g == "" {
+		return 0, nil
+	}
+	granularity, err := time.ParseDuration(g)
+	if err != nil {
+		return 0, fmt.Errorf("invalid mtimeGranularity %q: %v", g, err)
+	}
+	return granularity, nil
+}
+
+// timestampDiff returns the diff of the named timestamp field or nil if it didn't change.
+// The timestamps are truncated to the configured mtimeGranularity first. It applies to
+// change and birth times too, which file systems store at the same granularity.
+func (r *Reporter) timestampDiff(field string, bt, at *tspb.Timestamp) (*FieldDiff, error) {
 	if bt == nil && at == nil {
-		return "", nil
+		return nil, nil
 	}
 	bmt := bt.AsTime()
 	amt := at.AsTime()
+	granularity, err := r.mtimeGranularity()
+	if err != nil {
+		return nil, err
+	}
+	bmt = bmt.Truncate(granularity)
+	amt = amt.Truncate(granularity)
 	if bmt.Equal(amt) {
-		return "", nil
+		return nil, nil
+	}
+	loc := r.displayLocation()
+	bmt, amt = bmt.In(loc), amt.In(loc)
+	format := timeReportFormat
+	if bmt.Format(format) == amt.Format(format) {
+		format = timeReportFormatNano
 	}
-	return fmt.Sprintf("%s => %s", bmt.Format(timeReportFormat), amt.Format(timeReportFormat)), nil
+	return &FieldDiff{
+		Field:  field,
+		Before: bmt.Format(format),
+		After:  amt.Format(format),
+	}, nil
 }
 
-// diffFileStat compares the FileInfo proto of two files and reports all relevant diffs as human readable strings.
-func (r *Reporter) diffFileInfo(fib, fia *fspb.FileInfo) ([]string, error) {
-	var diffs []string
+// diffFileInfo compares the FileInfo proto of two files and reports all relevant diffs.
+func (r *Reporter) diffFileInfo(fib, fia *fspb.FileInfo) ([]FieldDiff, error) {
+	var diffs []FieldDiff
 
 	if fib == nil && fia == nil {
 		return diffs, nil
 	}
 
 	if fib.Name != fia.Name {
-		diffs = append(diffs, fmt.Sprintf("name: %q => %q", fib.Name, fia.Name))
+		diffs = append(diffs, FieldDiff{"name", fib.Name, fia.Name})
 	}
 	if fib.Size != fia.Size {
-		diffs = append(diffs, fmt.Sprintf("size: %d => %d", fib.Size, fia.Size))
+		diffs = append(diffs, FieldDiff{"size", fmt.Sprint(fib.Size), fmt.Sprint(fia.Size)})
+	}
+	// A file emptied in place is easy to miss among size changes but typical for log tampering.
+	if fib.Size > 0 && fia.Size == 0 && !fib.IsDir && !fia.IsDir {
+		diffs = append(diffs, FieldDiff{"truncated-to-empty", fmt.Sprint(fib.Size), "0"})
 	}
 	if fib.Mode != fia.Mode {
-		diffs = append(diffs, fmt.Sprintf("mode: %d => %d", fib.Mode, fia.Mode))
+		diffs = append(diffs, FieldDiff{"mode", fmt.Sprint(fib.Mode), fmt.Sprint(fia.Mode)})
+	}
+	if tb, ta := fileType(fib.Mode), fileType(fia.Mode); tb != ta {
+		diffs = append(diffs, FieldDiff{"type", tb, ta})
 	}
 	if fib.IsDir != fia.IsDir {
-		diffs = append(diffs, fmt.Sprintf("is_dir: %t => %t", fib.IsDir, fia.IsDir))
+		diffs = append(diffs, FieldDiff{"is_dir", fmt.Sprint(fib.IsDir), fmt.Sprint(fia.IsDir)})
+	}
+	if r.config.GetFlagWorldWritable() && !worldWritable(fib.Mode) && worldWritable(fia.Mode) {
+		diffs = append(diffs, FieldDiff{"perm-other", "-w", "+w"})
+	}
+	if r.config.GetFlagPermissionLoosening() && permissionsLoosened(fib.Mode, fia.Mode) {
+		diffs = append(diffs, FieldDiff{"perm-loosened", fmt.Sprintf("%#o", unixPerm(fib.Mode)), fmt.Sprintf("%#o", unixPerm(fia.Mode))})
 	}
 
 	// Ignore if both timestamps are nil.
 	if fib.Modified == nil && fia.Modified == nil {
 		return diffs, nil
 	}
-	diff, err := r.timestampDiff(fib.Modified, fia.Modified)
+	diff, err := r.timestampDiff("mtime", fib.Modified, fia.Modified)
 	if err != nil {
 		return diffs, fmt.Errorf("unable to convert timestamps for %q: %v", fib.Name, err)
 	}
-	if diff != "" {
-		diffs = append(diffs, fmt.Sprintf("mtime: %s", diff))
+	if diff != nil {
+		diffs = append(diffs, *diff)
 	}
 
 	return diffs, nil
 }
 
-// diffFileStat compares the FileStat proto of two files and reports all relevant diffs as human readable strings.
+// worldWritable returns true if mode allows others to write to the file.
+// Symlinks are ignored as their permissions are meaningless.
+func worldWritable(mode uint32) bool {
+	m := os.FileMode(mode)
+	return m&0002 != 0 && m&os.ModeSymlink == 0
+}
+
+// permissionsLoosened returns true if the permissions of after grant anything the
+// ones of before didn't. Symlinks are ignored as their permissions are meaningless.
+func permissionsLoosened(before, after uint32) bool {
+	if os.FileMode(before)&os.ModeSymlink != 0 || os.FileMode(after)&os.ModeSymlink != 0 {
+		return false
+	}
+	return unixPerm(after)&^unixPerm(before) != 0
+}
+
+// unixPerm returns the permission bits of mode including setuid and setgid in
+// their traditional octal notation, e.g. 04755.
+func unixPerm(mode uint32) uint32 {
+	m := os.FileMode(mode)
+	perm := uint32(m.Perm())
+	if m&os.ModeSetuid != 0 {
+		perm |= 04000
+	}
+	if m&os.ModeSetgid != 0 {
+		perm |= 02000
+	}
+	return perm
+}
+
+// fileType returns the name of the file type encoded in mode.
+func fileType(mode uint32) string {
+	switch m := os.FileMode(mode); {
+	case m.IsRegular():
+		return "regular"
+	case m.IsDir():
+		return "directory"
+	case m&os.ModeSymlink != 0:
+		return "symlink"
+	case m&os.ModeNamedPipe != 0:
+		return "named-pipe"
+	case m&os.ModeSocket != 0:
+		return "socket"
+	case m&os.ModeCharDevice != 0:
+		return "char-device"
+	case m&os.ModeDevice != 0:
+		return "device"
+	default:
+		return "irregular"
+	}
+}
+
+// diffFileStat compares the FileStat proto of two files and reports all relevant diffs.
+// The birth time is only compared if both files have one.
 // The following fields are ignored as they are not regarded as relevant in this context:
 //   - atime
 //   - inode, nlink, dev, rdev
@@ -274,97 +878,385 @@ func (r *Reporter) diffFileInfo(fib, fia *fspb.FileInfo) ([]string, error) {
 //   - mode
 //   - size
 //   - mtime
-func (r *Reporter) diffFileStat(fsb, fsa *fspb.FileStat) ([]string, error) {
-	var diffs []string
+func (r *Reporter) diffFileStat(fsb, fsa *fspb.FileStat) ([]FieldDiff, error) {
+	var diffs []FieldDiff
 
 	if fsb == nil && fsa == nil {
 		return diffs, nil
 	}
 
 	if fsb.Uid != fsa.Uid {
-		diffs = append(diffs, fmt.Sprintf("uid: %d => %d", fsb.Uid, fsa.Uid))
+		diffs = append(diffs, FieldDiff{"uid", fmt.Sprint(fsb.Uid), fmt.Sprint(fsa.Uid)})
 	}
 	if fsb.Gid != fsa.Gid {
-		diffs = append(diffs, fmt.Sprintf("gid: %d => %d", fsb.Gid, fsa.Gid))
+		diffs = append(diffs, FieldDiff{"gid", fmt.Sprint(fsb.Gid), fmt.Sprint(fsa.Gid)})
+	}
+	if r.config.GetReportInodeChanges() && fsb.Inode != fsa.Inode {
+		diffs = append(diffs, FieldDiff{"inode", fmt.Sprint(fsb.Inode), fmt.Sprint(fsa.Inode)})
+	}
+
+	// Only compare birth times if both Walks collected them. A changed birth time
+	// means the file was deleted and recreated, even if its mtime was preserved.
+	if fsb.Btime != nil && fsa.Btime != nil {
+		bdiff, err := r.timestampDiff("btime", fsb.Btime, fsa.Btime)
+		if err != nil {
+			return diffs, fmt.Errorf("unable to convert timestamps: %v", err)
+		}
+		if bdiff != nil {
+			diffs = append(diffs, *bdiff)
+		}
 	}
 
 	// Ignore ctime changes if mtime equals to ctime or if both are nil.
-	cdiff, cerr := r.timestampDiff(fsb.Ctime, fsa.Ctime)
+	cdiff, cerr := r.timestampDiff("ctime", fsb.Ctime, fsa.Ctime)
 	if cerr != nil {
 		return diffs, fmt.Errorf("unable to convert timestamps: %v", cerr)
 	}
-	if cdiff == "" {
+	if cdiff == nil {
 		return diffs, nil
 	}
-	mdiff, merr := r.timestampDiff(fsb.Mtime, fsa.Mtime)
+	mdiff, merr := r.timestampDiff("mtime", fsb.Mtime, fsa.Mtime)
 	if merr != nil {
 		return diffs, fmt.Errorf("unable to convert timestamps: %v", merr)
 	}
-	if mdiff != cdiff {
-		diffs = append(diffs, fmt.Sprintf("ctime: %s", cdiff))
+	if mdiff == nil || mdiff.Before != cdiff.Before || mdiff.After != cdiff.After {
+		diffs = append(diffs, *cdiff)
 	}
 
 	return diffs, nil
 }
 
-// diffFile compares two File entries of a Walk and shows the diffs between the two.
-func (r *Reporter) diffFile(before, after *fspb.File) (string, error) {
+// Diff compares two File entries of a Walk and returns the changed fields,
+// sorted the same way as the lines of the human readable diff.
+func (r *Reporter) Diff(before, after *fspb.File) ([]FieldDiff, error) {
 	if before.Version != after.Version {
-		return "", fmt.Errorf("file format versions don't match: before(%d) != after(%d)", before.Version, after.Version)
+		return nil, fmt.Errorf("file format versions don't match: before(%d) != after(%d)", before.Version, after.Version)
 	}
 	if before.Path != after.Path {
-		return "", fmt.Errorf("file paths don't match: before(%q) != after(%q)", before.Path, after.Path)
+		return nil, fmt.Errorf("file paths don't match: before(%q) != after(%q)", before.Path, after.Path)
 	}
 
-	var diffs []string
+	var diffs []FieldDiff
 	// Ensure fingerprints are the same - if there was one before. Do not show a diff if there's a new fingerprint.
-	if len(before.Fingerprint) > 0 {
+	if len(before.Fingerprint) > 0 && !r.config.GetMetadataOnly() {
 		fb := before.Fingerprint[0]
-		if len(after.Fingerprint) == 0 {
-			diffs = append(diffs, fmt.Sprintf("fingerprint: %s => ", fb.Value))
-		} else {
+		switch {
+		case len(after.Fingerprint) == 0:
+			diffs = append(diffs, FieldDiff{"fingerprint", fb.Value, ""})
+		case r.IgnoreHashMethodChanges && hashMethodChanged(before, after):
+			// Values of different methods can't be compared, Compare warns about it instead.
+		default:
 			fa := after.Fingerprint[0]
 			if fb.Method != fa.Method {
-				diffs = append(diffs, fmt.Sprintf("fingerprint-method: %s => %s", fb.Method, fa.Method))
+				diffs = append(diffs, FieldDiff{"fingerprint-method", fb.Method.String(), fa.Method.String()})
 			}
 			if fb.Value != fa.Value {
-				diffs = append(diffs, fmt.Sprintf("fingerprint: %s => %s", fb.Value, fa.Value))
+				diffs = append(diffs, FieldDiff{"fingerprint", fb.Value, fa.Value})
 			}
 		}
 	}
+	// Only compare tree digests if both Walks computed them.
+	if !r.config.GetMetadataOnly() && before.TreeDigest != nil && after.TreeDigest != nil && before.TreeDigest.Value != after.TreeDigest.Value {
+		diffs = append(diffs, FieldDiff{"tree-digest", before.TreeDigest.Value, after.TreeDigest.Value})
+	}
+	if before.BindMountSource != after.BindMountSource {
+		diffs = append(diffs, FieldDiff{"bind-mount-source", before.BindMountSource, after.BindMountSource})
+	}
+	// Only compare entry counts if both Walks recorded them.
+	if before.EntryCount != nil && after.EntryCount != nil && before.GetEntryCount() != after.GetEntryCount() {
+		diffs = append(diffs, FieldDiff{"entries", fmt.Sprint(before.GetEntryCount()), fmt.Sprint(after.GetEntryCount())})
+	}
+	// Compare only reports data streams if both Walks collected them.
+	if !r.config.GetMetadataOnly() {
+		if bs, as := dataStreamsSummary(before.DataStream), dataStreamsSummary(after.DataStream); bs != as {
+			diffs = append(diffs, FieldDiff{"data-streams", bs, as})
+		}
+	}
+	// Content turning random hints at files encrypted in place, e.g. by ransomware.
+	// Only compare entropies if both Walks recorded them.
+	if threshold := r.config.GetFlagEntropyIncrease(); threshold > 0 && !r.config.GetMetadataOnly() && before.Entropy != nil && after.Entropy != nil && after.GetEntropy()-before.GetEntropy() >= threshold {
+		diffs = append(diffs, FieldDiff{"entropy-increase", fmt.Sprintf("%.2f", before.GetEntropy()), fmt.Sprintf("%.2f", after.GetEntropy())})
+	}
 	fiDiffs, err := r.diffFileInfo(before.Info, after.Info)
 	if err != nil {
-		return "", fmt.Errorf("unable to diff file info for %q: %v", before.Path, err)
+		return nil, fmt.Errorf("unable to diff file info for %q: %v", before.Path, err)
 	}
 	diffs = append(diffs, fiDiffs...)
 	fsDiffs, err := r.diffFileStat(before.Stat, after.Stat)
 	if err != nil {
-		return "", fmt.Errorf("unable to diff file stat for %q: %v", before.Path, err)
+		return nil, fmt.Errorf("unable to diff file stat for %q: %v", before.Path, err)
 	}
 	diffs = append(diffs, fsDiffs...)
-	slices.Sort(diffs)
-	return strings.Join(diffs, "\n"), nil
+	slices.SortFunc(diffs, func(a, b FieldDiff) bool {
+		return a.String() < b.String()
+	})
+	return diffs, nil
+}
+
+// dataStreamsSummary returns the names and fingerprints of streams sorted by name, e.g.
+// "com.apple.ResourceFork:<sha256>", or "" if there are none. Streams too large to be
+// hashed are summarized by their size instead, e.g. "com.apple.ResourceFork:1024 bytes".
+func dataStreamsSummary(streams []*fspb.DataStream) string {
+	var summary []string
+	for _, s := range streams {
+		value := s.GetFingerprint().GetValue()
+		if value == "" {
+			value = fmt.Sprintf("%d bytes", s.Size)
+		}
+		summary = append(summary, fmt.Sprintf("%s:%s", s.Name, value))
+	}
+	slices.Sort(summary)
+	return strings.Join(summary, ",")
+}
+
+// diffFile compares two File entries of a Walk and shows the diffs between the two.
+func (r *Reporter) diffFile(before, after *fspb.File) (string, error) {
+	diffs, err := r.Diff(before, after)
+	if err != nil {
+		return "", err
+	}
+	return formatFieldDiffs(diffs), nil
+}
+
+// CompareAny compares after against each of the baselines and returns the report against
+// the one with the fewest differences, so a Walk is good if it matches any approved state.
+// Report.WalkBefore is the chosen baseline. Ties are resolved in favor of the earlier baseline.
+func (r *Reporter) CompareAny(after *fspb.Walk, baselines []*fspb.Walk) (*Report, error) {
+	if len(baselines) == 0 {
+		return nil, errors.New("no baselines to compare against")
+	}
+	var best *Report
+	for i, before := range baselines {
+		report, err := r.Compare(before, after)
+		if err != nil {
+			return nil, fmt.Errorf("unable to compare against baseline %d (%s): %v", i, before.GetId(), err)
+		}
+		if best == nil || report.numDiffs() < best.numDiffs() {
+			best = report
+		}
+		if best.Empty() {
+			break
+		}
+	}
+	return best, nil
+}
+
+// numDiffs returns the number of files which differ in the Report.
+func (r *Report) numDiffs() int {
+	return len(r.Added) + len(r.Deleted) + len(r.Modified) + len(r.Errors) + len(r.KnownBad)
+}
+
+// invertedMetrics maps the metrics of a Report which depend on the direction of
+// the comparison to their counterpart in the inverted Report.
+var invertedMetrics = map[string]string{
+	"before-files":         "after-files",
+	"after-files":          "before-files",
+	"before-files-ignored": "after-files-ignored",
+	"after-files-ignored":  "before-files-ignored",
+	"before-files-removed": "after-files-created",
+	"after-files-created":  "before-files-removed",
+	"deleted-bytes":        "added-bytes",
+	"added-bytes":          "deleted-bytes",
+
+	"before-files-out-of-scope": "after-files-out-of-scope",
+	"after-files-out-of-scope":  "before-files-out-of-scope",
+}
+
+// oneWayDiffFields are fields which are only reported for a change in one direction.
+var oneWayDiffFields = map[string]bool{
+	"truncated-to-empty": true,
+	"perm-other":         true,
+	"perm-loosened":      true,
+	"entropy-increase":   true,
+}
+
+// Invert turns the Report into the one of comparing its Walks the other way around,
+// e.g. if they were passed in the wrong order: Added and Deleted files trade places
+// and the before and after values of all diffs are swapped.
+// Findings which only exist in one direction and need the Reporter to be recomputed
+// are dropped, i.e. one-way diff fields like truncated-to-empty, world-writable
+// files, known bad hashes and critical changes. A baseline Report can't be inverted.
+func (r *Report) Invert() error {
+	if r.Baseline {
+		return errors.New("a baseline report has no before walk to invert")
+	}
+	r.WalkBefore, r.WalkAfter = r.WalkAfter, r.WalkBefore
+	r.Added, r.Deleted = invertActions(r.Deleted), invertActions(r.Added)
+	r.Modified = invertActions(r.Modified)
+	r.PermissionChanges = invertActions(r.PermissionChanges)
+	r.Errors = invertActions(r.Errors)
+	r.DirectorySizeChanges = invertActions(r.DirectorySizeChanges)
+	sortDirectorySizeChanges(r.DirectorySizeChanges)
+	r.WorldWritable = nil
+	r.KnownBad = nil
+	r.CriticalChangeDetected = false
+	r.Warnings = nil
+	if warning := walkOrderWarning(r.WalkBefore, r.WalkAfter); warning != "" {
+		r.Warnings = append(r.Warnings, warning)
+	}
+	r.Warnings = append(r.Warnings, incompleteWalkWarnings(r.WalkBefore, r.WalkAfter)...)
+	r.Warnings = append(r.Warnings, duplicatePathWarnings(r.WalkBefore, r.WalkAfter, r.pathTransform)...)
+
+	if r.Counter == nil {
+		return nil
+	}
+	counter := &metrics.Counter{}
+	for m, v := range r.Counter.Snapshot() {
+		switch m {
+		case "modified-bytes-delta":
+			counter.Add(-v, m)
+		case "before-files-high-severity", "before-files-critical", "after-files-world-writable", "after-files-known-hash":
+			// Recomputed below or dropped.
+		default:
+			if inv, ok := invertedMetrics[m]; ok {
+				m = inv
+			}
+			counter.Add(v, m)
+		}
+	}
+	for _, m := range r.Modified {
+		if hasHighSeverity(m.Fields) {
+			counter.Add(1, "before-files-high-severity")
+		}
+	}
+	r.Counter = counter
+	if warning := scopeWarning(r.WalkBefore, r.WalkAfter, r.Counter); warning != "" {
+		r.Warnings = append(r.Warnings, warning)
+	}
+	return nil
+}
+
+// invertActions returns the entries with swapped before and after files and diffs,
+// sorted by path.
+func invertActions(entries []ActionData) []ActionData {
+	var inverted []ActionData
+	for _, e := range entries {
+		var fields []FieldDiff
+		for _, d := range e.Fields {
+			if !oneWayDiffFields[d.Field] {
+				fields = append(fields, FieldDiff{d.Field, d.After, d.Before})
+			}
+		}
+		inverted = append(inverted, ActionData{
+			Before: e.After,
+			After:  e.Before,
+			Diff:   formatFieldDiffs(fields),
+			Fields: fields,
+			Err:    e.Err,
+		})
+	}
+	slices.SortFunc(inverted, func(a, b ActionData) bool {
+		return actionPath(a) < actionPath(b)
+	})
+	return inverted
+}
+
+// actionPath returns the path of the file of the entry.
+func actionPath(a ActionData) string {
+	if a.Before != nil {
+		return a.Before.Path
+	}
+	return a.After.Path
+}
+
+// filter returns diffs without the fields which are ignored by the options.
+func (o CompareOptions) filter(diffs []FieldDiff) []FieldDiff {
+	if len(o.IgnoreFields) == 0 {
+		return diffs
+	}
+	var filtered []FieldDiff
+	for _, d := range diffs {
+		if !slices.Contains(o.IgnoreFields, d.Field) {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered
+}
+
+// hasHighSeverity returns true if any of diffs is of high severity.
+func hasHighSeverity(diffs []FieldDiff) bool {
+	for _, d := range diffs {
+		if d.HighSeverity() {
+			return true
+		}
+	}
+	return false
+}
+
+// formatFieldDiffs returns the human readable diff, one changed field per line.
+func formatFieldDiffs(diffs []FieldDiff) string {
+	lines := make([]string, 0, len(diffs))
+	for _, d := range diffs {
+		lines = append(lines, d.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CompareOptions tune how CompareWithOptions compares two Walks.
+// The zero value compares the same way as Compare.
+type CompareOptions struct {
+	// Exclude is a list of paths which are excluded from the report in addition
+	// to the exclusions of the report config.
+	Exclude []string
+	// IgnoreFields is a list of diff fields (see FieldDiff.Field) which are not
+	// regarded as a modification, e.g. "mtime" or "ctime".
+	IgnoreFields []string
+}
+
+// transformPath applies PathTransform to path if set.
+func (r *Reporter) transformPath(path string) string {
+	if r.PathTransform == nil {
+		return path
+	}
+	return r.PathTransform(path)
 }
 
 // Compare two Walks and returns the diffs.
 func (r *Reporter) Compare(before, after *fspb.Walk) (*Report, error) {
+	return r.CompareWithOptions(before, after, CompareOptions{})
+}
+
+// CompareWithOptions compares two Walks according to opts and returns the diffs.
+func (r *Reporter) CompareWithOptions(before, after *fspb.Walk, opts CompareOptions) (*Report, error) {
 	if err := r.sanityCheck(before, after); err != nil {
 		return nil, err
 	}
+	exclude := append(append([]string{}, r.config.Exclude...), opts.Exclude...)
+	// Files of Walks which didn't collect data streams can't be told apart from
+	// files without any, so their streams are only compared if both Walks did.
+	opts.IgnoreFields = append([]string{}, opts.IgnoreFields...)
+	if !before.GetDataStreamsCollected() || !after.GetDataStreamsCollected() {
+		opts.IgnoreFields = append(opts.IgnoreFields, "data-streams")
+	}
+	// Likewise, stat fields omitted by the statFields of a policy look like zero values.
+	opts.IgnoreFields = append(opts.IgnoreFields, unrecordedStatDiffFields(before, after)...)
+
+	// If the policies of the Walks cover different files, files only one of them
+	// could have recorded are not reported as added or deleted.
+	beforeScope, afterScope := comparisonScopes(before, after)
+	scoped := beforeScope != nil
+	// recordedPaths maps the files to their absolute path as recorded if scoped.
+	recordedPaths := map[*fspb.File]string{}
 
 	walkedBefore := map[string]*fspb.File{}
 	walkedAfter := map[string]*fspb.File{}
 	if before != nil {
 		for _, fbOrig := range before.File {
 			fb := proto.Clone(fbOrig).(*fspb.File)
-			fb.Path = NormalizePath(fb.Path, fb.Info.IsDir)
+			fb.Path = NormalizePath(r.transformPath(fb.Path), fb.Info.IsDir)
 			walkedBefore[fb.Path] = fb
+			if scoped {
+				recordedPaths[fb] = beforeScope.absPath(fbOrig.Path)
+			}
 		}
 	}
 	for _, faOrig := range after.File {
 		fa := proto.Clone(faOrig).(*fspb.File)
-		fa.Path = NormalizePath(fa.Path, fa.Info.IsDir)
+		fa.Path = NormalizePath(r.transformPath(fa.Path), fa.Info.IsDir)
 		walkedAfter[fa.Path] = fa
+		if scoped {
+			recordedPaths[fa] = afterScope.absPath(faOrig.Path)
+		}
 	}
 
 	counter := metrics.Counter{}
@@ -372,21 +1264,49 @@ func (r *Reporter) Compare(before, after *fspb.Walk) (*Report, error) {
 		Counter:    &counter,
 		WalkBefore: before,
 		WalkAfter:  after,
+		Baseline:   before == nil,
+
+		pathTransform: r.PathTransform,
+	}
+	if warning := r.displayLocationWarning(); warning != "" {
+		output.Warnings = append(output.Warnings, warning)
+	}
+	if warning := walkOrderWarning(before, after); warning != "" {
+		output.Warnings = append(output.Warnings, warning)
 	}
+	output.Warnings = append(output.Warnings, incompleteWalkWarnings(before, after)...)
+	output.Warnings = append(output.Warnings, duplicatePathWarnings(before, after, r.PathTransform)...)
 
 	for _, fb := range walkedBefore {
 		counter.Add(1, "before-files")
-		if isExcluded(fb.Path, r.config.Exclude) {
+		if isExcluded(fb.Path, exclude) {
 			counter.Add(1, "before-files-ignored")
 			continue
 		}
 		fa := walkedAfter[fb.Path]
+		if fa == nil && scoped && !afterScope.contains(recordedPaths[fb], fb.Info.GetIsDir()) {
+			counter.Add(1, "before-files-out-of-scope")
+			continue
+		}
 		if fa == nil {
 			counter.Add(1, "before-files-removed")
-			output.Deleted = append(output.Deleted, ActionData{Before: fb})
+			counter.Add(fb.Info.GetSize(), "deleted-bytes")
+			critical := isExcluded(fb.Path, r.config.GetCriticalPaths())
+			if critical {
+				counter.Add(1, "before-files-critical")
+				output.CriticalChangeDetected = true
+			}
+			if !r.config.GetModifiedOnly() {
+				output.Deleted = append(output.Deleted, ActionData{Before: fb, Critical: critical})
+			}
 			continue
 		}
-		diff, err := r.diffFile(fb, fa)
+		if r.IgnoreHashMethodChanges && !r.config.GetMetadataOnly() && hashMethodChanged(fb, fa) {
+			counter.Add(1, "before-files-hash-method-changed")
+		}
+		fields, err := r.Diff(fb, fa)
+		fields = opts.filter(fields)
+		diff := formatFieldDiffs(fields)
 		if err != nil {
 			counter.Add(1, "file-diff-error")
 			output.Errors = append(output.Errors, ActionData{
@@ -398,25 +1318,67 @@ func (r *Reporter) Compare(before, after *fspb.Walk) (*Report, error) {
 		}
 		if diff != "" {
 			counter.Add(1, "before-files-modified")
-			output.Modified = append(output.Modified, ActionData{
+			counter.Add(fa.Info.GetSize()-fb.Info.GetSize(), "modified-bytes-delta")
+			if hasHighSeverity(fields) {
+				counter.Add(1, "before-files-high-severity")
+			}
+			critical := isExcluded(fb.Path, r.config.GetCriticalPaths())
+			if critical {
+				counter.Add(1, "before-files-critical")
+				output.CriticalChangeDetected = true
+			}
+			action := ActionData{
+				Before:   fb,
+				After:    fa,
+				Diff:     diff,
+				Fields:   fields,
+				Critical: critical,
+			}
+			output.Modified = append(output.Modified, action)
+			if isPermissionChange(fields) {
+				counter.Add(1, "before-files-permission-changed")
+				if r.config.GetReportPermissionChanges() {
+					output.PermissionChanges = append(output.PermissionChanges, action)
+				}
+			}
+		}
+		if fb.AggregateSize != nil && fa.AggregateSize != nil && fb.GetAggregateSize() != fa.GetAggregateSize() {
+			sizeDiff := []FieldDiff{{"aggregate-size", fmt.Sprint(fb.GetAggregateSize()), fmt.Sprint(fa.GetAggregateSize())}}
+			output.DirectorySizeChanges = append(output.DirectorySizeChanges, ActionData{
 				Before: fb,
 				After:  fa,
-				Diff:   diff,
+				Diff:   formatFieldDiffs(sizeDiff),
+				Fields: sizeDiff,
 			})
 		}
 	}
 	for _, fa := range walkedAfter {
 		counter.Add(1, "after-files")
-		if isExcluded(fa.Path, r.config.Exclude) {
+		if isExcluded(fa.Path, exclude) {
 			counter.Add(1, "after-files-ignored")
 			continue
 		}
+		if r.config.GetReportWorldWritable() && worldWritable(fa.Info.GetMode()) {
+			counter.Add(1, "after-files-world-writable")
+			output.WorldWritable = append(output.WorldWritable, ActionData{After: fa})
+		}
 		_, ok := walkedBefore[fa.Path]
 		if ok {
 			continue
 		}
-		counter.Add(1, "after-files-created")
-		output.Added = append(output.Added, ActionData{After: fa})
+		if scoped && !beforeScope.contains(recordedPaths[fa], fa.Info.GetIsDir()) {
+			counter.Add(1, "after-files-out-of-scope")
+			continue
+		}
+		if output.Baseline {
+			counter.Add(1, "after-files-baseline")
+		} else {
+			counter.Add(1, "after-files-created")
+		}
+		counter.Add(fa.Info.GetSize(), "added-bytes")
+		if !r.config.GetModifiedOnly() {
+			output.Added = append(output.Added, ActionData{After: fa})
+		}
 	}
 
 	slices.SortFunc(output.Added, func(a, b ActionData) bool {
@@ -428,13 +1390,58 @@ func (r *Reporter) Compare(before, after *fspb.Walk) (*Report, error) {
 	slices.SortFunc(output.Modified, func(a, b ActionData) bool {
 		return a.Before.Path < b.Before.Path
 	})
+	slices.SortFunc(output.PermissionChanges, func(a, b ActionData) bool {
+		return a.Before.Path < b.Before.Path
+	})
 	slices.SortFunc(output.Errors, func(a, b ActionData) bool {
 		return a.Before.Path < b.Before.Path
 	})
+	slices.SortFunc(output.WorldWritable, func(a, b ActionData) bool {
+		return a.After.Path < b.After.Path
+	})
+	sortDirectorySizeChanges(output.DirectorySizeChanges)
+	if r.KnownHashes != nil {
+		output.CheckKnownHashes(r.KnownHashes)
+	}
+	if warning := scopeWarning(before, after, &counter); warning != "" {
+		output.Warnings = append(output.Warnings, warning)
+	}
+	if n, _ := counter.Get("before-files-hash-method-changed"); n > 0 {
+		output.Warnings = append(output.Warnings, fmt.Sprintf("the content of %d files wasn't compared as they were hashed with a different method before", n))
+	}
 
 	return &output, nil
 }
 
+// sortDirectorySizeChanges sorts the entries by the absolute change of the aggregate
+// size, largest first, and by path if equal.
+func sortDirectorySizeChanges(entries []ActionData) {
+	slices.SortFunc(entries, func(a, b ActionData) bool {
+		da, db := aggregateSizeDelta(a), aggregateSizeDelta(b)
+		if da < 0 {
+			da = -da
+		}
+		if db < 0 {
+			db = -db
+		}
+		if da != db {
+			return da > db
+		}
+		return actionPath(a) < actionPath(b)
+	})
+}
+
+// aggregateSizeDelta returns by how many bytes the aggregate size of the
+// directory of the entry grew.
+func aggregateSizeDelta(a ActionData) int64 {
+	return int64(a.After.GetAggregateSize()) - int64(a.Before.GetAggregateSize())
+}
+
+// hashMethodChanged returns true if both files have fingerprints but of different methods.
+func hashMethodChanged(before, after *fspb.File) bool {
+	return len(before.Fingerprint) > 0 && len(after.Fingerprint) > 0 && before.Fingerprint[0].Method != after.Fingerprint[0].Method
+}
+
 // PrintDiffSummary prints the diffs found in a Report.
 func (r *Reporter) PrintDiffSummary(report *Report) {
 	fmt.Println("===============================================================================")
@@ -442,41 +1449,96 @@ func (r *Reporter) PrintDiffSummary(report *Report) {
 	fmt.Println("===============================================================================")
 
 	if len(report.Added) > 0 {
-		fmt.Printf("Added (%d):\n", len(report.Added))
-		for _, file := range report.Added {
+		if report.Baseline {
+			fmt.Printf("Baseline Inventory (%d):\n", len(report.Added))
+		} else {
+			fmt.Printf("Added (%d):\n", len(report.Added))
+		}
+		for _, file := range r.truncate(report.Added) {
 			fmt.Println(file.After.Path)
 		}
+		r.printTruncated(report.Added)
 		fmt.Println()
 	}
 	if len(report.Deleted) > 0 {
 		fmt.Printf("Removed (%d):\n", len(report.Deleted))
-		for _, file := range report.Deleted {
+		for _, file := range r.truncate(report.Deleted) {
 			fmt.Println(file.Before.Path)
 		}
+		r.printTruncated(report.Deleted)
 		fmt.Println()
 	}
 	if len(report.Modified) > 0 {
 		fmt.Printf("Modified (%d):\n", len(report.Modified))
-		for _, file := range report.Modified {
-			fmt.Println(file.After.Path)
+		for _, file := range r.truncate(report.Modified) {
+			if hasHighSeverity(file.Fields) {
+				fmt.Printf("%s (HIGH SEVERITY)\n", file.After.Path)
+			} else {
+				fmt.Println(file.After.Path)
+			}
 			if r.Verbose {
 				fmt.Println(file.Diff)
 				fmt.Println()
 			}
+			if r.VerboseProto {
+				fmt.Printf("Before:\n%s\n", prototext.Format(file.Before))
+				fmt.Printf("After:\n%s\n", prototext.Format(file.After))
+				fmt.Println()
+			}
+		}
+		r.printTruncated(report.Modified)
+		fmt.Println()
+	}
+	if len(report.PermissionChanges) > 0 {
+		fmt.Printf("Permission Changes Only (%d):\n", len(report.PermissionChanges))
+		for _, file := range r.truncate(report.PermissionChanges) {
+			fmt.Println(file.After.Path)
 		}
+		r.printTruncated(report.PermissionChanges)
 		fmt.Println()
 	}
 	if len(report.Errors) > 0 {
 		fmt.Printf("Reporting Errors (%d):\n", len(report.Errors))
-		for _, file := range report.Errors {
+		for _, file := range r.truncate(report.Errors) {
 			fmt.Printf("%s: %v\n", file.Before.Path, file.Err)
 		}
+		r.printTruncated(report.Errors)
 		fmt.Println()
 	}
+	if len(report.KnownBad) > 0 {
+		fmt.Printf("Known Bad Hashes (%d):\n", len(report.KnownBad))
+		for _, file := range r.truncate(report.KnownBad) {
+			fmt.Printf("%s: %s (HIGH SEVERITY)\n", file.After.Path, file.Diff)
+		}
+		r.printTruncated(report.KnownBad)
+		fmt.Println()
+	}
+	if len(report.WorldWritable) > 0 {
+		fmt.Printf("World-Writable (%d):\n", len(report.WorldWritable))
+		for _, file := range r.truncate(report.WorldWritable) {
+			fmt.Println(file.After.Path)
+		}
+		r.printTruncated(report.WorldWritable)
+		fmt.Println()
+	}
+	if len(report.DirectorySizeChanges) > 0 {
+		fmt.Printf("Directory Size Changes (%d):\n", len(report.DirectorySizeChanges))
+		for _, dir := range r.truncate(report.DirectorySizeChanges) {
+			fmt.Printf("%s: %+d bytes (%d => %d)\n", dir.After.Path, aggregateSizeDelta(dir), dir.Before.GetAggregateSize(), dir.After.GetAggregateSize())
+		}
+		r.printTruncated(report.DirectorySizeChanges)
+		fmt.Println()
+	}
+	r.printChurnByGroup(report)
 	if report.Empty() {
 		fmt.Println("No changes.")
 	}
-	if report.WalkBefore != nil && len(report.WalkBefore.Notification) > 0 {
+	if report.CriticalChangeDetected {
+		n, _ := report.Counter.Get("before-files-critical")
+		fmt.Printf("CRITICAL: %d critical files were modified or removed!\n\n", n)
+	}
+	suppress := r.config.GetSuppressRecurringNotifications() && report.WalkBefore != nil
+	if report.WalkBefore != nil && len(report.WalkBefore.Notification) > 0 && !suppress {
 		fmt.Println("Walking Errors for BEFORE file:")
 		for _, err := range report.WalkBefore.Notification {
 			if r.Verbose || (err.Severity != fspb.Notification_UNKNOWN && err.Severity != fspb.Notification_INFO) {
@@ -485,25 +1547,123 @@ func (r *Reporter) PrintDiffSummary(report *Report) {
 		}
 		fmt.Println()
 	}
-	if len(report.WalkAfter.Notification) > 0 {
+	afterNotifications := report.WalkAfter.Notification
+	if suppress {
+		afterNotifications = newNotifications(report.WalkBefore, report.WalkAfter)
+	}
+	if len(afterNotifications) > 0 {
 		fmt.Println("Walking Errors for AFTER file:")
-		for _, err := range report.WalkAfter.Notification {
+		for _, err := range afterNotifications {
 			if r.Verbose || (err.Severity != fspb.Notification_UNKNOWN && err.Severity != fspb.Notification_INFO) {
 				fmt.Printf("%s(%s): %s\n", err.Severity, err.Path, err.Message)
 			}
 		}
 		fmt.Println()
 	}
+	if n := len(report.WalkAfter.Notification) - len(afterNotifications); n > 0 {
+		fmt.Printf("Suppressed %d notifications of the AFTER file already recorded by the BEFORE file.\n\n", n)
+	}
+}
+
+// newNotifications returns the notifications of after which before doesn't have
+// as well, i.e. with the same severity, path and message.
+func newNotifications(before, after *fspb.Walk) []*fspb.Notification {
+	type key struct {
+		severity fspb.Notification_Severity
+		path     string
+		message  string
+	}
+	recurring := map[key]bool{}
+	for _, n := range before.GetNotification() {
+		recurring[key{n.Severity, n.Path, n.Message}] = true
+	}
+	var ns []*fspb.Notification
+	for _, n := range after.GetNotification() {
+		if !recurring[key{n.Severity, n.Path, n.Message}] {
+			ns = append(ns, n)
+		}
+	}
+	return ns
+}
+
+// truncate returns the entries of a section which are printed according to MaxDiffs.
+func (r *Reporter) truncate(entries []ActionData) []ActionData {
+	if r.MaxDiffs > 0 && len(entries) > r.MaxDiffs {
+		return entries[:r.MaxDiffs]
+	}
+	return entries
+}
+
+// printTruncated prints a footer stating how many entries of a section were not printed.
+func (r *Reporter) printTruncated(entries []ActionData) {
+	if n := len(entries) - len(r.truncate(entries)); n > 0 {
+		fmt.Printf("... and %d more\n", n)
+	}
+}
+
+// PrintStatsSummary prints the number of additions, deletions, modifications and errors
+// in a Report without listing any of the paths involved.
+func (r *Reporter) PrintStatsSummary(report *Report) {
+	fmt.Println("===============================================================================")
+	fmt.Println("Stats Summary:")
+	fmt.Println("===============================================================================")
+	if report.Baseline {
+		fmt.Println("Mode: baseline creation (no before walk)")
+	}
+	fmt.Printf("Added: %d\n", len(report.Added))
+	fmt.Printf("Removed: %d\n", len(report.Deleted))
+	fmt.Printf("Modified: %d\n", len(report.Modified))
+	fmt.Printf("Reporting Errors: %d\n", len(report.Errors))
+	for _, warning := range report.Warnings {
+		fmt.Printf("WARNING: %s\n", warning)
+	}
+	fmt.Println()
+	r.printChurnByGroup(report)
 }
 
 // printWalkSummary prints some information about the given walk.
 func (r *Reporter) printWalkSummary(walk *fspb.Walk) {
-	awst := walk.StartWalk.AsTime()
-	awet := walk.StopWalk.AsTime()
+	awst := walk.StartWalk.AsTime().In(r.displayLocation())
+	awet := walk.StopWalk.AsTime().In(r.displayLocation())
 
 	fmt.Printf("  - ID: %s\n", walk.Id)
 	fmt.Printf("  - Start Time: %s\n", awst)
 	fmt.Printf("  - Stop Time: %s\n", awet)
+	for _, k := range labelKeys(walk.Labels) {
+		fmt.Printf("  - Label %s: %s\n", k, walk.Labels[k])
+	}
+}
+
+// labelKeys returns the sorted union of the keys of all labels.
+func labelKeys(labels ...map[string]string) []string {
+	var keys []string
+	for _, l := range labels {
+		for k := range l {
+			if !slices.Contains(keys, k) {
+				keys = append(keys, k)
+			}
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// labelDiffs returns the changes of the labels between the before and after Walk.
+func labelDiffs(before, after *fspb.Walk) []string {
+	var diffs []string
+	for _, k := range labelKeys(before.GetLabels(), after.GetLabels()) {
+		bv, bok := before.GetLabels()[k]
+		av, aok := after.GetLabels()[k]
+		switch {
+		case !bok:
+			diffs = append(diffs, fmt.Sprintf("%s: added %q", k, av))
+		case !aok:
+			diffs = append(diffs, fmt.Sprintf("%s: removed %q", k, bv))
+		case bv != av:
+			diffs = append(diffs, fmt.Sprintf("%s: %q => %q", k, bv, av))
+		}
+	}
+	return diffs
 }
 
 // PrintReportSummary prints a few key information pieces around the Report.
@@ -513,6 +1673,11 @@ func (r *Reporter) PrintReportSummary(report *Report) {
 	fmt.Println("===============================================================================")
 	fmt.Printf("Host name: %s\n", report.WalkAfter.Hostname)
 	fmt.Printf("Report config used: %s\n", r.configPath)
+	if report.Baseline {
+		fmt.Println("Mode: baseline creation (no before walk, all files are listed as the initial inventory)")
+	} else {
+		fmt.Println("Mode: comparison")
+	}
 	if report.WalkBefore != nil {
 		fmt.Println("Walk (Before)")
 		r.printWalkSummary(report.WalkBefore)
@@ -520,6 +1685,12 @@ func (r *Reporter) PrintReportSummary(report *Report) {
 	fmt.Println("Walk (After)")
 	r.printWalkSummary(report.WalkAfter)
 	fmt.Println()
+	for _, warning := range report.Warnings {
+		fmt.Printf("WARNING: %s\n", warning)
+	}
+	if len(report.Warnings) > 0 {
+		fmt.Println()
+	}
 }
 
 // PrintRuleSummary prints the configs and policies involved in creating the Walk and Report.
@@ -528,6 +1699,19 @@ func (r *Reporter) PrintRuleSummary(report *Report) {
 	fmt.Println("Rule Summary:")
 	fmt.Println("===============================================================================")
 
+	if report.WalkBefore != nil {
+		fmt.Printf("Policy fingerprint (before): %s\n", fingerprintValue(report.WalkBefore.PolicyFingerprint))
+	}
+	fmt.Printf("Policy fingerprint (after): %s\n", fingerprintValue(report.WalkAfter.PolicyFingerprint))
+	if report.WalkBefore != nil && !proto.Equal(report.WalkBefore.PolicyFingerprint, report.WalkAfter.PolicyFingerprint) {
+		fmt.Println("Policy fingerprints differ: the walks were not created with the same policy!")
+	}
+	if diffs := labelDiffs(report.WalkBefore, report.WalkAfter); len(diffs) > 0 && report.WalkBefore != nil {
+		fmt.Println("Walk label changes:")
+		for _, d := range diffs {
+			fmt.Printf("  %s\n", d)
+		}
+	}
 	if report.WalkBefore != nil {
 		// TODO: TOML encode
 		diff := cmp.Diff(report.WalkBefore.Policy, report.WalkAfter.Policy, cmp.Comparer(proto.Equal))
@@ -562,6 +1746,14 @@ func (r *Reporter) PrintRuleSummary(report *Report) {
 	}
 }
 
+// fingerprintValue returns a printable representation of the fingerprint.
+func fingerprintValue(fp *fspb.Fingerprint) string {
+	if fp == nil {
+		return "<none>"
+	}
+	return fmt.Sprintf("%s(%s)", fp.Method, fp.Value)
+}
+
 func encodeTOML(v any) (string, error) {
 	var buf strings.Builder
 	enc := toml.NewEncoder(&buf)
@@ -572,8 +1764,84 @@ func encodeTOML(v any) (string, error) {
 	return buf.String(), nil
 }
 
+// ReadReviews reads the reviews file at path which holds the last known good Walk per host.
+// The returned Reviews always has a non-nil Review map.
+func (r *Reporter) ReadReviews(path string) (*fspb.Reviews, error) {
+	reviews := &fspb.Reviews{}
+	if err := readTextProto(path, reviews); err != nil {
+		return nil, err
+	}
+	if reviews.Review == nil {
+		reviews.Review = map[string]*fspb.Review{}
+	}
+	return reviews, nil
+}
+
+// OutputFileMode returns the file mode of the files written by the reporter,
+// as set by the outputFileMode of the report config.
+func (r *Reporter) OutputFileMode() (os.FileMode, error) {
+	mode, err := ParseFileMode(r.config.GetOutputFileMode(), defaultReportFileMode)
+	if err != nil {
+		return 0, fmt.Errorf("invalid outputFileMode: %v", err)
+	}
+	return mode, nil
+}
+
+// WriteReviews writes reviews to the reviews file at path, replacing its content.
+// The file mode is taken from the report config.
+func (r *Reporter) WriteReviews(path string, reviews *fspb.Reviews) error {
+	mode, err := r.OutputFileMode()
+	if err != nil {
+		return err
+	}
+	return writeTextProto(path, reviews, mode)
+}
+
+// reviewFileFor returns the review file of reviewFiles to store the review of hostname in,
+// along with its reviews. That is the first writable file which already holds a review of
+// the host or, if there is none, the first writable one.
+func (r *Reporter) reviewFileFor(hostname string, reviewFiles []string) (string, *fspb.Reviews, error) {
+	var firstPath string
+	var firstReviews *fspb.Reviews
+	for _, path := range reviewFiles {
+		if !isWritable(path) {
+			continue
+		}
+		reviews, err := r.ReadReviews(path)
+		if err != nil {
+			return "", nil, err
+		}
+		if _, ok := reviews.Review[hostname]; ok {
+			return path, reviews, nil
+		}
+		if firstReviews == nil {
+			firstPath, firstReviews = path, reviews
+		}
+	}
+	if firstReviews == nil {
+		return "", nil, fmt.Errorf("none of the review files %q is writable", reviewFiles)
+	}
+	return firstPath, firstReviews, nil
+}
+
+// isWritable returns true if the existing file at path can be opened for writing.
+func isWritable(path string) bool {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return false
+	}
+	f.Close()
+	return true
+}
+
 // UpdateReviewProto updates the reviews file to the reviewed version to be "last known good".
-func (r *Reporter) UpdateReviewProto(walkFile *WalkFile, reviewFile string) error {
+// Of multiple review files, the first writable one is updated, preferring the one
+// which already holds a review of the host. Without (non-empty) review files, the
+// review is only printed. Incomplete Walks are refused.
+func (r *Reporter) UpdateReviewProto(walkFile *WalkFile, reviewFiles ...string) error {
+	if walkFile.Walk.GetIncomplete() {
+		return fmt.Errorf("refusing to review the incomplete walk %q", walkFile.Path)
+	}
 	review := &fspb.Review{
 		WalkID:        walkFile.Walk.Id,
 		WalkReference: walkFile.Path,
@@ -588,14 +1856,20 @@ func (r *Reporter) UpdateReviewProto(walkFile *WalkFile, reviewFile string) erro
 	// replace message boundary characters as curly braces look nicer (both is fine to parse)
 	fmt.Println(strings.Replace(strings.Replace(blob, "<", "{", -1), ">", "}", -1))
 
-	if reviewFile != "" {
-		reviews := &fspb.Reviews{}
-		if err := readTextProto(reviewFile, reviews); err != nil {
+	var paths []string
+	for _, path := range reviewFiles {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) > 0 {
+		reviewFile, reviews, err := r.reviewFileFor(walkFile.Walk.Hostname, paths)
+		if err != nil {
 			return err
 		}
 
 		reviews.Review[walkFile.Walk.Hostname] = review
-		if err := writeTextProto(reviewFile, reviews); err != nil {
+		if err := r.WriteReviews(reviewFile, reviews); err != nil {
 			return err
 		}
 		fmt.Printf("Changes written to %q\n", reviewFile)
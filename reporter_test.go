@@ -15,19 +15,49 @@
 package fswalker
 
 import (
+	"bytes"
 	"crypto/sha256"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
+	"testing/iotest"
 	"time"
+	_ "time/tzdata" // Time zones for TestDiffDisplayTimezone independent of the host.
 
 	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/encoding/prototext"
 	"google.golang.org/protobuf/proto"
 	tspb "google.golang.org/protobuf/types/known/timestamppb"
 
 	fspb "github.com/google/fswalker/proto/fswalker"
 )
 
+// captureStdout returns everything written to stdout while running f.
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	outCh := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		outCh <- string(b)
+	}()
+	f()
+	w.Close()
+	return <-outCh
+}
+
 func TestVerifyFingerprint(t *testing.T) {
 	testCases := []struct {
 		desc    string
@@ -200,6 +230,252 @@ func TestReadWalk(t *testing.T) {
 	}
 }
 
+func TestDecodeWalk(t *testing.T) {
+	walk := &fspb.Walk{
+		Id:        "large",
+		Version:   1,
+		Hostname:  "testhost",
+		StartWalk: tspb.Now(),
+		StopWalk:  tspb.Now(),
+		Policy: &fspb.Policy{
+			Version:         1,
+			Include:         []string{"/"},
+			MaxHashFileSize: 1024 * 1024,
+		},
+		Notification: []*fspb.Notification{
+			{Severity: fspb.Notification_WARNING, Path: "/x", Message: "warning"},
+		},
+	}
+	for i := 0; i < 10000; i++ {
+		walk.File = append(walk.File, &fspb.File{
+			Version: 1,
+			Path:    fmt.Sprintf("/dir%d/file%d", i%100, i),
+			Info: &fspb.FileInfo{
+				Name:     fmt.Sprintf("file%d", i),
+				Size:     int64(i),
+				Mode:     0644,
+				Modified: tspb.Now(),
+			},
+			Stat: &fspb.FileStat{Inode: uint64(i), Uid: 1000, Gid: 1000},
+			Fingerprint: []*fspb.Fingerprint{
+				{Method: fspb.Fingerprint_SHA256, Value: fmt.Sprintf("%064x", i)},
+			},
+		})
+	}
+	b, err := proto.Marshal(walk)
+	if err != nil {
+		t.Fatalf("problems marshaling walk: %v", err)
+	}
+
+	r := &Reporter{}
+	wantFp := r.fingerprint(b)
+	gotWalk, gotFp, err := decodeWalk(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("decodeWalk() error: %v", err)
+	}
+	if diff := cmp.Diff(wantFp, gotFp, cmp.Comparer(proto.Equal)); diff != "" {
+		t.Errorf("decodeWalk() fingerprint: diff (-want +got):\n%s", diff)
+	}
+	if !proto.Equal(walk, gotWalk) {
+		t.Error("decodeWalk() walk differs from the marshaled walk")
+	}
+
+	if _, _, err := decodeWalk(bytes.NewReader(b[:len(b)-10])); err == nil {
+		t.Error("decodeWalk() of truncated walk: no error")
+	}
+}
+
+func TestReadWalkFrom(t *testing.T) {
+	walk := &fspb.Walk{
+		Id:       "piped",
+		Hostname: "testhost",
+		File:     []*fspb.File{{Path: "/etc/hosts", Info: &fspb.FileInfo{Name: "hosts", Size: 10}}},
+	}
+	b, err := proto.Marshal(walk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := &Reporter{}
+	// A pipe delivers the walk in small chunks.
+	got, err := r.ReadWalkFrom(iotest.OneByteReader(bytes.NewReader(b)), "-")
+	if err != nil {
+		t.Fatalf("ReadWalkFrom() error: %v", err)
+	}
+	if !proto.Equal(walk, got.Walk) {
+		t.Error("ReadWalkFrom() walk differs from the marshaled walk")
+	}
+	if diff := cmp.Diff(r.fingerprint(b), got.Fingerprint, cmp.Comparer(proto.Equal)); diff != "" {
+		t.Errorf("ReadWalkFrom() fingerprint: diff (-want +got):\n%s", diff)
+	}
+	if got.Path != "-" {
+		t.Errorf("ReadWalkFrom() path = %q; want %q", got.Path, "-")
+	}
+}
+
+func TestReadLatestWalk(t *testing.T) {
+	dir := t.TempDir()
+	walks := []struct {
+		name string
+		id   string
+	}{
+		{WalkFilename("testhost", time.Date(2018, 12, 31, 23, 0, 0, 0, time.Local)), "old-format"},
+		{WalkFilenameUTC("testhost", time.Date(2019, 1, 1, 1, 0, 0, 0, time.Local)), "latest"},
+		{WalkFilenameUTC("testhost", time.Date(2018, 12, 1, 0, 0, 0, 0, time.Local)), "oldest"},
+		{WalkFilename("otherhost", time.Date(2020, 1, 1, 0, 0, 0, 0, time.Local)), "other-host"},
+	}
+	for _, w := range walks {
+		b, err := proto.Marshal(&fspb.Walk{Id: w.id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, w.name), b, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := &Reporter{}
+	wf, err := r.ReadLatestWalk("testhost", dir)
+	if err != nil {
+		t.Fatalf("ReadLatestWalk() error: %v", err)
+	}
+	if wf.Walk.Id != "latest" {
+		t.Errorf("ReadLatestWalk() read walk %q; want %q", wf.Walk.Id, "latest")
+	}
+}
+
+func TestReadWriteReviews(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reviews.asciipb")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	r := &Reporter{config: &fspb.ReportConfig{}}
+
+	reviews, err := r.ReadReviews(path)
+	if err != nil {
+		t.Fatalf("ReadReviews() error: %v", err)
+	}
+	reviews.Review["host-a"] = &fspb.Review{WalkID: "walk-a", WalkReference: "/walks/a.pb"}
+	reviews.Review["host-b"] = &fspb.Review{WalkID: "walk-b", WalkReference: "/walks/b.pb"}
+	if err := r.WriteReviews(path, reviews); err != nil {
+		t.Fatalf("WriteReviews() error: %v", err)
+	}
+
+	reviews, err = r.ReadReviews(path)
+	if err != nil {
+		t.Fatalf("ReadReviews() error: %v", err)
+	}
+	if got := len(reviews.Review); got != 2 {
+		t.Fatalf("ReadReviews() read %d reviews; want 2", got)
+	}
+	delete(reviews.Review, "host-a")
+	if err := r.WriteReviews(path, reviews); err != nil {
+		t.Fatalf("WriteReviews() error: %v", err)
+	}
+
+	got, err := r.ReadReviews(path)
+	if err != nil {
+		t.Fatalf("ReadReviews() error: %v", err)
+	}
+	want := &fspb.Reviews{Review: map[string]*fspb.Review{
+		"host-b": {WalkID: "walk-b", WalkReference: "/walks/b.pb"},
+	}}
+	if diff := cmp.Diff(want, got, cmp.Comparer(proto.Equal)); diff != "" {
+		t.Errorf("ReadReviews() after removing host-a: diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestMultipleReviewFiles(t *testing.T) {
+	dir := t.TempDir()
+	walkPath := filepath.Join(dir, "walk.pb")
+	if err := WriteWalk(walkPath, &fspb.Walk{Id: "walk-b", Hostname: "host-b"}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	r := &Reporter{config: &fspb.ReportConfig{}}
+	wf, err := r.ReadWalk(walkPath)
+	if err != nil {
+		t.Fatalf("ReadWalk() error: %v", err)
+	}
+
+	first := filepath.Join(dir, "prod.asciipb")
+	second := filepath.Join(dir, "staging.asciipb")
+	firstReviews := &fspb.Reviews{Review: map[string]*fspb.Review{
+		"host-a": {WalkID: "walk-a", WalkReference: "/walks/a.pb"},
+	}}
+	secondReviews := &fspb.Reviews{Review: map[string]*fspb.Review{
+		"host-b": {WalkID: "walk-b", WalkReference: walkPath, Fingerprint: wf.Fingerprint},
+	}}
+	if err := r.WriteReviews(first, firstReviews); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.WriteReviews(second, secondReviews); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := r.ReadLastGoodWalk("host-b", first, second)
+	if err != nil {
+		t.Fatalf("ReadLastGoodWalk() error: %v", err)
+	}
+	if got == nil || got.Walk.Id != "walk-b" {
+		t.Fatalf("ReadLastGoodWalk() = %v; want walk walk-b", got)
+	}
+
+	// The update must go to the file already holding the host's review.
+	captureStdout(t, func() {
+		if err := r.UpdateReviewProto(wf, first, second); err != nil {
+			t.Fatalf("UpdateReviewProto() error: %v", err)
+		}
+	})
+	reviews, err := r.ReadReviews(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(firstReviews, reviews, cmp.Comparer(proto.Equal)); diff != "" {
+		t.Errorf("UpdateReviewProto() modified %s: diff (-want +got):\n%s", first, diff)
+	}
+	reviews, err = r.ReadReviews(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(secondReviews, reviews, cmp.Comparer(proto.Equal)); diff != "" {
+		t.Errorf("UpdateReviewProto() wrote unexpected reviews to %s: diff (-want +got):\n%s", second, diff)
+	}
+
+	// Without review files, the review is only printed.
+	for _, files := range [][]string{nil, {""}} {
+		out := captureStdout(t, func() {
+			if err := r.UpdateReviewProto(wf, files...); err != nil {
+				t.Errorf("UpdateReviewProto(%q) error: %v", files, err)
+			}
+		})
+		if !strings.Contains(out, "update it manually") {
+			t.Errorf("UpdateReviewProto(%q) output = %q; want a hint to update the review manually", files, out)
+		}
+	}
+}
+
+func TestIncompleteWalk(t *testing.T) {
+	before := &fspb.Walk{Id: "1", Hostname: "testhost", File: []*fspb.File{{Path: "/a", Info: &fspb.FileInfo{}}}}
+	after := &fspb.Walk{Id: "2", Hostname: "testhost", Incomplete: true}
+
+	r := &Reporter{config: &fspb.ReportConfig{}}
+	report, err := r.Compare(before, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	if len(report.Warnings) != 1 || !strings.Contains(report.Warnings[0], "after Walk is incomplete") {
+		t.Errorf("Compare() warnings = %q; want a warning about the incomplete after Walk", report.Warnings)
+	}
+
+	reviews := filepath.Join(t.TempDir(), "reviews.asciipb")
+	if err := r.UpdateReviewProto(&WalkFile{Path: "/walks/2.pb", Walk: after}, reviews); err == nil {
+		t.Error("UpdateReviewProto() of an incomplete Walk succeeded, want error")
+	}
+	if _, err := os.Stat(reviews); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("UpdateReviewProto() of an incomplete Walk wrote %s", reviews)
+	}
+}
+
 func TestSanityCheck(t *testing.T) {
 	ts1 := tspb.Now()
 	ts2 := tspb.New(time.Now().Add(time.Hour * 10))
@@ -300,6 +576,42 @@ func TestSanityCheck(t *testing.T) {
 	}
 }
 
+func TestCompareReversedWalks(t *testing.T) {
+	older := tspb.New(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	newer := tspb.New(time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC))
+	// The newer Walk was interrupted and never recorded when it stopped, so
+	// the Walks don't overlap and sanityCheck can't tell they are reversed.
+	before := &fspb.Walk{Id: "newer", Hostname: "testhost", StartWalk: newer}
+	after := &fspb.Walk{Id: "older", Hostname: "testhost", StartWalk: older, StopWalk: older}
+
+	r := &Reporter{config: &fspb.ReportConfig{}}
+	report, err := r.Compare(before, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	if len(report.Warnings) != 1 || !strings.Contains(report.Warnings[0], "wrong order") {
+		t.Fatalf("Compare() warnings = %q; want a warning about the wrong order", report.Warnings)
+	}
+	out := captureStdout(t, func() { r.PrintReportSummary(report) })
+	if want := "WARNING: " + report.Warnings[0]; !strings.Contains(out, want) {
+		t.Errorf("PrintReportSummary() output doesn't contain %q:\n%s", want, out)
+	}
+
+	report, err = r.Compare(after, before)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	if len(report.Warnings) != 0 {
+		t.Errorf("Compare() of Walks in the right order warnings = %q; want none", report.Warnings)
+	}
+
+	// With both stop times recorded, reversed Walks overlap and are rejected.
+	before.StopWalk = newer
+	if _, err := r.Compare(before, after); err == nil || !strings.Contains(err.Error(), "wrong order") {
+		t.Errorf("Compare() of reversed Walks error = %v; want error about the wrong order", err)
+	}
+}
+
 func TestDiffFile(t *testing.T) {
 	testCases := []struct {
 		desc     string
@@ -382,6 +694,38 @@ func TestDiffFile(t *testing.T) {
 				},
 			},
 			wantDiff: "ctime: 2018-12-03 09:56:40 UTC => 2018-12-04 13:43:20 UTC\nuid: 5000 => 0",
+		}, {
+			desc: "file stat changes btime",
+			before: &fspb.File{
+				Version: 1,
+				Path:    "/tmp/testfile",
+				Stat: &fspb.FileStat{
+					Btime: &tspb.Timestamp{Seconds: int64(1543831000)},
+				},
+			},
+			after: &fspb.File{
+				Version: 1,
+				Path:    "/tmp/testfile",
+				Stat: &fspb.FileStat{
+					Btime: &tspb.Timestamp{Seconds: int64(1543931000)},
+				},
+			},
+			wantDiff: "btime: 2018-12-03 09:56:40 UTC => 2018-12-04 13:43:20 UTC",
+		}, {
+			desc: "file stat btime only after",
+			before: &fspb.File{
+				Version: 1,
+				Path:    "/tmp/testfile",
+				Stat:    &fspb.FileStat{},
+			},
+			after: &fspb.File{
+				Version: 1,
+				Path:    "/tmp/testfile",
+				Stat: &fspb.FileStat{
+					Btime: &tspb.Timestamp{Seconds: int64(1543931000)},
+				},
+			},
+			wantDiff: "",
 		}, {
 			desc: "file changes version",
 			before: &fspb.File{
@@ -422,6 +766,17 @@ func TestDiffFile(t *testing.T) {
 				Fingerprint: []*fspb.Fingerprint{{Value: "efgh"}},
 			},
 			wantDiff: "fingerprint: abcd => efgh",
+		}, {
+			desc: "diff tree digests",
+			before: &fspb.File{
+				Path:       "/tmp/testdir",
+				TreeDigest: &fspb.Fingerprint{Value: "abcd"},
+			},
+			after: &fspb.File{
+				Path:       "/tmp/testdir",
+				TreeDigest: &fspb.Fingerprint{Value: "efgh"},
+			},
+			wantDiff: "tree-digest: abcd => efgh",
 		}, {
 			desc: "fingerprint only after",
 			before: &fspb.File{
@@ -453,42 +808,405 @@ func TestDiffFile(t *testing.T) {
 	}
 }
 
-func TestCompare(t *testing.T) {
-	testCases := []struct {
-		desc      string
-		before    *fspb.Walk
-		after     *fspb.Walk
-		deleted   int
-		added     int
-		modified  int
-		wantError bool
+func TestDiff(t *testing.T) {
+	before := &fspb.File{
+		Path: "/tmp/testfile",
+		Info: &fspb.FileInfo{Name: "testfile", Size: 10, Mode: 0644},
+	}
+	after := &fspb.File{
+		Path: "/tmp/testfile",
+		Info: &fspb.FileInfo{Name: "testfile", Size: 20, Mode: 0600},
+	}
+	wantDiffs := []FieldDiff{
+		{Field: "mode", Before: "420", After: "384"},
+		{Field: "size", Before: "10", After: "20"},
+	}
+
+	r := &Reporter{}
+	gotDiffs, err := r.Diff(before, after)
+	if err != nil {
+		t.Fatalf("Diff() error: %v", err)
+	}
+	if diff := cmp.Diff(wantDiffs, gotDiffs); diff != "" {
+		t.Errorf("Diff(): diff (-want +got):\n%s", diff)
+	}
+	wantText := "mode: 420 => 384\nsize: 10 => 20"
+	gotText, err := r.diffFile(before, after)
+	if err != nil {
+		t.Fatalf("diffFile() error: %v", err)
+	}
+	if gotText != wantText {
+		t.Errorf("diffFile() = %q; want %q", gotText, wantText)
+	}
+}
+
+func TestDiffMtimeGranularity(t *testing.T) {
+	mtime := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	newMtime := mtime.Add(300 * time.Millisecond)
+	before := &fspb.File{
+		Path: "/tmp/testfile",
+		Info: &fspb.FileInfo{Modified: tspb.New(mtime)},
+		// Writing the file changes its ctime at the same granularity.
+		Stat: &fspb.FileStat{Mtime: tspb.New(mtime), Ctime: tspb.New(mtime)},
+	}
+	after := &fspb.File{
+		Path: "/tmp/testfile",
+		Info: &fspb.FileInfo{Modified: tspb.New(newMtime)},
+		Stat: &fspb.FileStat{Mtime: tspb.New(newMtime), Ctime: tspb.New(newMtime)},
+	}
+
+	for _, tc := range []struct {
+		granularity string
+		wantDiffs   int
+	}{
+		{granularity: "", wantDiffs: 1},
+		{granularity: "1s", wantDiffs: 0},
+	} {
+		r := &Reporter{config: &fspb.ReportConfig{MtimeGranularity: tc.granularity}}
+		diffs, err := r.Diff(before, after)
+		if err != nil {
+			t.Fatalf("Diff() with mtimeGranularity %q error: %v", tc.granularity, err)
+		}
+		if len(diffs) != tc.wantDiffs {
+			t.Errorf("Diff() with mtimeGranularity %q = %v; want %d diffs", tc.granularity, diffs, tc.wantDiffs)
+		}
+	}
+
+	if _, err := ReporterFromConfigBytes([]byte(`mtimeGranularity = "bogus"`)); err == nil {
+		t.Error("ReporterFromConfigBytes() with an invalid mtimeGranularity succeeded; want error")
+	}
+}
+
+func TestDiffDisplayTimezone(t *testing.T) {
+	mtime := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	before := &fspb.File{Path: "/tmp/testfile", Info: &fspb.FileInfo{Name: "testfile", Modified: tspb.New(mtime)}}
+	after := &fspb.File{Path: "/tmp/testfile", Info: &fspb.FileInfo{Name: "testfile", Modified: tspb.New(mtime.Add(time.Hour))}}
+
+	for _, tc := range []struct {
+		tz   string
+		want FieldDiff
+	}{
+		{tz: "", want: FieldDiff{"mtime", "2020-01-01 12:00:00 UTC", "2020-01-01 13:00:00 UTC"}},
+		{tz: "America/New_York", want: FieldDiff{"mtime", "2020-01-01 07:00:00 EST", "2020-01-01 08:00:00 EST"}},
+		{tz: "Asia/Tokyo", want: FieldDiff{"mtime", "2020-01-01 21:00:00 JST", "2020-01-01 22:00:00 JST"}},
+	} {
+		r, err := ReporterFromConfigBytes([]byte(fmt.Sprintf("displayTimezone = %q", tc.tz)))
+		if err != nil {
+			t.Fatalf("ReporterFromConfigBytes() with displayTimezone %q error: %v", tc.tz, err)
+		}
+		diffs, err := r.Diff(before, after)
+		if err != nil {
+			t.Fatalf("Diff() with displayTimezone %q error: %v", tc.tz, err)
+		}
+		if diff := cmp.Diff([]FieldDiff{tc.want}, diffs); diff != "" {
+			t.Errorf("Diff() with displayTimezone %q: diff (-want +got):\n%s", tc.tz, diff)
+		}
+	}
+
+	// Unknown zones fall back to UTC with a warning.
+	r, err := ReporterFromConfigBytes([]byte(`displayTimezone = "Mars/Olympus_Mons"`))
+	if err != nil {
+		t.Fatalf("ReporterFromConfigBytes() with an unknown displayTimezone error: %v", err)
+	}
+	diffs, err := r.Diff(before, after)
+	if err != nil {
+		t.Fatalf("Diff() with an unknown displayTimezone error: %v", err)
+	}
+	if want := []FieldDiff{{"mtime", "2020-01-01 12:00:00 UTC", "2020-01-01 13:00:00 UTC"}}; !cmp.Equal(want, diffs) {
+		t.Errorf("Diff() with an unknown displayTimezone = %v; want %v", diffs, want)
+	}
+	report, err := r.Compare(&fspb.Walk{Id: "1"}, &fspb.Walk{Id: "2"})
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	if len(report.Warnings) != 1 || !strings.Contains(report.Warnings[0], "Mars/Olympus_Mons") {
+		t.Errorf("Compare() with an unknown displayTimezone warnings = %q; want a warning about it", report.Warnings)
+	}
+}
+
+func TestDiffSubSecondMtime(t *testing.T) {
+	mtime := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	before := &fspb.File{
+		Path: "/tmp/testfile",
+		Info: &fspb.FileInfo{Name: "testfile", Modified: tspb.New(mtime)},
+	}
+	after := &fspb.File{
+		Path: "/tmp/testfile",
+		Info: &fspb.FileInfo{Name: "testfile", Modified: tspb.New(mtime.Add(300 * time.Millisecond))},
+	}
+
+	r := &Reporter{}
+	got, err := r.Diff(before, after)
+	if err != nil {
+		t.Fatalf("Diff() error: %v", err)
+	}
+	want := []FieldDiff{{
+		Field:  "mtime",
+		Before: "2020-01-01 12:00:00.000000000 UTC",
+		After:  "2020-01-01 12:00:00.300000000 UTC",
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Diff(): diff (-want +got):\n%s", diff)
+	}
+
+	// Differences of a second or more keep the shorter format.
+	after.Info.Modified = tspb.New(mtime.Add(1500 * time.Millisecond))
+	got, err = r.Diff(before, after)
+	if err != nil {
+		t.Fatalf("Diff() error: %v", err)
+	}
+	want = []FieldDiff{{Field: "mtime", Before: "2020-01-01 12:00:00 UTC", After: "2020-01-01 12:00:01 UTC"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Diff(): diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestDiffInodeChange(t *testing.T) {
+	before := &fspb.File{Path: "/etc/passwd", Info: &fspb.FileInfo{}, Stat: &fspb.FileStat{Inode: 100}}
+	after := &fspb.File{Path: "/etc/passwd", Info: &fspb.FileInfo{}, Stat: &fspb.FileStat{Inode: 200}}
+
+	for _, tc := range []struct {
+		config *fspb.ReportConfig
+		want   []FieldDiff
 	}{
+		{config: &fspb.ReportConfig{}, want: nil},
 		{
-			desc:   "nil before",
-			before: nil,
-			after: &fspb.Walk{
-				File: []*fspb.File{
-					{Path: "/a/b/c", Info: &fspb.FileInfo{}},
-				},
+			config: &fspb.ReportConfig{ReportInodeChanges: true},
+			want:   []FieldDiff{{Field: "inode", Before: "100", After: "200"}},
+		},
+	} {
+		r := &Reporter{config: tc.config}
+		got, err := r.Diff(before, after)
+		if err != nil {
+			t.Fatalf("Diff() error: %v", err)
+		}
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("Diff() with reportInodeChanges %t: diff (-want +got):\n%s", tc.config.ReportInodeChanges, diff)
+		}
+	}
+}
+
+func TestCompareMetadataOnly(t *testing.T) {
+	before := &fspb.Walk{
+		Id: "1",
+		File: []*fspb.File{
+			{
+				Path:        "/var/lib/app/data",
+				Info:        &fspb.FileInfo{Mode: 0644},
+				Fingerprint: []*fspb.Fingerprint{{Method: fspb.Fingerprint_SHA256, Value: "aaaa"}},
+				TreeDigest:  &fspb.Fingerprint{Method: fspb.Fingerprint_SHA256, Value: "aaaa"},
+			}, {
+				Path:        "/etc/app.conf",
+				Info:        &fspb.FileInfo{Mode: 0644},
+				Fingerprint: []*fspb.Fingerprint{{Method: fspb.Fingerprint_SHA256, Value: "cccc"}},
 			},
-			added: 1,
-		}, {
-			desc: "empty after",
-			before: &fspb.Walk{
-				Id: "1",
-				File: []*fspb.File{
-					{Path: "/a/b/c", Info: &fspb.FileInfo{}},
-				},
+		},
+	}
+	after := &fspb.Walk{
+		Id: "2",
+		File: []*fspb.File{
+			{
+				Path:        "/var/lib/app/data",
+				Info:        &fspb.FileInfo{Mode: 0644},
+				Fingerprint: []*fspb.Fingerprint{{Method: fspb.Fingerprint_SHA256, Value: "bbbb"}},
+				TreeDigest:  &fspb.Fingerprint{Method: fspb.Fingerprint_SHA256, Value: "bbbb"},
+			}, {
+				Path:        "/etc/app.conf",
+				Info:        &fspb.FileInfo{Mode: 0600},
+				Fingerprint: []*fspb.Fingerprint{{Method: fspb.Fingerprint_SHA256, Value: "cccc"}},
 			},
-			after:   &fspb.Walk{Id: "2"},
-			deleted: 1,
-		}, {
-			desc:      "nil before and after",
-			before:    nil,
-			after:     nil,
-			wantError: true,
-		}, {
-			desc: "diffs",
+		},
+	}
+
+	for _, tc := range []struct {
+		metadataOnly bool
+		wantModified []string
+	}{
+		{metadataOnly: false, wantModified: []string{"/etc/app.conf", "/var/lib/app/data"}},
+		{metadataOnly: true, wantModified: []string{"/etc/app.conf"}},
+	} {
+		r := &Reporter{config: &fspb.ReportConfig{MetadataOnly: tc.metadataOnly}}
+		report, err := r.Compare(before, after)
+		if err != nil {
+			t.Fatalf("Compare() error: %v", err)
+		}
+		var modified []string
+		for _, m := range report.Modified {
+			modified = append(modified, m.Before.Path)
+		}
+		if diff := cmp.Diff(tc.wantModified, modified); diff != "" {
+			t.Errorf("Compare() with metadataOnly %t modified: diff (-want +got):\n%s", tc.metadataOnly, diff)
+		}
+	}
+}
+
+func TestCompareIgnoreHashMethodChanges(t *testing.T) {
+	before := &fspb.Walk{
+		Id: "1",
+		File: []*fspb.File{
+			{
+				Path:        "/bin/ls",
+				Info:        &fspb.FileInfo{Mode: 0755},
+				Fingerprint: []*fspb.Fingerprint{{Method: fspb.Fingerprint_SHA256, Value: "ls256"}},
+			}, {
+				Path:        "/bin/sh",
+				Info:        &fspb.FileInfo{Mode: 0755},
+				Fingerprint: []*fspb.Fingerprint{{Method: fspb.Fingerprint_SHA256, Value: "sh256"}},
+			},
+		},
+	}
+	after := &fspb.Walk{
+		Id: "2",
+		File: []*fspb.File{
+			{
+				Path:        "/bin/ls",
+				Info:        &fspb.FileInfo{Mode: 0755},
+				Fingerprint: []*fspb.Fingerprint{{Method: fspb.Fingerprint_SHA512, Value: "ls512"}},
+			}, {
+				Path:        "/bin/sh",
+				Info:        &fspb.FileInfo{Mode: 0777},
+				Fingerprint: []*fspb.Fingerprint{{Method: fspb.Fingerprint_SHA512, Value: "sh512"}},
+			},
+		},
+	}
+
+	r := &Reporter{config: &fspb.ReportConfig{}}
+	report, err := r.Compare(before, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	if len(report.Modified) != 2 {
+		t.Errorf("Compare() modified %d files; want 2 with changed fingerprint methods", len(report.Modified))
+	}
+
+	r.IgnoreHashMethodChanges = true
+	report, err = r.Compare(before, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	if len(report.Modified) != 1 || report.Modified[0].Before.Path != "/bin/sh" {
+		t.Fatalf("Compare() modified = %v; want only /bin/sh", report.Modified)
+	}
+	wantFields := []FieldDiff{{"mode", "493", "511"}}
+	if diff := cmp.Diff(wantFields, report.Modified[0].Fields); diff != "" {
+		t.Errorf("Compare() fields of /bin/sh: diff (-want +got):\n%s", diff)
+	}
+	if len(report.Warnings) != 1 || !strings.Contains(report.Warnings[0], "2 files") {
+		t.Errorf("Compare() warnings = %q; want one about 2 files hashed with a different method", report.Warnings)
+	}
+}
+
+func TestComparePermissionChanges(t *testing.T) {
+	ctime := tspb.New(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	chmodTime := tspb.New(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	before := &fspb.Walk{
+		Id: "1",
+		File: []*fspb.File{
+			{
+				Path:        "/etc/shadow",
+				Info:        &fspb.FileInfo{Mode: 0600},
+				Stat:        &fspb.FileStat{Ctime: ctime},
+				Fingerprint: []*fspb.Fingerprint{{Method: fspb.Fingerprint_SHA256, Value: "aaaa"}},
+			}, {
+				Path:        "/etc/sudoers",
+				Info:        &fspb.FileInfo{Mode: 0440},
+				Stat:        &fspb.FileStat{Ctime: ctime},
+				Fingerprint: []*fspb.Fingerprint{{Method: fspb.Fingerprint_SHA256, Value: "bbbb"}},
+			}, {
+				Path:        "/bin/ls",
+				Info:        &fspb.FileInfo{Mode: 0755},
+				Stat:        &fspb.FileStat{Ctime: ctime},
+				Fingerprint: []*fspb.Fingerprint{{Method: fspb.Fingerprint_SHA256, Value: "cccc"}},
+			},
+		},
+	}
+	after := &fspb.Walk{
+		Id: "2",
+		File: []*fspb.File{
+			{
+				// chmod only, which also updates the change time.
+				Path:        "/etc/shadow",
+				Info:        &fspb.FileInfo{Mode: 0644},
+				Stat:        &fspb.FileStat{Ctime: chmodTime},
+				Fingerprint: []*fspb.Fingerprint{{Method: fspb.Fingerprint_SHA256, Value: "aaaa"}},
+			}, {
+				// chown only.
+				Path:        "/etc/sudoers",
+				Info:        &fspb.FileInfo{Mode: 0440},
+				Stat:        &fspb.FileStat{Uid: 1000, Ctime: chmodTime},
+				Fingerprint: []*fspb.Fingerprint{{Method: fspb.Fingerprint_SHA256, Value: "bbbb"}},
+			}, {
+				// Content changed.
+				Path:        "/bin/ls",
+				Info:        &fspb.FileInfo{Mode: 0755},
+				Stat:        &fspb.FileStat{Ctime: ctime},
+				Fingerprint: []*fspb.Fingerprint{{Method: fspb.Fingerprint_SHA256, Value: "dddd"}},
+			},
+		},
+	}
+
+	for _, reportChanges := range []bool{false, true} {
+		r := &Reporter{config: &fspb.ReportConfig{ReportPermissionChanges: reportChanges}}
+		report, err := r.Compare(before, after)
+		if err != nil {
+			t.Fatalf("Compare() error: %v", err)
+		}
+		if len(report.Modified) != 3 {
+			t.Errorf("Compare() modified %d files; want 3", len(report.Modified))
+		}
+		if n, _ := report.Counter.Get("before-files-permission-changed"); n != 2 {
+			t.Errorf("before-files-permission-changed = %d; want 2", n)
+		}
+		var got []string
+		for _, a := range report.PermissionChanges {
+			got = append(got, a.Before.Path)
+		}
+		var want []string
+		if reportChanges {
+			want = []string{"/etc/shadow", "/etc/sudoers"}
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Compare() with reportPermissionChanges %t permission changes: diff (-want +got):\n%s", reportChanges, diff)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		before    *fspb.Walk
+		after     *fspb.Walk
+		deleted   int
+		added     int
+		modified  int
+		wantError bool
+	}{
+		{
+			desc:   "nil before",
+			before: nil,
+			after: &fspb.Walk{
+				File: []*fspb.File{
+					{Path: "/a/b/c", Info: &fspb.FileInfo{}},
+				},
+			},
+			added: 1,
+		}, {
+			desc: "empty after",
+			before: &fspb.Walk{
+				Id: "1",
+				File: []*fspb.File{
+					{Path: "/a/b/c", Info: &fspb.FileInfo{}},
+				},
+			},
+			after:   &fspb.Walk{Id: "2"},
+			deleted: 1,
+		}, {
+			desc:      "nil before and after",
+			before:    nil,
+			after:     nil,
+			wantError: true,
+		}, {
+			desc: "diffs",
 			before: &fspb.Walk{
 				Id: "1",
 				File: []*fspb.File{
@@ -566,3 +1284,1099 @@ func TestCompare(t *testing.T) {
 		})
 	}
 }
+
+func TestWalkFileNotifications(t *testing.T) {
+	wf := &WalkFile{
+		Walk: &fspb.Walk{
+			Notification: []*fspb.Notification{
+				{Severity: fspb.Notification_INFO, Path: "/a", Message: "info"},
+				{Severity: fspb.Notification_ERROR, Path: "/b", Message: "error"},
+				{Severity: fspb.Notification_WARNING, Path: "/c", Message: "warning"},
+				{Severity: fspb.Notification_UNKNOWN, Path: "/d", Message: "unknown"},
+			},
+		},
+	}
+	testCases := []struct {
+		minSeverity fspb.Notification_Severity
+		wantPaths   []string
+	}{
+		{
+			minSeverity: fspb.Notification_UNKNOWN,
+			wantPaths:   []string{"/a", "/b", "/c", "/d"},
+		}, {
+			minSeverity: fspb.Notification_INFO,
+			wantPaths:   []string{"/a", "/b", "/c"},
+		}, {
+			minSeverity: fspb.Notification_WARNING,
+			wantPaths:   []string{"/b", "/c"},
+		}, {
+			minSeverity: fspb.Notification_ERROR,
+			wantPaths:   []string{"/b"},
+		},
+	}
+	for _, tc := range testCases {
+		var gotPaths []string
+		for _, n := range wf.Notifications(tc.minSeverity) {
+			gotPaths = append(gotPaths, n.Path)
+		}
+		if diff := cmp.Diff(tc.wantPaths, gotPaths); diff != "" {
+			t.Errorf("Notifications(%s): diff (-want +got):\n%s", tc.minSeverity, diff)
+		}
+	}
+
+	if !wf.HasErrors() {
+		t.Error("HasErrors() = false; want true")
+	}
+	wf.Walk.Notification = wf.Walk.Notification[2:]
+	if wf.HasErrors() {
+		t.Error("HasErrors() = true; want false")
+	}
+}
+
+func TestPrintStatsSummary(t *testing.T) {
+	r := &Reporter{config: &fspb.ReportConfig{}}
+	report, err := r.Compare(&fspb.Walk{
+		Id: "1",
+		File: []*fspb.File{
+			{Path: "/deleted/file", Info: &fspb.FileInfo{}},
+			{Path: "/modified/file", Info: &fspb.FileInfo{Size: 1}},
+		},
+	}, &fspb.Walk{
+		Id: "2",
+		File: []*fspb.File{
+			{Path: "/added/file1", Info: &fspb.FileInfo{}},
+			{Path: "/added/file2", Info: &fspb.FileInfo{}},
+			{Path: "/modified/file", Info: &fspb.FileInfo{Size: 2}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+
+	out := captureStdout(t, func() { r.PrintStatsSummary(report) })
+	for _, want := range []string{"Added: 2\n", "Removed: 1\n", "Modified: 1\n", "Reporting Errors: 0\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("PrintStatsSummary() output doesn't contain %q:\n%s", want, out)
+		}
+	}
+	for _, path := range []string{"/added/", "/deleted/", "/modified/"} {
+		if strings.Contains(out, path) {
+			t.Errorf("PrintStatsSummary() output contains path %q:\n%s", path, out)
+		}
+	}
+}
+
+func TestCompareByteCounters(t *testing.T) {
+	r := &Reporter{config: &fspb.ReportConfig{}}
+	report, err := r.Compare(&fspb.Walk{
+		Id: "1",
+		File: []*fspb.File{
+			{Path: "/deleted", Info: &fspb.FileInfo{Size: 100}},
+			{Path: "/grown", Info: &fspb.FileInfo{Size: 10}},
+			{Path: "/shrunk", Info: &fspb.FileInfo{Size: 50}},
+			{Path: "/unchanged", Info: &fspb.FileInfo{Size: 1000}},
+		},
+	}, &fspb.Walk{
+		Id: "2",
+		File: []*fspb.File{
+			{Path: "/added1", Info: &fspb.FileInfo{Size: 7}},
+			{Path: "/added2", Info: &fspb.FileInfo{Size: 3}},
+			{Path: "/grown", Info: &fspb.FileInfo{Size: 30}},
+			{Path: "/shrunk", Info: &fspb.FileInfo{Size: 5}},
+			{Path: "/unchanged", Info: &fspb.FileInfo{Size: 1000}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	for metric, want := range map[string]int64{
+		"added-bytes":          10,
+		"deleted-bytes":        100,
+		"modified-bytes-delta": -25,
+	} {
+		if got, _ := report.Counter.Get(metric); got != want {
+			t.Errorf("Compare() counter %q = %d; want %d", metric, got, want)
+		}
+	}
+}
+
+func TestCompareWithOptions(t *testing.T) {
+	before := &fspb.Walk{
+		Id: "1",
+		File: []*fspb.File{
+			{Path: "/tmp/mtime", Info: &fspb.FileInfo{Modified: &tspb.Timestamp{Seconds: 1}}},
+			{Path: "/tmp/size", Info: &fspb.FileInfo{Size: 1, Modified: &tspb.Timestamp{Seconds: 1}}},
+			{Path: "/var/cache/size", Info: &fspb.FileInfo{Size: 1}},
+		},
+	}
+	after := &fspb.Walk{
+		Id: "2",
+		File: []*fspb.File{
+			{Path: "/tmp/mtime", Info: &fspb.FileInfo{Modified: &tspb.Timestamp{Seconds: 2}}},
+			{Path: "/tmp/size", Info: &fspb.FileInfo{Size: 2, Modified: &tspb.Timestamp{Seconds: 2}}},
+			{Path: "/var/cache/size", Info: &fspb.FileInfo{Size: 2}},
+		},
+	}
+
+	r := &Reporter{config: &fspb.ReportConfig{}}
+	report, err := r.Compare(before, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	if len(report.Modified) != 3 {
+		t.Errorf("Compare() modified = %d; want 3", len(report.Modified))
+	}
+
+	report, err = r.CompareWithOptions(before, after, CompareOptions{
+		Exclude:      []string{"/var/cache/"},
+		IgnoreFields: []string{"mtime"},
+	})
+	if err != nil {
+		t.Fatalf("CompareWithOptions() error: %v", err)
+	}
+	var gotModified []string
+	for _, m := range report.Modified {
+		gotModified = append(gotModified, m.After.Path)
+		if m.Diff != "size: 1 => 2" {
+			t.Errorf("CompareWithOptions() diff of %q = %q; want only the size", m.After.Path, m.Diff)
+		}
+	}
+	if diff := cmp.Diff([]string{"/tmp/size"}, gotModified); diff != "" {
+		t.Errorf("CompareWithOptions() modified: diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestComparePathTransform(t *testing.T) {
+	before := &fspb.Walk{
+		Id: "1",
+		File: []*fspb.File{
+			{Path: "/opt/app-1.2/", Info: &fspb.FileInfo{IsDir: true}},
+			{Path: "/opt/app-1.2/bin/app", Info: &fspb.FileInfo{Size: 1}},
+			{Path: "/opt/app-1.2/README", Info: &fspb.FileInfo{Size: 1}},
+		},
+	}
+	after := &fspb.Walk{
+		Id: "2",
+		File: []*fspb.File{
+			{Path: "/opt/app-1.3/", Info: &fspb.FileInfo{IsDir: true}},
+			{Path: "/opt/app-1.3/bin/app", Info: &fspb.FileInfo{Size: 2}},
+			{Path: "/opt/app-1.3/README", Info: &fspb.FileInfo{Size: 1}},
+		},
+	}
+	version := regexp.MustCompile(`-[0-9.]+(/|$)`)
+
+	r := &Reporter{
+		config: &fspb.ReportConfig{},
+		PathTransform: func(p string) string {
+			return version.ReplaceAllString(p, "$1")
+		},
+	}
+	report, err := r.Compare(before, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	if len(report.Added) != 0 || len(report.Deleted) != 0 {
+		t.Errorf("Compare() added %d and deleted %d files; want none", len(report.Added), len(report.Deleted))
+	}
+	var gotModified []string
+	for _, m := range report.Modified {
+		gotModified = append(gotModified, fmt.Sprintf("%s: %s", m.After.Path, m.Diff))
+	}
+	if diff := cmp.Diff([]string{"/opt/app/bin/app: size: 1 => 2"}, gotModified); diff != "" {
+		t.Errorf("Compare() modified: diff (-want +got):\n%s", diff)
+	}
+	if len(report.Warnings) != 0 {
+		t.Errorf("Compare() warnings = %q; want none", report.Warnings)
+	}
+
+	// Two versions installed side by side end up at the same path.
+	after.File = append(after.File, &fspb.File{Path: "/opt/app-1.2/README", Info: &fspb.FileInfo{Size: 1}})
+	report, err = r.Compare(before, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	want := []string{`PathTransform maps different files of the after Walk to the same path (e.g. "/opt/app-1.3/README" and "/opt/app-1.2/README" to "/opt/app/README"), only the last file of each such path is compared`}
+	if diff := cmp.Diff(want, report.Warnings); diff != "" {
+		t.Errorf("Compare() warnings: diff (-want +got):\n%s", diff)
+	}
+	if err := report.Invert(); err != nil {
+		t.Fatalf("Invert() error: %v", err)
+	}
+	want = []string{strings.Replace(want[0], "after Walk", "before Walk", 1)}
+	if diff := cmp.Diff(want, report.Warnings); diff != "" {
+		t.Errorf("Invert() warnings: diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestCompareModifiedOnly(t *testing.T) {
+	before := &fspb.Walk{
+		Id: "1",
+		File: []*fspb.File{
+			{Path: "/var/log/old.log", Info: &fspb.FileInfo{Size: 1}},
+			{Path: "/etc/hosts", Info: &fspb.FileInfo{Size: 1}},
+		},
+	}
+	after := &fspb.Walk{
+		Id: "2",
+		File: []*fspb.File{
+			{Path: "/var/log/new.log", Info: &fspb.FileInfo{Size: 1}},
+			{Path: "/etc/hosts", Info: &fspb.FileInfo{Size: 2}},
+		},
+	}
+
+	r := &Reporter{config: &fspb.ReportConfig{ModifiedOnly: true}}
+	report, err := r.Compare(before, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	if len(report.Added) != 0 || len(report.Deleted) != 0 {
+		t.Errorf("Compare() added %d and deleted %d files; want none", len(report.Added), len(report.Deleted))
+	}
+	if len(report.Modified) != 1 || report.Modified[0].After.Path != "/etc/hosts" {
+		t.Errorf("Compare() modified = %v; want only /etc/hosts", report.Modified)
+	}
+	for _, c := range []string{"after-files-created", "before-files-removed"} {
+		if n, _ := report.Counter.Get(c); n != 1 {
+			t.Errorf("%s = %d; want 1", c, n)
+		}
+	}
+}
+
+func TestReportInvert(t *testing.T) {
+	before := &fspb.Walk{
+		Id: "1",
+		File: []*fspb.File{
+			{Path: "/var/log/old.log", Info: &fspb.FileInfo{Size: 1}},
+			{Path: "/etc/hosts", Info: &fspb.FileInfo{Size: 10, Mode: 0644}},
+		},
+	}
+	after := &fspb.Walk{
+		Id: "2",
+		File: []*fspb.File{
+			{Path: "/var/log/new.log", Info: &fspb.FileInfo{Size: 2}},
+			{Path: "/etc/hosts", Info: &fspb.FileInfo{Size: 0, Mode: 0600}},
+		},
+	}
+
+	r := &Reporter{config: &fspb.ReportConfig{}}
+	report, err := r.Compare(before, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	if err := report.Invert(); err != nil {
+		t.Fatalf("Invert() error: %v", err)
+	}
+
+	if report.WalkBefore != after || report.WalkAfter != before {
+		t.Errorf("Invert() didn't swap the walks")
+	}
+	if len(report.Added) != 1 || report.Added[0].After.Path != "/var/log/old.log" {
+		t.Errorf("Invert() added = %v; want only /var/log/old.log", report.Added)
+	}
+	if len(report.Deleted) != 1 || report.Deleted[0].Before.Path != "/var/log/new.log" {
+		t.Errorf("Invert() deleted = %v; want only /var/log/new.log", report.Deleted)
+	}
+	if len(report.Modified) != 1 {
+		t.Fatalf("Invert() modified %d files; want 1", len(report.Modified))
+	}
+	m := report.Modified[0]
+	if m.Before.Info.Size != 0 || m.After.Info.Size != 10 {
+		t.Errorf("Invert() modified entry has sizes %d => %d; want 0 => 10", m.Before.Info.Size, m.After.Info.Size)
+	}
+	// truncated-to-empty only exists in the original direction.
+	wantFields := []FieldDiff{
+		{"mode", "384", "420"},
+		{"size", "0", "10"},
+	}
+	if diff := cmp.Diff(wantFields, m.Fields); diff != "" {
+		t.Errorf("Invert() fields: diff (-want +got):\n%s", diff)
+	}
+	if want := "mode: 384 => 420\nsize: 0 => 10"; m.Diff != want {
+		t.Errorf("Invert() diff = %q; want %q", m.Diff, want)
+	}
+	for c, want := range map[string]int64{
+		"after-files-created":        1,
+		"before-files-removed":       1,
+		"added-bytes":                1,
+		"deleted-bytes":              2,
+		"modified-bytes-delta":       10,
+		"before-files-high-severity": 0,
+	} {
+		if n, _ := report.Counter.Get(c); n != want {
+			t.Errorf("%s = %d; want %d", c, n, want)
+		}
+	}
+
+	if err := (&Report{Baseline: true}).Invert(); err == nil {
+		t.Errorf("Invert() of a baseline report: want error")
+	}
+}
+
+func TestCompareCriticalPaths(t *testing.T) {
+	before := &fspb.Walk{
+		Id: "1",
+		File: []*fspb.File{
+			{Path: "/etc/sudoers", Info: &fspb.FileInfo{Size: 1}},
+			{Path: "/boot/vmlinuz-6.1", Info: &fspb.FileInfo{Size: 1}},
+			{Path: "/etc/motd", Info: &fspb.FileInfo{Size: 1}},
+		},
+	}
+	after := &fspb.Walk{
+		Id: "2",
+		File: []*fspb.File{
+			{Path: "/etc/sudoers", Info: &fspb.FileInfo{Size: 1}},
+			{Path: "/boot/vmlinuz-6.1", Info: &fspb.FileInfo{Size: 1}},
+			{Path: "/etc/motd", Info: &fspb.FileInfo{Size: 2}},
+		},
+	}
+	config := &fspb.ReportConfig{CriticalPaths: []string{"/etc/sudoers", "/boot/vmlinuz-*"}}
+	r := &Reporter{config: config}
+
+	report, err := r.Compare(before, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	if report.CriticalChangeDetected {
+		t.Error("Compare() CriticalChangeDetected = true with only a non-critical file modified; want false")
+	}
+
+	// Modify sudoers and remove the kernel.
+	after = &fspb.Walk{
+		Id: "3",
+		File: []*fspb.File{
+			{Path: "/etc/sudoers", Info: &fspb.FileInfo{Size: 2}},
+			{Path: "/etc/motd", Info: &fspb.FileInfo{Size: 2}},
+		},
+	}
+	report, err = r.Compare(before, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	if !report.CriticalChangeDetected {
+		t.Error("Compare() CriticalChangeDetected = false; want true")
+	}
+	if n, _ := report.Counter.Get("before-files-critical"); n != 2 {
+		t.Errorf("before-files-critical = %d; want 2 (modified sudoers, removed vmlinuz)", n)
+	}
+	out := captureStdout(t, func() { r.PrintDiffSummary(report) })
+	if want := "CRITICAL: 2 critical files"; !strings.Contains(out, want) {
+		t.Errorf("PrintDiffSummary() output doesn't contain %q:\n%s", want, out)
+	}
+}
+
+func TestCompareAny(t *testing.T) {
+	golden1 := &fspb.Walk{
+		Id: "golden1",
+		File: []*fspb.File{
+			{Path: "/bin/sh", Info: &fspb.FileInfo{Size: 1}},
+		},
+	}
+	golden2 := &fspb.Walk{
+		Id: "golden2",
+		File: []*fspb.File{
+			{Path: "/bin/sh", Info: &fspb.FileInfo{Size: 2}},
+			{Path: "/bin/bash", Info: &fspb.FileInfo{Size: 3}},
+		},
+	}
+	after := &fspb.Walk{
+		Id: "after",
+		File: []*fspb.File{
+			{Path: "/bin/sh", Info: &fspb.FileInfo{Size: 2}},
+			{Path: "/bin/bash", Info: &fspb.FileInfo{Size: 3}},
+		},
+	}
+
+	r := &Reporter{config: &fspb.ReportConfig{}}
+	report, err := r.CompareAny(after, []*fspb.Walk{golden1, golden2})
+	if err != nil {
+		t.Fatalf("CompareAny() error: %v", err)
+	}
+	if !report.Empty() {
+		t.Errorf("CompareAny() report isn't empty: %+v", report)
+	}
+	if report.WalkBefore != golden2 {
+		t.Errorf("CompareAny() compared against %q; want %q", report.WalkBefore.GetId(), golden2.Id)
+	}
+
+	if _, err := r.CompareAny(after, nil); err == nil {
+		t.Error("CompareAny() without baselines succeeded; want error")
+	}
+}
+
+func TestWalkFileFilterFiles(t *testing.T) {
+	before := &WalkFile{
+		Path: "before.pb",
+		Walk: &fspb.Walk{
+			Id:       "before",
+			Hostname: "host",
+			File: []*fspb.File{
+				{Path: "/etc/", Info: &fspb.FileInfo{IsDir: true}},
+				{Path: "/etc/passwd", Info: &fspb.FileInfo{Size: 1}},
+				{Path: "/var/log/syslog", Info: &fspb.FileInfo{Size: 1}},
+			},
+		},
+	}
+	after := &WalkFile{
+		Path: "after.pb",
+		Walk: &fspb.Walk{
+			Id:       "after",
+			Hostname: "host",
+			File: []*fspb.File{
+				{Path: "/etc/", Info: &fspb.FileInfo{IsDir: true}},
+				{Path: "/etc/passwd", Info: &fspb.FileInfo{Size: 1}},
+				{Path: "/var/log/syslog", Info: &fspb.FileInfo{Size: 2}},
+				{Path: "/var/log/messages", Info: &fspb.FileInfo{Size: 2}},
+			},
+		},
+	}
+	underEtc := func(f *fspb.File) bool { return strings.HasPrefix(f.Path, "/etc/") }
+
+	fb := before.FilterFiles(underEtc)
+	fa := after.FilterFiles(underEtc)
+	if fb.Walk.Id != "before" || fb.Walk.Hostname != "host" || fb.Path != "before.pb" {
+		t.Errorf("FilterFiles() didn't preserve the metadata: %+v", fb)
+	}
+	if len(fb.Walk.File) != 2 || len(before.Walk.File) != 3 {
+		t.Errorf("FilterFiles() = %d files (original %d); want 2 (original 3)", len(fb.Walk.File), len(before.Walk.File))
+	}
+
+	r := &Reporter{config: &fspb.ReportConfig{}}
+	report, err := r.Compare(fb.Walk, fa.Walk)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	if !report.Empty() {
+		t.Errorf("Compare() of the filtered walks isn't empty: %+v", report)
+	}
+}
+
+func TestPrintDiffSummaryMaxDiffs(t *testing.T) {
+	after := &fspb.Walk{Id: "1"}
+	for i := 0; i < 5; i++ {
+		after.File = append(after.File, &fspb.File{Path: fmt.Sprintf("/added/file%d", i), Info: &fspb.FileInfo{}})
+	}
+	r := &Reporter{config: &fspb.ReportConfig{}, MaxDiffs: 2}
+	report, err := r.Compare(&fspb.Walk{Id: "0"}, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	if len(report.Added) != 5 {
+		t.Errorf("len(report.Added) = %d; want 5", len(report.Added))
+	}
+
+	out := captureStdout(t, func() { r.PrintDiffSummary(report) })
+	want := "Added (5):\n/added/file0\n/added/file1\n... and 3 more\n"
+	if !strings.Contains(out, want) {
+		t.Errorf("PrintDiffSummary() output doesn't contain %q:\n%s", want, out)
+	}
+	if strings.Contains(out, "/added/file2") {
+		t.Errorf("PrintDiffSummary() output isn't truncated:\n%s", out)
+	}
+}
+
+func TestPrintDiffSummaryVerboseProto(t *testing.T) {
+	before := &fspb.Walk{Id: "1", File: []*fspb.File{
+		{Path: "/etc/hosts", Info: &fspb.FileInfo{Name: "hosts", Size: 1}, Stat: &fspb.FileStat{Inode: 42}},
+	}}
+	after := &fspb.Walk{Id: "2", File: []*fspb.File{
+		{Path: "/etc/hosts", Info: &fspb.FileInfo{Name: "hosts", Size: 2}, Stat: &fspb.FileStat{Inode: 42}},
+	}}
+
+	for _, verboseProto := range []bool{false, true} {
+		r := &Reporter{config: &fspb.ReportConfig{}, VerboseProto: verboseProto}
+		report, err := r.Compare(before, after)
+		if err != nil {
+			t.Fatalf("Compare() error: %v", err)
+		}
+		if len(report.Modified) != 1 {
+			t.Fatalf("Compare() modified %d files; want 1", len(report.Modified))
+		}
+		m := report.Modified[0]
+		out := captureStdout(t, func() { r.PrintDiffSummary(report) })
+		for _, want := range []string{
+			"Before:\n" + prototext.Format(m.Before),
+			"After:\n" + prototext.Format(m.After),
+		} {
+			if got := strings.Contains(out, want); got != verboseProto {
+				t.Errorf("PrintDiffSummary() with VerboseProto %t output contains %q: %t; want %t:\n%s", verboseProto, want, got, verboseProto, out)
+			}
+		}
+	}
+}
+
+func TestCompareBaseline(t *testing.T) {
+	r := &Reporter{config: &fspb.ReportConfig{}}
+	after := &fspb.Walk{
+		Id: "1",
+		File: []*fspb.File{
+			{Path: "/a", Info: &fspb.FileInfo{}},
+			{Path: "/b", Info: &fspb.FileInfo{}},
+		},
+	}
+
+	report, err := r.Compare(nil, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	if !report.Baseline {
+		t.Error("report.Baseline = false; want true")
+	}
+	if n := len(report.Added); n != 2 {
+		t.Errorf("len(report.Added) = %d; want 2", n)
+	}
+	if n, _ := report.Counter.Get("after-files-baseline"); n != 2 {
+		t.Errorf("report.Counter.Get(after-files-baseline) = %d; want 2", n)
+	}
+	if _, ok := report.Counter.Get("after-files-created"); ok {
+		t.Error("report.Counter.Get(after-files-created) is set in baseline mode")
+	}
+	out := captureStdout(t, func() {
+		r.PrintReportSummary(report)
+		r.PrintDiffSummary(report)
+	})
+	for _, want := range []string{"Mode: baseline creation", "Baseline Inventory (2):\n/a\n/b\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("summary output doesn't contain %q:\n%s", want, out)
+		}
+	}
+
+	report, err = r.Compare(&fspb.Walk{Id: "0"}, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	if report.Baseline {
+		t.Error("report.Baseline = true; want false")
+	}
+	if n, _ := report.Counter.Get("after-files-created"); n != 2 {
+		t.Errorf("report.Counter.Get(after-files-created) = %d; want 2", n)
+	}
+	out = captureStdout(t, func() { r.PrintDiffSummary(report) })
+	if !strings.Contains(out, "Added (2):") {
+		t.Errorf("PrintDiffSummary() output doesn't contain Added section:\n%s", out)
+	}
+}
+
+func TestCompareTruncatedToEmpty(t *testing.T) {
+	r := &Reporter{config: &fspb.ReportConfig{}}
+	report, err := r.Compare(&fspb.Walk{
+		Id: "1",
+		File: []*fspb.File{
+			{Path: "/var/log/auth.log", Info: &fspb.FileInfo{Size: 1024}},
+			{Path: "/var/log/shrunk.log", Info: &fspb.FileInfo{Size: 1024}},
+		},
+	}, &fspb.Walk{
+		Id: "2",
+		File: []*fspb.File{
+			{Path: "/var/log/auth.log", Info: &fspb.FileInfo{Size: 0}},
+			{Path: "/var/log/shrunk.log", Info: &fspb.FileInfo{Size: 10}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+
+	truncated := map[string]bool{}
+	for _, m := range report.Modified {
+		for _, d := range m.Fields {
+			if d.Field == "truncated-to-empty" && d.HighSeverity() {
+				truncated[m.After.Path] = true
+			}
+		}
+	}
+	want := map[string]bool{"/var/log/auth.log": true}
+	if diff := cmp.Diff(want, truncated); diff != "" {
+		t.Errorf("Compare() truncated files: diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestCompareWorldWritable(t *testing.T) {
+	before := &fspb.Walk{
+		Id: "1",
+		File: []*fspb.File{
+			{Path: "/etc/passwd", Info: &fspb.FileInfo{Mode: 0644}},
+			{Path: "/etc/group", Info: &fspb.FileInfo{Mode: 0644}},
+			{Path: "/tmp/", Info: &fspb.FileInfo{Mode: uint32(os.ModeDir | os.ModeSticky | 0777), IsDir: true}},
+		},
+	}
+	after := &fspb.Walk{
+		Id: "2",
+		File: []*fspb.File{
+			{Path: "/etc/passwd", Info: &fspb.FileInfo{Mode: 0646}},
+			{Path: "/etc/group", Info: &fspb.FileInfo{Mode: 0664}},
+			{Path: "/tmp/", Info: &fspb.FileInfo{Mode: uint32(os.ModeDir | os.ModeSticky | 0777), IsDir: true}},
+			{Path: "/etc/link", Info: &fspb.FileInfo{Mode: uint32(os.ModeSymlink | 0777)}},
+		},
+	}
+
+	for _, tc := range []struct {
+		desc              string
+		config            *fspb.ReportConfig
+		wantFlagged       []string
+		wantWorldWritable []string
+	}{
+		{
+			desc:   "disabled",
+			config: &fspb.ReportConfig{},
+		}, {
+			desc:        "flag changes",
+			config:      &fspb.ReportConfig{FlagWorldWritable: true},
+			wantFlagged: []string{"/etc/passwd"},
+		}, {
+			desc:              "report all",
+			config:            &fspb.ReportConfig{ReportWorldWritable: true},
+			wantWorldWritable: []string{"/etc/passwd", "/tmp/"},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			r := &Reporter{config: tc.config}
+			report, err := r.Compare(before, after)
+			if err != nil {
+				t.Fatalf("Compare() error: %v", err)
+			}
+
+			var flagged []string
+			for _, m := range report.Modified {
+				for _, d := range m.Fields {
+					if d.Field == "perm-other" {
+						if got, want := d.String(), "perm-other: -w => +w"; got != want || !d.HighSeverity() {
+							t.Errorf("diff of %q = %q (high severity %t); want %q (high severity)", m.After.Path, got, d.HighSeverity(), want)
+						}
+						flagged = append(flagged, m.After.Path)
+					}
+				}
+			}
+			if diff := cmp.Diff(tc.wantFlagged, flagged); diff != "" {
+				t.Errorf("Compare() flagged files: diff (-want +got):\n%s", diff)
+			}
+
+			var worldWritable []string
+			for _, a := range report.WorldWritable {
+				worldWritable = append(worldWritable, a.After.Path)
+			}
+			if diff := cmp.Diff(tc.wantWorldWritable, worldWritable); diff != "" {
+				t.Errorf("Compare() world-writable files: diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCompareFlagPermissionLoosening(t *testing.T) {
+	before := &fspb.Walk{
+		Id: "1",
+		File: []*fspb.File{
+			{Path: "/etc/shadow", Info: &fspb.FileInfo{Mode: 0600}},
+			{Path: "/etc/hosts", Info: &fspb.FileInfo{Mode: 0644}},
+			{Path: "/usr/bin/tool", Info: &fspb.FileInfo{Mode: 0755}},
+			{Path: "/etc/link", Info: &fspb.FileInfo{Mode: uint32(os.ModeSymlink | 0700)}},
+		},
+	}
+	after := &fspb.Walk{
+		Id: "2",
+		File: []*fspb.File{
+			{Path: "/etc/shadow", Info: &fspb.FileInfo{Mode: 0644}},
+			{Path: "/etc/hosts", Info: &fspb.FileInfo{Mode: 0600}},
+			{Path: "/usr/bin/tool", Info: &fspb.FileInfo{Mode: uint32(os.ModeSetuid | 0755)}},
+			{Path: "/etc/link", Info: &fspb.FileInfo{Mode: uint32(os.ModeSymlink | 0777)}},
+		},
+	}
+
+	for _, flag := range []bool{false, true} {
+		r := &Reporter{config: &fspb.ReportConfig{FlagPermissionLoosening: flag}}
+		report, err := r.Compare(before, after)
+		if err != nil {
+			t.Fatalf("Compare() error: %v", err)
+		}
+
+		got := map[string]string{}
+		for _, m := range report.Modified {
+			if !hasHighSeverity(m.Fields) {
+				got[m.After.Path] = "normal"
+				continue
+			}
+			for _, d := range m.Fields {
+				if d.HighSeverity() {
+					got[m.After.Path] = d.String()
+				}
+			}
+		}
+		want := map[string]string{
+			"/etc/shadow":   "normal",
+			"/etc/hosts":    "normal",
+			"/usr/bin/tool": "normal",
+			"/etc/link":     "normal",
+		}
+		if flag {
+			want["/etc/shadow"] = "perm-loosened: 0600 => 0644"
+			want["/usr/bin/tool"] = "perm-loosened: 0755 => 04755"
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("flagPermissionLoosening=%v: Compare() severities: diff (-want +got):\n%s", flag, diff)
+		}
+	}
+}
+
+func TestCompareTypeChange(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"file": "content", "target": "target"})
+	pol := &fspb.Policy{Include: []string{root}, MaxHashFileSize: 1024}
+	before := runWalk(t, pol)
+
+	path := filepath.Join(root, "file")
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(root, "target"), path); err != nil {
+		t.Fatal(err)
+	}
+	after := runWalk(t, pol)
+
+	r := &Reporter{config: &fspb.ReportConfig{}}
+	report, err := r.Compare(before, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	var typeDiff *FieldDiff
+	for _, m := range report.Modified {
+		if m.After.Path != path {
+			continue
+		}
+		for i, d := range m.Fields {
+			if d.Field == "type" {
+				typeDiff = &m.Fields[i]
+			}
+		}
+	}
+	want := &FieldDiff{Field: "type", Before: "regular", After: "symlink"}
+	if diff := cmp.Diff(want, typeDiff); diff != "" {
+		t.Fatalf("Compare() type diff of %q: diff (-want +got):\n%s", path, diff)
+	}
+	if !typeDiff.HighSeverity() {
+		t.Errorf("%v.HighSeverity() = false; want true", typeDiff)
+	}
+	if n, _ := report.Counter.Get("before-files-high-severity"); n != 1 {
+		t.Errorf("before-files-high-severity = %d; want 1", n)
+	}
+}
+
+func TestCompareEntryCount(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "dir")
+	writeFiles(t, root, map[string]string{"dir/a": "a", "dir/excluded": "excluded"})
+	pol := &fspb.Policy{
+		Include:          []string{root},
+		Exclude:          []string{filepath.Join(dir, "excluded")},
+		RecordEntryCount: true,
+	}
+	before := runWalk(t, pol)
+	writeFiles(t, root, map[string]string{"dir/b": "b"})
+	after := runWalk(t, pol)
+
+	r := &Reporter{config: &fspb.ReportConfig{}}
+	report, err := r.Compare(before, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	var entriesDiff *FieldDiff
+	for _, m := range report.Modified {
+		if m.After.Path != NormalizePath(dir, true) {
+			continue
+		}
+		for i, d := range m.Fields {
+			if d.Field == "entries" {
+				entriesDiff = &m.Fields[i]
+			}
+		}
+	}
+	// Excluded entries are counted as well.
+	want := &FieldDiff{Field: "entries", Before: "2", After: "3"}
+	if diff := cmp.Diff(want, entriesDiff); diff != "" {
+		t.Errorf("Compare() entries diff of %q: diff (-want +got):\n%s", dir, diff)
+	}
+
+	// Walks without entry counts don't report a diff.
+	pol.RecordEntryCount = false
+	after = runWalk(t, pol)
+	report, err = r.Compare(before, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	for _, m := range report.Modified {
+		for _, d := range m.Fields {
+			if d.Field == "entries" {
+				t.Errorf("Compare() reported %q for %q without entry counts in the after walk", d, m.After.Path)
+			}
+		}
+	}
+}
+
+func TestCompareDuplicatePaths(t *testing.T) {
+	before := &fspb.Walk{
+		Id: "before",
+		File: []*fspb.File{
+			{Path: "/etc", Info: &fspb.FileInfo{IsDir: true}},
+			{Path: "/etc/hosts", Info: &fspb.FileInfo{Size: 1}},
+		},
+	}
+	// Overlapping includes recorded both entries twice.
+	after := &fspb.Walk{
+		Id: "after",
+		File: []*fspb.File{
+			{Path: "/etc", Info: &fspb.FileInfo{IsDir: true}},
+			{Path: "/etc/hosts", Info: &fspb.FileInfo{Size: 1}},
+			{Path: "/etc/", Info: &fspb.FileInfo{IsDir: true}},
+			{Path: "/etc/hosts", Info: &fspb.FileInfo{Size: 1}},
+		},
+	}
+	if diff := cmp.Diff([]string{"/etc/", "/etc/hosts"}, duplicatePaths(after.File, nil)); diff != "" {
+		t.Errorf("duplicatePaths(): diff (-want +got):\n%s", diff)
+	}
+	if got := duplicatePaths(before.File, nil); len(got) != 0 {
+		t.Errorf("duplicatePaths() = %q; want none", got)
+	}
+
+	r := &Reporter{config: &fspb.ReportConfig{}}
+	report, err := r.Compare(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{`the after Walk records 2 paths more than once (e.g. "/etc/"), only the last entry of each is compared`}
+	if diff := cmp.Diff(want, report.Warnings); diff != "" {
+		t.Errorf("Compare() warnings: diff (-want +got):\n%s", diff)
+	}
+	if err := report.Invert(); err != nil {
+		t.Fatal(err)
+	}
+	want = []string{`the before Walk records 2 paths more than once (e.g. "/etc/"), only the last entry of each is compared`}
+	if diff := cmp.Diff(want, report.Warnings); diff != "" {
+		t.Errorf("inverted warnings: diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestPrintDiffSummarySuppressRecurringNotifications(t *testing.T) {
+	recurring := &fspb.Notification{Severity: fspb.Notification_WARNING, Path: "/root/secret", Message: "permission denied"}
+	before := &fspb.Walk{Id: "0", Notification: []*fspb.Notification{recurring}}
+	after := &fspb.Walk{Id: "1", Notification: []*fspb.Notification{
+		recurring,
+		{Severity: fspb.Notification_ERROR, Path: "/var/data", Message: "input/output error"},
+		// The same path with another message is a new problem.
+		{Severity: fspb.Notification_WARNING, Path: "/root/secret", Message: "file vanished"},
+	}}
+
+	for _, tc := range []struct {
+		suppress bool
+		want     []string
+		notWant  []string
+	}{
+		{
+			suppress: false,
+			want:     []string{"Walking Errors for BEFORE file:", "WARNING(/root/secret): permission denied\nERROR(/var/data)"},
+		}, {
+			suppress: true,
+			want: []string{
+				"Walking Errors for AFTER file:\nERROR(/var/data): input/output error\nWARNING(/root/secret): file vanished\n",
+				"Suppressed 1 notifications",
+			},
+			notWant: []string{"Walking Errors for BEFORE file:", "permission denied"},
+		},
+	} {
+		r := &Reporter{config: &fspb.ReportConfig{SuppressRecurringNotifications: tc.suppress}}
+		report, err := r.Compare(before, after)
+		if err != nil {
+			t.Fatalf("Compare() error: %v", err)
+		}
+		out := captureStdout(t, func() { r.PrintDiffSummary(report) })
+		for _, want := range tc.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("suppress=%v: PrintDiffSummary() output doesn't contain %q:\n%s", tc.suppress, want, out)
+			}
+		}
+		for _, notWant := range tc.notWant {
+			if strings.Contains(out, notWant) {
+				t.Errorf("suppress=%v: PrintDiffSummary() output contains %q:\n%s", tc.suppress, notWant, out)
+			}
+		}
+	}
+}
+
+func TestReporterFromConfigBytes(t *testing.T) {
+	r, err := ReporterFromConfigBytes([]byte("version = 1\nexclude = [\"/tmp/\"]\n"))
+	if err != nil {
+		t.Fatalf("ReporterFromConfigBytes() error: %v", err)
+	}
+	want := &fspb.ReportConfig{Version: 1, Exclude: []string{"/tmp/"}}
+	if diff := cmp.Diff(want, r.config, cmp.Comparer(proto.Equal)); diff != "" {
+		t.Errorf("ReporterFromConfigBytes() config: diff (-want +got):\n%s", diff)
+	}
+
+	if _, err := ReporterFromConfigBytes([]byte("exclud = [\"/tmp/\"]")); err == nil {
+		t.Error("ReporterFromConfigBytes() with an unknown key succeeded; want error")
+	}
+}
+
+func TestReporterFromConfigEnv(t *testing.T) {
+	t.Setenv(ReportConfigEnvVar, "")
+	os.Unsetenv(ReportConfigEnvVar)
+	if _, err := ReporterFromConfigFile("", false); err == nil {
+		t.Errorf("ReporterFromConfigFile(\"\") without %s succeeded; want error", ReportConfigEnvVar)
+	}
+
+	t.Setenv(ReportConfigEnvVar, "version = 1\nexclude = [\"/tmp/\"]\n")
+	r, err := ReporterFromConfigFile("", true)
+	if err != nil {
+		t.Fatalf("ReporterFromConfigFile(\"\") error: %v", err)
+	}
+	want := &fspb.ReportConfig{Version: 1, Exclude: []string{"/tmp/"}}
+	if diff := cmp.Diff(want, r.config, cmp.Comparer(proto.Equal)); diff != "" {
+		t.Errorf("ReporterFromConfigFile(\"\") config: diff (-want +got):\n%s", diff)
+	}
+	if !r.Verbose {
+		t.Error("ReporterFromConfigFile(\"\", true) didn't set Verbose")
+	}
+	if r.configPath != "$"+ReportConfigEnvVar {
+		t.Errorf("ReporterFromConfigFile(\"\") config path = %q; want %q", r.configPath, "$"+ReportConfigEnvVar)
+	}
+}
+
+func TestCompareDirectorySizeChanges(t *testing.T) {
+	newWalk := func(id string, sizes map[string]int64) *fspb.Walk {
+		walk := &fspb.Walk{Id: id}
+		for _, dir := range []string{"/", "/data/", "/logs/", "/etc/"} {
+			walk.File = append(walk.File, &fspb.File{Path: dir, Info: &fspb.FileInfo{IsDir: true}})
+		}
+		for path, size := range sizes {
+			walk.File = append(walk.File, &fspb.File{Path: path, Info: &fspb.FileInfo{Size: size}})
+		}
+		computeDirectorySizes(walk.File)
+		return walk
+	}
+	before := newWalk("1", map[string]int64{
+		"/data/db":    1000,
+		"/logs/app":   500,
+		"/logs/old":   300,
+		"/etc/config": 10,
+		"/etc/passwd": 20,
+	})
+	// /data grows most, /logs shrinks, /etc stays the same size.
+	after := newWalk("2", map[string]int64{
+		"/data/db":     5000,
+		"/data/db.wal": 2000,
+		"/logs/app":    600,
+		"/etc/config":  20,
+		"/etc/passwd":  10,
+	})
+	r := &Reporter{config: &fspb.ReportConfig{}}
+	report, err := r.Compare(before, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+
+	var got []string
+	for _, d := range report.DirectorySizeChanges {
+		got = append(got, fmt.Sprintf("%s %+d", d.After.Path, aggregateSizeDelta(d)))
+	}
+	want := []string{"/data/ +6000", "/ +5800", "/logs/ -200"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Compare() directory size changes: diff (-want +got):\n%s", diff)
+	}
+	if report.Empty() {
+		t.Error("Compare() report is empty; want the changed files")
+	}
+
+	out := captureStdout(t, func() { r.PrintDiffSummary(report) })
+	if wantOut := "Directory Size Changes (3):\n/data/: +6000 bytes (1000 => 7000)\n"; !strings.Contains(out, wantOut) {
+		t.Errorf("PrintDiffSummary() output doesn't contain %q:\n%s", wantOut, out)
+	}
+
+	if err := report.Invert(); err != nil {
+		t.Fatalf("Invert() error: %v", err)
+	}
+	got = nil
+	for _, d := range report.DirectorySizeChanges {
+		got = append(got, fmt.Sprintf("%s %+d", d.After.Path, aggregateSizeDelta(d)))
+	}
+	want = []string{"/data/ -6000", "/ -5800", "/logs/ +200"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Invert() directory size changes: diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestPrintWalkLabels(t *testing.T) {
+	before := &fspb.Walk{Id: "1", Labels: map[string]string{"ticket": "CHG-1", "reason": "patching"}}
+	after := &fspb.Walk{Id: "2", Labels: map[string]string{"ticket": "CHG-2", "operator": "alice"}}
+	r := &Reporter{config: &fspb.ReportConfig{}}
+	report, err := r.Compare(before, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+
+	out := captureStdout(t, func() { r.PrintReportSummary(report) })
+	if want := "  - Label operator: alice\n  - Label ticket: CHG-2\n"; !strings.Contains(out, want) {
+		t.Errorf("PrintReportSummary() output doesn't contain %q:\n%s", want, out)
+	}
+	out = captureStdout(t, func() { r.PrintRuleSummary(report) })
+	want := "Walk label changes:\n" +
+		"  operator: added \"alice\"\n" +
+		"  reason: removed \"patching\"\n" +
+		"  ticket: \"CHG-1\" => \"CHG-2\"\n"
+	if !strings.Contains(out, want) {
+		t.Errorf("PrintRuleSummary() output doesn't contain %q:\n%s", want, out)
+	}
+}
+
+func TestCompareDataStreams(t *testing.T) {
+	fork := func(value string) []*fspb.DataStream {
+		return []*fspb.DataStream{{
+			Name:        "com.apple.ResourceFork",
+			Size:        10,
+			Fingerprint: &fspb.Fingerprint{Method: fspb.Fingerprint_SHA256, Value: value},
+		}}
+	}
+	before := &fspb.Walk{
+		Id:                   "1",
+		DataStreamsCollected: true,
+		File: []*fspb.File{
+			{Path: "/Applications/a", Info: &fspb.FileInfo{}},
+			{Path: "/Applications/b", Info: &fspb.FileInfo{}, DataStream: fork("abc")},
+			{Path: "/Applications/c", Info: &fspb.FileInfo{}, DataStream: fork("abc")},
+			// Too large to be hashed.
+			{Path: "/Applications/d", Info: &fspb.FileInfo{}, DataStream: []*fspb.DataStream{{Name: "com.apple.ResourceFork", Size: 10}}},
+		},
+	}
+	after := &fspb.Walk{
+		Id:                   "2",
+		DataStreamsCollected: true,
+		File: []*fspb.File{
+			{Path: "/Applications/a", Info: &fspb.FileInfo{}, DataStream: fork("def")},
+			{Path: "/Applications/b", Info: &fspb.FileInfo{}, DataStream: fork("def")},
+			{Path: "/Applications/c", Info: &fspb.FileInfo{}, DataStream: fork("abc")},
+			{Path: "/Applications/d", Info: &fspb.FileInfo{}, DataStream: []*fspb.DataStream{{Name: "com.apple.ResourceFork", Size: 20}}},
+		},
+	}
+	r := &Reporter{config: &fspb.ReportConfig{}}
+	report, err := r.Compare(before, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	got := map[string]string{}
+	for _, m := range report.Modified {
+		got[m.After.Path] = m.Diff
+	}
+	want := map[string]string{
+		"/Applications/a": "data-streams:  => com.apple.ResourceFork:def",
+		"/Applications/b": "data-streams: com.apple.ResourceFork:abc => com.apple.ResourceFork:def",
+		"/Applications/d": "data-streams: com.apple.ResourceFork:10 bytes => com.apple.ResourceFork:20 bytes",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Compare() data stream diffs: diff (-want +got):\n%s", diff)
+	}
+
+	// The streams of a first walk collecting them aren't reported as changes.
+	before.DataStreamsCollected = false
+	report, err = r.Compare(before, after)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	if len(report.Modified) != 0 {
+		t.Errorf("Compare() with a walk not collecting data streams modified %d files; want none", len(report.Modified))
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"encoding/json"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+// PolicyJSONSchema returns a JSON Schema document describing valid policies in
+// their JSON form (see protojson), e.g. to validate policies in external editors.
+// It is generated from the fields of the Policy proto, so it always lists all
+// fields the walker understands.
+func PolicyJSONSchema() ([]byte, error) {
+	schema := messageSchema((&fspb.Policy{}).ProtoReflect().Descriptor())
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "fswalker policy"
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// messageSchema returns the JSON Schema of the message md.
+func messageSchema(md protoreflect.MessageDescriptor) map[string]any {
+	props := map[string]any{}
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		props[fd.JSONName()] = fieldSchema(fd)
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+}
+
+// fieldSchema returns the JSON Schema of the values of the field fd.
+func fieldSchema(fd protoreflect.FieldDescriptor) map[string]any {
+	switch {
+	case fd.IsMap():
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": fieldSchema(fd.MapValue()),
+		}
+	case fd.IsList():
+		return map[string]any{
+			"type":  "array",
+			"items": singularSchema(fd),
+		}
+	}
+	return singularSchema(fd)
+}
+
+// singularSchema returns the JSON Schema of a single value of the field fd,
+// following the JSON mapping of proto3.
+func singularSchema(fd protoreflect.FieldDescriptor) map[string]any {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return map[string]any{"type": "boolean"}
+	case protoreflect.StringKind:
+		return map[string]any{"type": "string"}
+	case protoreflect.BytesKind:
+		return map[string]any{"type": "string", "contentEncoding": "base64"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return map[string]any{"type": "integer"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		// 64 bit integers may be encoded as strings to not lose precision.
+		return map[string]any{"type": []string{"integer", "string"}}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return map[string]any{"type": "number"}
+	case protoreflect.EnumKind:
+		var names []string
+		values := fd.Enum().Values()
+		for i := 0; i < values.Len(); i++ {
+			names = append(names, string(values.Get(i).Name()))
+		}
+		return map[string]any{"type": "string", "enum": names}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return messageSchema(fd.Message())
+	}
+	return map[string]any{}
+}
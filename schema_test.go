@@ -0,0 +1,78 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"encoding/json"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+func TestPolicyJSONSchema(t *testing.T) {
+	b, err := PolicyJSONSchema()
+	if err != nil {
+		t.Fatalf("PolicyJSONSchema() error: %v", err)
+	}
+	var schema struct {
+		Type       string                     `json:"type"`
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(b, &schema); err != nil {
+		t.Fatalf("PolicyJSONSchema() is no valid JSON: %v", err)
+	}
+	if schema.Type != "object" {
+		t.Errorf("PolicyJSONSchema() type = %q; want %q", schema.Type, "object")
+	}
+	for field, want := range map[string]string{
+		"include":                    `{"items":{"type":"string"},"type":"array"}`,
+		"exclude":                    `{"items":{"type":"string"},"type":"array"}`,
+		"maxHashFileSize":            `{"type":["integer","string"]}`,
+		"maxHashFileSizeByExtension": `{"additionalProperties":{"type":["integer","string"]},"type":"object"}`,
+		"maxOpenFiles":               `{"minimum":0,"type":"integer"}`,
+		"honorIgnoreFiles":           `{"type":"boolean"}`,
+	} {
+		got, ok := schema.Properties[field]
+		if !ok {
+			t.Errorf("PolicyJSONSchema() doesn't list %q", field)
+			continue
+		}
+		var compact map[string]any
+		if err := json.Unmarshal(got, &compact); err != nil {
+			t.Fatal(err)
+		}
+		gotJSON, _ := json.Marshal(compact)
+		if string(gotJSON) != want {
+			t.Errorf("PolicyJSONSchema() %q = %s; want %s", field, gotJSON, want)
+		}
+	}
+
+	// Every field of a policy in JSON form is described by the schema.
+	pol, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(&fspb.Policy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(pol, &fields); err != nil {
+		t.Fatal(err)
+	}
+	for field := range fields {
+		if _, ok := schema.Properties[field]; !ok {
+			t.Errorf("PolicyJSONSchema() doesn't list %q", field)
+		}
+	}
+}
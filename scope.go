@@ -0,0 +1,124 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/google/fswalker/internal/metrics"
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+// walkScope describes which files a Walk could have recorded according to the
+// includes and excludes of its policy.
+type walkScope struct {
+	include         []string
+	exclude         []string
+	excludeContents []string
+	relativeTo      string
+}
+
+// newWalkScope returns the scope of walk, or nil if it is unknown because the
+// Walk has no policy with includes.
+func newWalkScope(walk *fspb.Walk) *walkScope {
+	pol := walk.GetPolicy()
+	if len(pol.GetInclude()) == 0 {
+		return nil
+	}
+	s := &walkScope{
+		exclude:         append([]string{}, pol.Exclude...),
+		excludeContents: append([]string{}, pol.ExcludeContents...),
+		relativeTo:      pol.RelativeTo,
+	}
+	for _, p := range pol.Include {
+		s.include = append(s.include, filepath.Clean(p))
+	}
+	slices.Sort(s.include)
+	slices.Sort(s.exclude)
+	slices.Sort(s.excludeContents)
+	return s
+}
+
+// comparisonScopes returns the scopes of before and after if a comparison of
+// the Walks is limited to the files both of them could have recorded, or nils
+// otherwise. This is the case if their policies cover different files but
+// record paths relative to the same directory. Walks recording paths relative
+// to different directories, e.g. of two copies of a tree, are aligned by their
+// relative paths and their absolute scopes can't be related to each other.
+func comparisonScopes(before, after *fspb.Walk) (*walkScope, *walkScope) {
+	beforeScope, afterScope := newWalkScope(before), newWalkScope(after)
+	if beforeScope == nil || afterScope == nil || beforeScope.equal(afterScope) || beforeScope.relativeTo != afterScope.relativeTo {
+		return nil, nil
+	}
+	return beforeScope, afterScope
+}
+
+// equal returns true if s and o cover the same files.
+func (s *walkScope) equal(o *walkScope) bool {
+	return slices.Equal(s.include, o.include) && slices.Equal(s.exclude, o.exclude) &&
+		slices.Equal(s.excludeContents, o.excludeContents) && s.relativeTo == o.relativeTo
+}
+
+// absPath returns the absolute form of path as recorded by a Walk of scope s.
+func (s *walkScope) absPath(path string) string {
+	if s.relativeTo != "" && !filepath.IsAbs(path) {
+		return filepath.Join(s.relativeTo, path)
+	}
+	return path
+}
+
+// contains returns true if the file at the absolute path is in scope, i.e. it
+// is an included path or below one and neither it nor any of its parents up to
+// the include is excluded.
+func (s *walkScope) contains(path string, isDir bool) bool {
+	p := filepath.Clean(path)
+	root := ""
+	for _, inc := range s.include {
+		if p == inc || inc == string(filepath.Separator) || strings.HasPrefix(p, inc+string(filepath.Separator)) {
+			root = inc
+			break
+		}
+	}
+	if root == "" {
+		return false
+	}
+	if isExcluded(NormalizePath(p, isDir), s.exclude) {
+		return false
+	}
+	// The walk doesn't descend into excluded directories.
+	for d := p; d != root && d != filepath.Dir(d); {
+		d = filepath.Dir(d)
+		nd := NormalizePath(d, true)
+		if isExcluded(nd, s.exclude) || isExcluded(nd, s.excludeContents) {
+			return false
+		}
+	}
+	return true
+}
+
+// scopeWarning returns a warning if the policies of the Walks cover different
+// files, stating how many files were left out of the comparison for it.
+func scopeWarning(before, after *fspb.Walk, counter *metrics.Counter) string {
+	if beforeScope, _ := comparisonScopes(before, after); beforeScope == nil {
+		return ""
+	}
+	removed, _ := counter.Get("before-files-out-of-scope")
+	added, _ := counter.Get("after-files-out-of-scope")
+	return fmt.Sprintf("the walks were taken with policies including or excluding different paths, %d removed and %d added files outside of the scope of the other walk are not reported", removed, added)
+}
@@ -0,0 +1,146 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+func TestCompareDifferentScopes(t *testing.T) {
+	narrow := &fspb.Policy{Include: []string{"/data"}, Exclude: []string{"/data/cache/"}}
+	wide := &fspb.Policy{Include: []string{"/data/"}}
+	withCache := []*fspb.File{
+		{Path: "/data", Info: &fspb.FileInfo{IsDir: true}},
+		{Path: "/data/a", Info: &fspb.FileInfo{}},
+		{Path: "/data/cache", Info: &fspb.FileInfo{IsDir: true}},
+		{Path: "/data/cache/x", Info: &fspb.FileInfo{}},
+	}
+	withoutCache := []*fspb.File{
+		{Path: "/data", Info: &fspb.FileInfo{IsDir: true}},
+		{Path: "/data/a", Info: &fspb.FileInfo{}},
+	}
+	added := &fspb.File{Path: "/data/added", Info: &fspb.FileInfo{}}
+
+	testCases := []struct {
+		desc        string
+		before      *fspb.Walk
+		after       *fspb.Walk
+		wantAdded   []string
+		wantDeleted []string
+		wantWarning bool
+	}{
+		{
+			desc: "exclude removed",
+			before: &fspb.Walk{Id: "1", Policy: narrow,
+				File: append(withoutCache, &fspb.File{Path: "/data/removed", Info: &fspb.FileInfo{}})},
+			after: &fspb.Walk{Id: "2", Policy: wide,
+				File: append(withCache, added)},
+			wantAdded:   []string{"/data/added"},
+			wantDeleted: []string{"/data/removed"},
+			wantWarning: true,
+		}, {
+			desc:   "exclude added",
+			before: &fspb.Walk{Id: "1", Policy: wide, File: withCache},
+			after: &fspb.Walk{Id: "2", Policy: narrow,
+				File: append(withoutCache, added)},
+			wantAdded:   []string{"/data/added"},
+			wantWarning: true,
+		}, {
+			desc:        "same scope",
+			before:      &fspb.Walk{Id: "1", Policy: narrow, File: withoutCache},
+			after:       &fspb.Walk{Id: "2", Policy: &fspb.Policy{Include: []string{"/data"}, Exclude: []string{"/data/cache/"}}, File: withCache},
+			wantAdded:   []string{"/data/cache/", "/data/cache/x"},
+			wantWarning: false,
+		}, {
+			desc:      "unknown scope",
+			before:    &fspb.Walk{Id: "1", File: withoutCache},
+			after:     &fspb.Walk{Id: "2", Policy: wide, File: withCache},
+			wantAdded: []string{"/data/cache/", "/data/cache/x"},
+		},
+	}
+	for _, tc := range testCases {
+		r := &Reporter{config: &fspb.ReportConfig{}}
+		report, err := r.Compare(tc.before, tc.after)
+		if err != nil {
+			t.Fatalf("%s: Compare() error: %v", tc.desc, err)
+		}
+		if got := actionPaths(report.Added); !reflect.DeepEqual(got, tc.wantAdded) {
+			t.Errorf("%s: Compare() added = %q; want %q", tc.desc, got, tc.wantAdded)
+		}
+		if got := actionPaths(report.Deleted); !reflect.DeepEqual(got, tc.wantDeleted) {
+			t.Errorf("%s: Compare() deleted = %q; want %q", tc.desc, got, tc.wantDeleted)
+		}
+		warned := false
+		for _, w := range report.Warnings {
+			warned = warned || strings.Contains(w, "different paths")
+		}
+		if warned != tc.wantWarning {
+			t.Errorf("%s: Compare() warned about scopes = %v; want %v (warnings: %q)", tc.desc, warned, tc.wantWarning, report.Warnings)
+		}
+
+		if err := report.Invert(); err != nil {
+			t.Fatalf("%s: Invert() error: %v", tc.desc, err)
+		}
+		if got := actionPaths(report.Deleted); !reflect.DeepEqual(got, tc.wantAdded) {
+			t.Errorf("%s: inverted deleted = %q; want %q", tc.desc, got, tc.wantAdded)
+		}
+		if got := len(report.Warnings) > 0; got != tc.wantWarning {
+			t.Errorf("%s: inverted warnings = %q; want scope warning %v", tc.desc, report.Warnings, tc.wantWarning)
+		}
+	}
+}
+
+func TestWalkScopeContains(t *testing.T) {
+	s := newWalkScope(&fspb.Walk{Policy: &fspb.Policy{
+		Include:         []string{"/etc", "/usr/bin/"},
+		Exclude:         []string{"/etc/ssl/", "*.log"},
+		ExcludeContents: []string{"/etc/cache/"},
+		RelativeTo:      "/",
+	}})
+	for _, tc := range []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"/etc", true, true},
+		{"/etc/passwd", false, true},
+		{"/usr/bin/ls", false, true},
+		{"/usr/lib/libc.so", false, false},
+		{"/etcetera", false, false},
+		{"/etc/ssl", true, false},
+		{"/etc/ssl/certs/ca.pem", false, false},
+		{"/etc/app.log", false, false},
+		{"/etc/cache", true, true},
+		{"/etc/cache/entry", false, false},
+		{s.absPath("etc/hosts"), false, true},
+	} {
+		if got := s.contains(tc.path, tc.isDir); got != tc.want {
+			t.Errorf("contains(%q, %v) = %v; want %v", tc.path, tc.isDir, got, tc.want)
+		}
+	}
+}
+
+// actionPaths returns the paths of the files of entries.
+func actionPaths(entries []ActionData) []string {
+	var paths []string
+	for _, e := range entries {
+		paths = append(paths, actionPath(e))
+	}
+	return paths
+}
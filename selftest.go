@@ -0,0 +1,203 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+const (
+	// selfTestTree is the fixture tree walked by SelfTest within selfTestFS.
+	selfTestTree = "selftest/tree"
+	// selfTestGolden is the Walk expected from the fixture tree within selfTestFS.
+	// Its paths are relative to the directory the tree is extracted to, stat info
+	// and directory sizes are left out as they depend on the file system.
+	selfTestGolden = "selftest/golden.asciipb"
+)
+
+// selfTestFS holds the fixture tree and golden Walk of SelfTest.
+//
+//go:embed selftest
+var selfTestFS embed.FS
+
+// selfTestTime is the modification time of all files of the extracted fixture tree.
+var selfTestTime = time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// SelfTest walks a fixture tree embedded in the binary and compares the result
+// against an embedded golden Walk. This verifies that hashing, stat collection and
+// comparison work on the current platform. The tree is extracted to a temporary
+// directory which is removed afterwards.
+// It returns an error listing all discrepancies found, or nil if there are none.
+func SelfTest(ctx context.Context) error {
+	dir, err := os.MkdirTemp("", "fswalker-selftest")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	// The golden Walk expects the files in the mode of a default report config.
+	r := &Reporter{config: &fspb.ReportConfig{}}
+	mode, err := r.OutputFileMode()
+	if err != nil {
+		return err
+	}
+	root := filepath.Join(dir, filepath.Base(selfTestTree))
+	if err := extractSelfTestTree(root, mode); err != nil {
+		return fmt.Errorf("unable to extract fixture tree: %v", err)
+	}
+
+	var walk *fspb.Walk
+	w := &Walker{
+		pol: &fspb.Policy{
+			Version:         1,
+			Include:         []string{root},
+			MaxHashFileSize: 1 << 20,
+			RelativeTo:      dir,
+		},
+		WalkCallback: func(wlk *fspb.Walk) error {
+			walk = wlk
+			return nil
+		},
+	}
+	if err := w.Run(ctx); err != nil {
+		return fmt.Errorf("unable to walk fixture tree: %v", err)
+	}
+
+	b, err := selfTestFS.ReadFile(selfTestGolden)
+	if err != nil {
+		return err
+	}
+	golden := &fspb.Walk{}
+	if err := prototext.Unmarshal(b, golden); err != nil {
+		return fmt.Errorf("unable to parse golden walk: %v", err)
+	}
+	golden.Hostname = walk.Hostname
+
+	var problems []string
+	for _, n := range (&WalkFile{Walk: walk}).Notifications(fspb.Notification_WARNING) {
+		problems = append(problems, fmt.Sprintf("%s: %s: %s", n.Severity, n.Path, n.Message))
+	}
+	for _, f := range walk.File {
+		problems = append(problems, checkSelfTestStat(f)...)
+	}
+
+	report, err := r.Compare(golden, normalizeSelfTestWalk(walk))
+	if err != nil {
+		return fmt.Errorf("unable to compare against golden walk: %v", err)
+	}
+	for _, a := range report.Added {
+		problems = append(problems, fmt.Sprintf("%s: unexpected file", a.After.Path))
+	}
+	for _, d := range report.Deleted {
+		problems = append(problems, fmt.Sprintf("%s: missing file", d.Before.Path))
+	}
+	for _, m := range report.Modified {
+		problems = append(problems, fmt.Sprintf("%s: %s", m.Before.Path, strings.ReplaceAll(m.Diff, "\n", ", ")))
+	}
+	for _, e := range report.Errors {
+		problems = append(problems, fmt.Sprintf("%s: %v", e.Before.Path, e.Err))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("self-test found %d discrepancies:\n%s", len(problems), strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// extractSelfTestTree writes the fixture tree to root with files in mode and fixed
+// modification times, independent of the umask.
+func extractSelfTestTree(root string, mode os.FileMode) error {
+	var dirs []string
+	err := fs.WalkDir(selfTestFS, selfTestTree, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(path, selfTestTree)))
+		if d.IsDir() {
+			dirs = append(dirs, dst)
+			if err := os.MkdirAll(dst, 0755); err != nil {
+				return err
+			}
+			return os.Chmod(dst, 0755)
+		}
+		b, err := selfTestFS.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := writeFile(dst, b, mode); err != nil {
+			return err
+		}
+		return os.Chtimes(dst, selfTestTime, selfTestTime)
+	})
+	if err != nil {
+		return err
+	}
+	// Directories are touched last as creating their entries updates them.
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := os.Chtimes(dirs[i], selfTestTime, selfTestTime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkSelfTestStat returns the inconsistencies between the stat info and the
+// basic info of f, which point to a broken fsstat implementation.
+func checkSelfTestStat(f *fspb.File) []string {
+	if f.Stat == nil {
+		return []string{fmt.Sprintf("%s: no stat info", f.Path)}
+	}
+	var problems []string
+	if f.Stat.Inode == 0 {
+		problems = append(problems, fmt.Sprintf("%s: stat inode is 0", f.Path))
+	}
+	if f.Stat.Nlink == 0 {
+		problems = append(problems, fmt.Sprintf("%s: stat nlink is 0", f.Path))
+	}
+	if !f.Info.IsDir && f.Stat.Size != f.Info.Size {
+		problems = append(problems, fmt.Sprintf("%s: stat size %d != size %d", f.Path, f.Stat.Size, f.Info.Size))
+	}
+	if perm := fs.FileMode(f.Info.Mode).Perm(); fs.FileMode(f.Stat.Mode).Perm() != perm {
+		problems = append(problems, fmt.Sprintf("%s: stat permissions %v != permissions %v", f.Path, fs.FileMode(f.Stat.Mode).Perm(), perm))
+	}
+	if mt, st := f.Info.Modified.AsTime(), f.Stat.Mtime.AsTime(); !mt.Equal(st) {
+		problems = append(problems, fmt.Sprintf("%s: stat mtime %s != mtime %s", f.Path, st, mt))
+	}
+	return problems
+}
+
+// normalizeSelfTestWalk returns a copy of walk without the file system dependent
+// fields which the golden Walk leaves out.
+func normalizeSelfTestWalk(walk *fspb.Walk) *fspb.Walk {
+	walk = proto.Clone(walk).(*fspb.Walk)
+	for _, f := range walk.File {
+		f.Stat = nil
+		if f.Info.IsDir {
+			f.Info.Size = 0
+		}
+	}
+	return walk
+}
@@ -0,0 +1,75 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/google/fswalker/internal/fsstat"
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+// statFieldSet returns the set of FileStat fields listed in the statFields of a
+// policy, or all fields if statFields is empty.
+func statFieldSet(statFields []string) (fsstat.Fields, error) {
+	if len(statFields) == 0 {
+		return fsstat.AllFields, nil
+	}
+	fields := (&fspb.FileStat{}).ProtoReflect().Descriptor().Fields()
+	var set fsstat.Fields
+	for _, name := range statFields {
+		fd := fields.ByName(protoreflect.Name(name))
+		if fd == nil {
+			return 0, fmt.Errorf("unknown stat field %q", name)
+		}
+		set |= 1 << fd.Number()
+	}
+	return set, nil
+}
+
+// statDiffFields are the FileStat fields which are compared by Diff, by the name of
+// the field and of their diff.
+var statDiffFields = []struct {
+	name  string
+	field fsstat.Fields
+}{
+	{"uid", fsstat.FieldUid},
+	{"gid", fsstat.FieldGid},
+	{"inode", fsstat.FieldInode},
+	{"ctime", fsstat.FieldCtime},
+	{"btime", fsstat.FieldBtime},
+}
+
+// unrecordedStatDiffFields returns the names of the diffs of the FileStat fields
+// which the statFields of the policy of either Walk omitted. Omitted fields can't be
+// told apart from zero values, so comparing them would report bogus changes.
+func unrecordedStatDiffFields(before, after *fspb.Walk) []string {
+	recorded := fsstat.AllFields
+	for _, walk := range []*fspb.Walk{before, after} {
+		// Walks are only taken with valid statFields.
+		if set, err := statFieldSet(walk.GetPolicy().GetStatFields()); err == nil {
+			recorded &= set
+		}
+	}
+	var fields []string
+	for _, f := range statDiffFields {
+		if recorded&f.field == 0 {
+			fields = append(fields, f.name)
+		}
+	}
+	return fields
+}
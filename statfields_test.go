@@ -0,0 +1,100 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/fswalker/internal/fsstat"
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+func TestRunStatFields(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"file": "content"})
+	walk := runWalk(t, &fspb.Policy{Include: []string{root}, StatFields: []string{"uid", "gid", "mtime"}})
+
+	if len(walk.File) == 0 {
+		t.Fatal("Run() recorded no files")
+	}
+	for _, f := range walk.File {
+		st := f.Stat
+		if st == nil {
+			t.Fatalf("%s: no stat info", f.Path)
+		}
+		if st.Mtime == nil {
+			t.Errorf("%s: mtime not recorded", f.Path)
+		}
+		if st.Atime != nil || st.Ctime != nil || st.Btime != nil {
+			t.Errorf("%s: omitted times recorded: atime %v, ctime %v, btime %v", f.Path, st.Atime, st.Ctime, st.Btime)
+		}
+		if st.Dev != 0 || st.Inode != 0 || st.Nlink != 0 || st.Mode != 0 || st.Size != 0 || st.Blksize != 0 || st.Blocks != 0 {
+			t.Errorf("%s: omitted fields recorded: %v", f.Path, st)
+		}
+	}
+}
+
+func TestRunUnknownStatField(t *testing.T) {
+	w := &Walker{pol: &fspb.Policy{Include: []string{t.TempDir()}, StatFields: []string{"mtime", "color"}}}
+	if err := w.Run(context.Background()); err == nil {
+		t.Error("Run() with an unknown stat field succeeded")
+	}
+}
+
+func TestStatFieldSet(t *testing.T) {
+	got, err := statFieldSet([]string{"dev", "ctime", "attributes"})
+	if err != nil {
+		t.Fatalf("statFieldSet() error: %v", err)
+	}
+	if want := fsstat.FieldDev | fsstat.FieldCtime | fsstat.FieldAttributes; got != want {
+		t.Errorf("statFieldSet() = %b; want %b", got, want)
+	}
+}
+
+func TestRunHashChangedOnlyWithoutCtime(t *testing.T) {
+	w := &Walker{pol: &fspb.Policy{Include: []string{t.TempDir()}, StatFields: []string{"mtime"}, HashChangedOnly: true}}
+	if err := w.Run(context.Background()); err == nil {
+		t.Error("Run() with hashChangedOnly but without ctime succeeded")
+	}
+}
+
+func TestCompareLimitedStatFields(t *testing.T) {
+	full := &fspb.Walk{
+		Id:     "1",
+		Policy: &fspb.Policy{},
+		File: []*fspb.File{
+			{Path: "/etc/passwd", Info: &fspb.FileInfo{}, Stat: &fspb.FileStat{Uid: 1000, Gid: 1000, Inode: 42}},
+			{Path: "/etc/shadow", Info: &fspb.FileInfo{}, Stat: &fspb.FileStat{Uid: 1000, Gid: 1000, Inode: 43}},
+		},
+	}
+	limited := &fspb.Walk{
+		Id:     "2",
+		Policy: &fspb.Policy{StatFields: []string{"uid"}},
+		File: []*fspb.File{
+			{Path: "/etc/passwd", Info: &fspb.FileInfo{}, Stat: &fspb.FileStat{Uid: 1000}},
+			{Path: "/etc/shadow", Info: &fspb.FileInfo{}, Stat: &fspb.FileStat{Uid: 0}},
+		},
+	}
+	r := &Reporter{config: &fspb.ReportConfig{ReportInodeChanges: true}}
+	report, err := r.Compare(full, limited)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	// Only the uid recorded by both walks is compared.
+	if len(report.Modified) != 1 || report.Modified[0].Diff != "uid: 1000 => 0" {
+		t.Errorf("Compare() modified = %v; want only the uid of /etc/shadow", report.Modified)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// WalkDiffStat is a single labeled gauge describing a Report.
+type WalkDiffStat struct {
+	// Name is the metric name, e.g. "fswalker_files".
+	Name string
+	// Help describes the metric.
+	Help string
+	// Labels distinguish gauges of the same name, e.g. {"walk": "before"}.
+	Labels map[string]string
+	Value  int64
+}
+
+// WalkDiffStats returns the totals of the Report as a flat list of labeled
+// gauges, e.g. to feed dashboards trending the churn of a host: the number of
+// files of both Walks, the number of added, deleted and modified files and
+// bytes, the number of errors and all values of the Report's Counter.
+// Every gauge is labeled with the hostname of the after Walk. Gauges of the
+// same name are adjacent, as required by WritePrometheus.
+func (r *Report) WalkDiffStats() []WalkDiffStat {
+	host := r.WalkAfter.GetHostname()
+	if host == "" {
+		host = r.WalkBefore.GetHostname()
+	}
+	var stats []WalkDiffStat
+	add := func(name, help string, value int64, labels ...string) {
+		l := map[string]string{"hostname": host}
+		for i := 0; i+1 < len(labels); i += 2 {
+			l[labels[i]] = labels[i+1]
+		}
+		stats = append(stats, WalkDiffStat{Name: name, Help: help, Labels: l, Value: value})
+	}
+	count := func(name string) int64 {
+		if r.Counter == nil {
+			return 0
+		}
+		v, _ := r.Counter.Get(name)
+		return v
+	}
+
+	const filesHelp = "Number of files in the compared walk."
+	add("fswalker_files", filesHelp, int64(len(r.WalkBefore.GetFile())), "walk", "before")
+	add("fswalker_files", filesHelp, int64(len(r.WalkAfter.GetFile())), "walk", "after")
+	const changedHelp = "Number of changed files by kind of change."
+	add("fswalker_changed_files", changedHelp, int64(len(r.Added)), "change", "added")
+	add("fswalker_changed_files", changedHelp, int64(len(r.Deleted)), "change", "deleted")
+	add("fswalker_changed_files", changedHelp, int64(len(r.Modified)), "change", "modified")
+	const bytesHelp = "Size of the changed files by kind of change, the size difference for modified files."
+	add("fswalker_changed_bytes", bytesHelp, count("added-bytes"), "change", "added")
+	add("fswalker_changed_bytes", bytesHelp, count("deleted-bytes"), "change", "deleted")
+	add("fswalker_changed_bytes", bytesHelp, count("modified-bytes-delta"), "change", "modified")
+	add("fswalker_errors", "Number of files which couldn't be compared.", int64(len(r.Errors)))
+
+	if r.Counter != nil {
+		snapshot := r.Counter.Snapshot()
+		names := make([]string, 0, len(snapshot))
+		for n := range snapshot {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			add("fswalker_report_counter", "Value of a counter of the comparison.", snapshot[n], "counter", n)
+		}
+	}
+	return stats
+}
+
+// WritePrometheus writes stats to w in the Prometheus text exposition format,
+// e.g. for the textfile collector of the node exporter. Stats of the same name
+// need to be adjacent, they share a single HELP and TYPE line.
+func WritePrometheus(w io.Writer, stats []WalkDiffStat) error {
+	bw := bufio.NewWriter(w)
+	for i, s := range stats {
+		if i == 0 || stats[i-1].Name != s.Name {
+			fmt.Fprintf(bw, "# HELP %s %s\n", s.Name, escapePrometheus(s.Help, false))
+			fmt.Fprintf(bw, "# TYPE %s gauge\n", s.Name)
+		}
+		bw.WriteString(s.Name)
+		if len(s.Labels) > 0 {
+			keys := make([]string, 0, len(s.Labels))
+			for k := range s.Labels {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			pairs := make([]string, len(keys))
+			for j, k := range keys {
+				pairs[j] = fmt.Sprintf("%s=\"%s\"", k, escapePrometheus(s.Labels[k], true))
+			}
+			fmt.Fprintf(bw, "{%s}", strings.Join(pairs, ","))
+		}
+		fmt.Fprintf(bw, " %d\n", s.Value)
+	}
+	return bw.Flush()
+}
+
+// escapePrometheus escapes s for the text exposition format. Backslashes and
+// line feeds are escaped in help texts, double quotes as well in label values.
+func escapePrometheus(s string, labelValue bool) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	if labelValue {
+		s = strings.ReplaceAll(s, `"`, `\"`)
+	}
+	return s
+}
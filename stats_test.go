@@ -0,0 +1,94 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	before := &fspb.Walk{
+		Id:       "before",
+		Hostname: "testhost",
+		File: []*fspb.File{
+			{Path: "/a", Info: &fspb.FileInfo{Size: 10}},
+			{Path: "/b", Info: &fspb.FileInfo{Size: 20}},
+			{Path: "/c", Info: &fspb.FileInfo{Size: 30}},
+		},
+	}
+	after := &fspb.Walk{
+		Id:       "after",
+		Hostname: "testhost",
+		File: []*fspb.File{
+			{Path: "/a", Info: &fspb.FileInfo{Size: 15}},
+			{Path: "/c", Info: &fspb.FileInfo{Size: 30}},
+			{Path: "/d", Info: &fspb.FileInfo{Size: 40}},
+		},
+	}
+	report, err := (&Reporter{config: &fspb.ReportConfig{}}).Compare(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := WritePrometheus(&buf, report.WalkDiffStats()); err != nil {
+		t.Fatalf("WritePrometheus() error: %v", err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"# HELP fswalker_files Number of files in the compared walk.\n# TYPE fswalker_files gauge\n",
+		`fswalker_files{hostname="testhost",walk="before"} 3` + "\n",
+		`fswalker_files{hostname="testhost",walk="after"} 3` + "\n",
+		`fswalker_changed_files{change="added",hostname="testhost"} 1` + "\n",
+		`fswalker_changed_files{change="deleted",hostname="testhost"} 1` + "\n",
+		`fswalker_changed_files{change="modified",hostname="testhost"} 1` + "\n",
+		`fswalker_changed_bytes{change="added",hostname="testhost"} 40` + "\n",
+		`fswalker_changed_bytes{change="deleted",hostname="testhost"} 20` + "\n",
+		`fswalker_changed_bytes{change="modified",hostname="testhost"} 5` + "\n",
+		`fswalker_errors{hostname="testhost"} 0` + "\n",
+		`fswalker_report_counter{counter="before-files-removed",hostname="testhost"} 1` + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("WritePrometheus() output doesn't contain %q:\n%s", want, got)
+		}
+	}
+	if n := strings.Count(got, "# TYPE fswalker_changed_files gauge"); n != 1 {
+		t.Errorf("WritePrometheus() wrote %d TYPE lines for fswalker_changed_files; want 1", n)
+	}
+}
+
+func TestWritePrometheusEscaping(t *testing.T) {
+	stats := []WalkDiffStat{{
+		Name:   "fswalker_test",
+		Help:   "line\nbreak \\ \"quoted\"",
+		Labels: map[string]string{"path": "C:\\dir\n\"x\""},
+		Value:  -1,
+	}}
+	var buf bytes.Buffer
+	if err := WritePrometheus(&buf, stats); err != nil {
+		t.Fatalf("WritePrometheus() error: %v", err)
+	}
+	want := `# HELP fswalker_test line\nbreak \\ "quoted"
+# TYPE fswalker_test gauge
+fswalker_test{path="C:\\dir\n\"x\""} -1
+`
+	if got := buf.String(); got != want {
+		t.Errorf("WritePrometheus() = %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"google.golang.org/protobuf/proto"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+// treeIgnoreFields are the diff fields which differ between any two copies of a
+// tree and are therefore ignored by CompareTrees.
+var treeIgnoreFields = []string{"ctime", "btime", "inode"}
+
+// CompareTrees walks the live directory trees at pathA and pathB with the same
+// policy and compares them, e.g. to verify that a restore matches its source.
+// The includes and relativeTo of the policy are replaced by the respective root,
+// so files are recorded relative to it and the trees align. Change times, birth
+// times and inodes are ignored as they differ between copies anyway.
+// pathA is regarded as the before and pathB as the after state of the Report.
+func CompareTrees(ctx context.Context, policy *fspb.Policy, pathA, pathB string) (*Report, error) {
+	walkA, err := walkTree(ctx, policy, pathA)
+	if err != nil {
+		return nil, err
+	}
+	walkB, err := walkTree(ctx, policy, pathB)
+	if err != nil {
+		return nil, err
+	}
+	r := &Reporter{config: &fspb.ReportConfig{}}
+	return r.CompareWithOptions(walkA, walkB, CompareOptions{IgnoreFields: treeIgnoreFields})
+}
+
+// walkTree walks the tree at root with policy, recording files relative to root.
+func walkTree(ctx context.Context, policy *fspb.Policy, root string) (*fspb.Walk, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	pol := &fspb.Policy{}
+	if policy != nil {
+		pol = proto.Clone(policy).(*fspb.Policy)
+	}
+	pol.Include = []string{root}
+	pol.RelativeTo = root
+
+	var walk *fspb.Walk
+	w := &Walker{
+		pol: pol,
+		WalkCallback: func(wlk *fspb.Walk) error {
+			walk = wlk
+			return nil
+		},
+	}
+	if err := w.Run(ctx); err != nil {
+		return nil, fmt.Errorf("unable to walk %q: %v", root, err)
+	}
+	// The roots are recorded as "." but keep their own, differing names.
+	for _, f := range walk.File {
+		if f.Path == "." && f.Info != nil {
+			f.Info.Name = "."
+		}
+	}
+	return walk, nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+func TestCompareTrees(t *testing.T) {
+	files := map[string]string{
+		"etc/hosts":      "127.0.0.1 localhost",
+		"etc/passwd":     "root:x:0:0",
+		"var/lib/a.db":   "data",
+		"var/log/syslog": "",
+	}
+	// Like a restore preserving modification times, both copies get the same ones.
+	mtime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	source, restore := t.TempDir(), t.TempDir()
+	for _, root := range []string{source, restore} {
+		writeFiles(t, root, files)
+	}
+	if err := os.WriteFile(filepath.Join(restore, "var/lib/a.db"), []byte("DATA"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, root := range []string{source, restore} {
+		if err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			return os.Chtimes(path, mtime, mtime)
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := CompareTrees(context.Background(), &fspb.Policy{MaxHashFileSize: 1024}, source, restore)
+	if err != nil {
+		t.Fatalf("CompareTrees() error: %v", err)
+	}
+	if len(report.Added) != 0 || len(report.Deleted) != 0 || len(report.Errors) != 0 {
+		t.Errorf("CompareTrees() added %d, deleted %d and failed %d files; want none", len(report.Added), len(report.Deleted), len(report.Errors))
+	}
+	if len(report.Modified) != 1 {
+		t.Fatalf("CompareTrees() modified = %v; want only var/lib/a.db", report.Modified)
+	}
+	if m := report.Modified[0]; m.Before.Path != "var/lib/a.db" || len(m.Fields) != 1 || m.Fields[0].Field != "fingerprint" {
+		t.Errorf("CompareTrees() modified %q: %q; want only the fingerprint of var/lib/a.db", m.Before.Path, m.Diff)
+	}
+
+	// Files only in one of the trees are added or deleted, even though the
+	// walks include different roots.
+	writeFiles(t, restore, map[string]string{"etc/shadow": "root:*"})
+	if err := os.Remove(filepath.Join(restore, "var/log/syslog")); err != nil {
+		t.Fatal(err)
+	}
+	report, err = CompareTrees(context.Background(), &fspb.Policy{MaxHashFileSize: 1024}, source, restore)
+	if err != nil {
+		t.Fatalf("CompareTrees() error: %v", err)
+	}
+	if len(report.Added) != 1 || report.Added[0].After.Path != "etc/shadow" {
+		t.Errorf("CompareTrees() added = %v; want only etc/shadow", report.Added)
+	}
+	if len(report.Deleted) != 1 || report.Deleted[0].Before.Path != "var/log/syslog" {
+		t.Errorf("CompareTrees() deleted = %v; want only var/log/syslog", report.Deleted)
+	}
+	if len(report.Warnings) != 0 {
+		t.Errorf("CompareTrees() warnings = %q; want none", report.Warnings)
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+// UpgradeWalk migrates a Walk written by an older version of the walker in place,
+// so it can be compared against current Walks: fields missing in older Walks are
+// filled with defaults and the versions of the Walk and its files are bumped to
+// the current ones. Walks of a newer version than supported are rejected.
+func UpgradeWalk(walk *fspb.Walk) error {
+	if walk.Version > walkVersion {
+		return fmt.Errorf("walk version %d is newer than the supported version %d", walk.Version, walkVersion)
+	}
+	walk.Version = walkVersion
+	if walk.Policy == nil {
+		walk.Policy = &fspb.Policy{}
+	}
+	for _, f := range walk.File {
+		if f.Version > fileVersion {
+			return fmt.Errorf("file %q: version %d is newer than the supported version %d", f.Path, f.Version, fileVersion)
+		}
+		f.Version = fileVersion
+		if f.Info == nil {
+			f.Info = &fspb.FileInfo{Name: filepath.Base(f.Path)}
+		}
+		// IsDir is derived from the mode or a trailing separator if it wasn't recorded.
+		if fs.FileMode(f.Info.Mode).IsDir() || strings.HasSuffix(f.Path, string(filepath.Separator)) {
+			f.Info.IsDir = true
+		}
+	}
+	return nil
+}
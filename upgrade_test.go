@@ -0,0 +1,78 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fswalker
+
+import (
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
+)
+
+func TestUpgradeWalk(t *testing.T) {
+	// A walk of an older version: no versions, no policy, a file without info
+	// and a directory only recognizable by its mode.
+	old := &fspb.Walk{
+		Id: "old",
+		File: []*fspb.File{
+			{Path: "/etc/"},
+			{Path: "/etc/hosts", Info: &fspb.FileInfo{Name: "hosts", Size: 10}},
+			{Path: "/etc/conf.d", Info: &fspb.FileInfo{Name: "conf.d", Mode: uint32(fs.ModeDir | 0755)}},
+		},
+	}
+	path := filepath.Join(t.TempDir(), "old.pb")
+	if err := WriteWalk(path, old, 0644); err != nil {
+		t.Fatal(err)
+	}
+	current := &fspb.Walk{
+		Version: walkVersion,
+		Id:      "current",
+		File: []*fspb.File{
+			{Version: fileVersion, Path: "/etc/", Info: &fspb.FileInfo{Name: "etc", IsDir: true}},
+			{Version: fileVersion, Path: "/etc/hosts", Info: &fspb.FileInfo{Name: "hosts", Size: 20}},
+			{Version: fileVersion, Path: "/etc/conf.d", Info: &fspb.FileInfo{Name: "conf.d", Mode: uint32(fs.ModeDir | 0755), IsDir: true}},
+		},
+	}
+
+	r := &Reporter{config: &fspb.ReportConfig{}}
+	wf, err := r.ReadWalk(path)
+	if err != nil {
+		t.Fatalf("ReadWalk() error: %v", err)
+	}
+	if _, err := r.Compare(wf.Walk, current); err == nil {
+		t.Fatalf("Compare() of the old walk without upgrading: want error")
+	}
+
+	r.UpgradeWalks = true
+	wf, err = r.ReadWalk(path)
+	if err != nil {
+		t.Fatalf("ReadWalk() error: %v", err)
+	}
+	report, err := r.Compare(wf.Walk, current)
+	if err != nil {
+		t.Fatalf("Compare() of the upgraded walk error: %v", err)
+	}
+	if len(report.Added) != 0 || len(report.Deleted) != 0 || len(report.Errors) != 0 {
+		t.Errorf("Compare() added %d, deleted %d and failed %d files; want none", len(report.Added), len(report.Deleted), len(report.Errors))
+	}
+	if len(report.Modified) != 1 || report.Modified[0].Diff != "size: 10 => 20" {
+		t.Errorf("Compare() modified = %v; want only the size of /etc/hosts", report.Modified)
+	}
+
+	if err := UpgradeWalk(&fspb.Walk{Version: walkVersion + 1}); err == nil {
+		t.Errorf("UpgradeWalk() of a newer walk: want error")
+	}
+}
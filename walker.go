@@ -22,19 +22,24 @@ import (
 	"hash"
 	"io/fs"
 	"log"
+	"math"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
+	"google.golang.org/protobuf/proto"
 	tspb "google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/google/fswalker/internal/fsstat"
 	"github.com/google/fswalker/internal/metrics"
+	"github.com/google/fswalker/internal/mountinfo"
 	fspb "github.com/google/fswalker/proto/fswalker"
 )
 
@@ -44,16 +49,70 @@ const (
 	walkVersion = 1
 
 	// Unique names for each counter - used by the counter output processor.
-	countFiles       = "file-count"
-	countDirectories = "dir-count"
-	countFileSizeSum = "file-size-sum"
-	countStatErr     = "file-stat-errors"
-	countHashes      = "file-hash-count"
+	countFiles                 = "file-count"
+	countDirectories           = "dir-count"
+	countFileSizeSum           = "file-size-sum"
+	countStatErr               = "file-stat-errors"
+	countHashes                = "file-hash-count"
+	countHashSkipped           = "hashes-skipped-unchanged"
+	countHashSkippedOutOfScope = "hashes-skipped-out-of-scope"
+	countFutureMtime           = "file-future-mtime"
+
+	// fallbackMaxOpenFiles is the default of maxOpenFiles if the file descriptor limit is unknown.
+	fallbackMaxOpenFiles = 128
+
+	// futureMtimeSlack is how far in the future a modification time may be before
+	// it is flagged, tolerating small clock differences e.g. with network file systems.
+	futureMtimeSlack = time.Minute
 )
 
+// SizeBucketPrefix is the prefix of the metrics of the file size histogram of
+// DetailedMetrics. It is followed by the name of the bucket, see SizeBuckets.
+const SizeBucketPrefix = "size-bucket:"
+
+// sizeBuckets are the buckets of the file size histogram of DetailedMetrics,
+// by their exclusive upper bound. Larger files end up in the last bucket.
+var sizeBuckets = []struct {
+	max  int64
+	name string
+}{
+	{1 << 10, "0-1K"},
+	{1 << 20, "1K-1M"},
+	{100 << 20, "1M-100M"},
+	{math.MaxInt64, ">100M"},
+}
+
+// SizeBuckets returns the names of the buckets of the file size histogram of
+// DetailedMetrics, ordered by increasing file size.
+func SizeBuckets() []string {
+	names := make([]string, 0, len(sizeBuckets))
+	for _, b := range sizeBuckets {
+		names = append(names, b.name)
+	}
+	return names
+}
+
+// defaultQueueDepth is the number of discovered files queued for the workers
+// if neither Walker.QueueDepth nor the queueDepth of the policy is set.
+const defaultQueueDepth = 64
+
+// defaultHashBufferSize is the size of the buffer io.Copy hashes files with if
+// the policy doesn't set hashBufferSize.
+const defaultHashBufferSize = 32 << 10
+
+// maxHashBufferSize is the largest hashBufferSize of a policy, as every worker
+// holds a buffer of that size.
+const maxHashBufferSize = 64 << 20
+
+// errWalkStopped is used to abort filepath.WalkDir once the walk's context is done.
+var errWalkStopped = errors.New("walk stopped")
+
 var (
 	// Number of workers
 	parallelism = runtime.NumCPU()
+
+	// readMountInfo returns the mount table, it can be replaced in tests.
+	readMountInfo = mountinfo.Read
 )
 
 // Walker is able to walk a file structure starting with a list of given includes
@@ -68,6 +127,31 @@ type Walker struct {
 	// walk collects all processed files during a run.
 	walk   *fspb.Walk
 	walkMu sync.Mutex
+	// progress is the progress of the current run, protected by walkMu.
+	progress Progress
+	// missingStat counts the files for which no stat info is available per
+	// device of their filesystem, protected by walkMu.
+	missingStat map[uint64]*missingStatFiles
+	// missingStatMounts is the mount table to find the filesystem of files
+	// without stat info in, read once the first such file is found. Protected
+	// by walkMu.
+	missingStatMounts       []*mountinfo.Mount
+	missingStatMountsLoaded bool
+	// futureMtimes are the warnings about files modified in the future, protected by walkMu.
+	futureMtimes []*fspb.Notification
+
+	// mounts maps device numbers to their mount if the policy requires mount information.
+	mounts map[uint64]*mountinfo.Mount
+	// bindSources maps bind mount points to their source path if requested by the policy.
+	bindSources map[string]string
+
+	// mtimeRef is the parsed mtimeReference of the policy, the zero time if unset.
+	mtimeRef time.Time
+
+	// openFiles is a semaphore limiting the files opened concurrently to the policy's maxOpenFiles.
+	openFiles chan struct{}
+	// hashBufs holds the buffers of the policy's hashBufferSize files are hashed with, if set.
+	hashBufs *sync.Pool
 
 	// Function to call once the Walk is complete i.e. to inspect or write the Walk.
 	WalkCallback WalkCallback
@@ -77,6 +161,146 @@ type Walker struct {
 
 	// Counter records stats over all processed files, if non-nil.
 	Counter *metrics.Counter
+
+	// DetailedMetrics, when true, additionally records a histogram of file sizes
+	// in Counter, e.g. "size-bucket:1K-1M" for files of at least 1 KiB but less than 1 MiB.
+	DetailedMetrics bool
+
+	// SkipDirFunc, if non-nil, is called for every directory which is not excluded by
+	// the policy. If it returns true, the directory and all its contents are skipped.
+	// The path has a trailing path separator (see NormalizePath). If the policy sets
+	// isolateIncludes, it is called concurrently for different includes, so it then
+	// needs to be safe for concurrent use.
+	SkipDirFunc func(path string, d fs.DirEntry) bool
+
+	// SkipPaths are skipped like the excludes of the policy and have the same format,
+	// but aren't recorded in the policy of the Walk. This keeps e.g. the walker's own
+	// output files out of the walk without changing its policy fingerprint.
+	SkipPaths []string
+
+	// FS, if non-nil, is walked instead of the local file system. The includes and
+	// excludes of the policy are then paths within FS (see fs.ValidPath) and files
+	// are recorded with those paths. Features relying on the local file system, like
+	// mount information, birth times or attributes, are not available.
+	// Device boundaries are only honored if FS provides stat info like os.DirFS does.
+	FS fs.FS
+
+	// Baseline is a previous Walk of the same policy. If the policy sets hashChangedOnly,
+	// unchanged files get the fingerprints of their baseline entry instead of being hashed.
+	// Files outside of the scope of the Baseline, which a comparison with it doesn't
+	// report, aren't hashed at all.
+	Baseline *fspb.Walk
+	// baselineFiles indexes the files of Baseline by path.
+	baselineFiles map[string]*fspb.File
+	// baselineScope is the scope of Baseline if it limits the comparison with the walk.
+	baselineScope *walkScope
+
+	// ProgressCallback, if non-nil, is called after each processed file.
+	// Calls are serialized and block the workers, so it should return quickly.
+	ProgressCallback func(Progress)
+
+	// PrePass, when true, makes Run count all files in a stat-only walk before
+	// the actual one so the Progress passed to ProgressCallback has totals.
+	// Note that this traverses all directories twice.
+	PrePass bool
+
+	// ErrorCallback, if non-nil, is called for every error processing a file, in
+	// addition to recording it as a notification. Calls are serialized.
+	// If it returns an error, no more files are discovered and Run returns that
+	// error once all files found so far are processed, without calling WalkCallback.
+	ErrorCallback func(path string, err error) error
+
+	// OnFile, if non-nil, is called with every File as soon as it is processed,
+	// e.g. to stream the walk to a live view. Unlike the other callbacks, calls
+	// are not serialized but made concurrently from all workers, so it needs to
+	// be safe for concurrent use. The File is part of the Walk and must not be
+	// modified.
+	OnFile func(*fspb.File)
+
+	// Labels are recorded in the Walk, e.g. the change ticket it is taken for or
+	// the operator who started it, for audit trails.
+	Labels map[string]string
+
+	// Explain, when true, makes Walker record an INFO notification for each of
+	// ExplainPaths naming the rule which made the walk record or skip it, e.g.
+	// the include it was recorded as part of or the exclude entry matching it.
+	// The messages start with "explain: ". If ExplainPaths is empty, every path
+	// the walk comes across is explained.
+	Explain      bool
+	ExplainPaths []string
+	// explained maps the cleaned ExplainPaths to whether they were explained in
+	// the current run, protected by walkMu.
+	explained map[string]bool
+
+	// droppedStatFields are the fields of FileStat which the policy doesn't record.
+	droppedStatFields fsstat.Fields
+
+	// QueueDepth overrides the queueDepth of the policy if positive.
+	QueueDepth int
+}
+
+// Progress describes how far a Run has come.
+type Progress struct {
+	// Files is the number of files processed so far and Bytes their total size.
+	Files int64
+	Bytes int64
+	// TotalFiles and TotalBytes are the totals counted by the pre-pass, or zero
+	// if Walker.PrePass isn't set.
+	TotalFiles int64
+	TotalBytes int64
+}
+
+// AddIncludes adds paths to the includes of the policy, in addition to the ones it already has.
+func (w *Walker) AddIncludes(paths ...string) {
+	w.pol.Include = append(w.pol.Include, paths...)
+}
+
+// AddExcludes adds paths to the excludes of the policy, in addition to the ones it already has.
+func (w *Walker) AddExcludes(paths ...string) {
+	w.pol.Exclude = append(w.pol.Exclude, paths...)
+}
+
+// EffectivePolicy returns a copy of the policy the Walker runs with, including
+// includes and excludes added later and with defaults applied to unset fields.
+func (w *Walker) EffectivePolicy() *fspb.Policy {
+	pol := proto.Clone(w.pol).(*fspb.Policy)
+	if pol.OutputFileMode == "" {
+		pol.OutputFileMode = fmt.Sprintf("%04o", DefaultWalkFileMode.Perm())
+	}
+	pol.MaxOpenFiles = uint32(w.maxOpenFiles())
+	pol.QueueDepth = uint32(w.queueDepth())
+	if pol.HashBufferSize == 0 {
+		pol.HashBufferSize = defaultHashBufferSize
+	}
+	return pol
+}
+
+// maxOpenFiles returns the maximum number of files to open concurrently, e.g. for hashing.
+// It is capped to the maximum size of a channel on 32 bit platforms.
+func (w *Walker) maxOpenFiles() int {
+	n := w.pol.MaxOpenFiles
+	if n == 0 {
+		n = defaultMaxOpenFiles()
+	}
+	if n > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return int(n)
+}
+
+// acquireFile blocks until another file may be opened within maxOpenFiles.
+// Every call needs to be followed by a call to releaseFile once the file is closed.
+func (w *Walker) acquireFile() {
+	if w.openFiles != nil {
+		w.openFiles <- struct{}{}
+	}
+}
+
+// releaseFile releases a file acquired by acquireFile.
+func (w *Walker) releaseFile() {
+	if w.openFiles != nil {
+		<-w.openFiles
+	}
 }
 
 // WalkCallback is called by Walker at the end of the Run.
@@ -87,17 +311,51 @@ type WalkCallback func(*fspb.Walk) error
 type fileInfo struct {
 	path string
 	info fs.FileInfo
+	// root is the include path the file was discovered under and dev its device.
+	root string
+	dev  uint64
+}
+
+// missingStatFiles counts the files of a filesystem without stat info.
+type missingStatFiles struct {
+	// path is the mount point of the filesystem or, if unknown, the first
+	// include in sort order the files were discovered under.
+	path  string
+	files int
 }
 
 type workerErr struct {
-	path string
-	err  string
+	severity fspb.Notification_Severity
+	path     string
+	err      error
 }
 
+// PolicyEnvVar is the environment variable WalkerFromPolicyFile reads the policy
+// from if no path is given, e.g. in containers where mounting files is awkward.
+const PolicyEnvVar = "FSWALKER_POLICY"
+
 // WalkerFromPolicyFile creates a new Walker based on a policy path.
+// If path is empty, the policy is read from the PolicyEnvVar environment variable.
 func WalkerFromPolicyFile(path string) (*Walker, error) {
+	if path == "" {
+		pol, ok := os.LookupEnv(PolicyEnvVar)
+		if !ok {
+			return nil, fmt.Errorf("no policy file given and %s is not set", PolicyEnvVar)
+		}
+		return WalkerFromPolicyBytes([]byte(pol))
+	}
+	b, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return WalkerFromPolicyBytes(b)
+}
+
+// WalkerFromPolicyBytes creates a new Walker based on a policy in the format of
+// policy files, e.g. embedded in the binary.
+func WalkerFromPolicyBytes(b []byte) (*Walker, error) {
 	pol := &fspb.Policy{}
-	md, err := toml.DecodeFile(path, pol)
+	md, err := toml.Decode(string(b), pol)
 	if err != nil {
 		return nil, err
 	}
@@ -123,34 +381,115 @@ func WalkerFromPolicyFile(path string) (*Walker, error) {
 // Run is the main function of Walker. It discovers all files under included paths
 // (minus excluded ones) and processes them.
 // This does NOT follow symlinks - fortunately we don't need it either.
+// If ctx is done or the policy's maxWalkDuration is exceeded, no more files are
+// discovered but all files found so far are processed and the partial Walk is
+// still passed to the WalkCallback.
 func (w *Walker) Run(ctx context.Context) error {
+	var maxDuration time.Duration
+	if w.pol.MaxWalkDuration != "" {
+		var err error
+		if maxDuration, err = time.ParseDuration(w.pol.MaxWalkDuration); err != nil {
+			return fmt.Errorf("invalid maxWalkDuration %q: %v", w.pol.MaxWalkDuration, err)
+		}
+	}
+	if _, err := ParseFileMode(w.pol.OutputFileMode, DefaultWalkFileMode); err != nil {
+		return fmt.Errorf("invalid outputFileMode: %v", err)
+	}
+	if w.pol.HashBufferSize > maxHashBufferSize {
+		return fmt.Errorf("invalid hashBufferSize %d: must not exceed %d", w.pol.HashBufferSize, maxHashBufferSize)
+	}
+	if w.pol.MaxBlockDeviceHashSize > math.MaxInt64 {
+		return fmt.Errorf("invalid maxBlockDeviceHashSize %d: must not exceed %d", w.pol.MaxBlockDeviceHashSize, int64(math.MaxInt64))
+	}
+	statFields, err := statFieldSet(w.pol.StatFields)
+	if err != nil {
+		return fmt.Errorf("invalid statFields: %v", err)
+	}
+	if w.pol.HashChangedOnly && statFields&fsstat.FieldCtime == 0 {
+		return errors.New("hashChangedOnly needs ctime to be recorded in statFields")
+	}
+	w.droppedStatFields = ^statFields
+	w.mtimeRef = time.Time{}
+	if w.pol.MtimeReference != "" {
+		var err error
+		if w.mtimeRef, err = time.Parse(time.RFC3339Nano, w.pol.MtimeReference); err != nil {
+			return fmt.Errorf("invalid mtimeReference %q: %v", w.pol.MtimeReference, err)
+		}
+	}
+	walkCtx := ctx
+	if maxDuration > 0 {
+		var cancel context.CancelFunc
+		walkCtx, cancel = context.WithTimeout(ctx, maxDuration)
+		defer cancel()
+	}
+	// stopCtx is additionally canceled if ErrorCallback aborts the walk.
+	stopCtx, stop := context.WithCancel(walkCtx)
+	defer stop()
+
 	walkID := uuid.New().String()
 	hn, err := os.Hostname()
 	if err != nil {
 		return err
 	}
+	polFp, err := policyFingerprint(w.pol)
+	if err != nil {
+		return fmt.Errorf("unable to fingerprint policy: %v", err)
+	}
 	w.walk = &fspb.Walk{
-		Version:   walkVersion,
-		Id:        walkID,
-		Policy:    w.pol,
-		Hostname:  hn,
-		StartWalk: tspb.Now(),
+		Version:           walkVersion,
+		Id:                walkID,
+		Policy:            w.pol,
+		PolicyFingerprint: polFp,
+		Hostname:          hn,
+		StartWalk:         tspb.Now(),
+		// Data streams are read from the host, which FS walks don't have access to.
+		DataStreamsCollected: w.pol.CollectDataStreams && w.FS == nil,
+	}
+	if len(w.Labels) > 0 {
+		w.walk.Labels = make(map[string]string, len(w.Labels))
+		for k, v := range w.Labels {
+			w.walk.Labels[k] = v
+		}
+	}
+
+	if w.FS == nil && ((!w.pol.WalkCrossDevice && w.pol.WalkCrossVirtualDevice) || w.pol.RecordBindMounts || len(w.pol.ExcludeHashingFilesystems) > 0) {
+		w.loadMounts()
+	}
+
+	w.baselineFiles = nil
+	w.baselineScope = nil
+	if w.pol.HashChangedOnly && w.Baseline != nil {
+		w.baselineFiles = make(map[string]*fspb.File, len(w.Baseline.File))
+		for _, f := range w.Baseline.File {
+			w.baselineFiles[f.Path] = f
+		}
+		w.baselineScope, _ = comparisonScopes(w.Baseline, w.walk)
+	}
+
+	w.resetExplanations()
+	includes := w.dedupeIncludes()
+	w.progress = Progress{}
+	w.missingStat = nil
+	w.missingStatMounts, w.missingStatMountsLoaded = nil, false
+	w.futureMtimes = nil
+	if w.PrePass {
+		w.progress.TotalFiles, w.progress.TotalBytes = w.prePass(stopCtx, includes)
 	}
 
-	fileCh := make(chan *fileInfo, 64)
 	errCh := make(chan *workerErr)
 	done := make(chan struct{})
 	var workerErrs []*workerErr
+	var abortErr error
 
-	var wg sync.WaitGroup
-	wg.Add(parallelism)
-
-	// start workers to hash and build file info concurrently
-	for i := 0; i < parallelism; i++ {
-		go func() {
-			defer wg.Done()
-			w.worker(fileCh, errCh)
-		}()
+	w.openFiles = make(chan struct{}, w.maxOpenFiles())
+	w.hashBufs = nil
+	if size := w.pol.HashBufferSize; size > 0 {
+		w.hashBufs = &sync.Pool{
+			New: func() any {
+				buf := make([]byte, size)
+				return &buf
+			},
+		}
 	}
 
 	// start goroutine to store worker errors
@@ -158,22 +497,55 @@ func (w *Walker) Run(ctx context.Context) error {
 		for {
 			for werr := range errCh {
 				workerErrs = append(workerErrs, werr)
-				log.Printf("ERROR: %s: %s", werr.path, werr.err)
+				log.Printf("%s: %s: %s", werr.severity, werr.path, werr.err)
+				if w.ErrorCallback != nil && abortErr == nil {
+					if abortErr = w.ErrorCallback(werr.path, werr.err); abortErr != nil {
+						stop()
+					}
+				}
 			}
 			done <- struct{}{}
 		}
 	}()
 
-	w.preformWalk(fileCh)
-
-	close(fileCh)
-	wg.Wait()
+	w.walkWithWorkers(stopCtx, includes, errCh)
+	w.explainUnreached(includes)
+	if ctx.Err() != nil || walkCtx.Err() != nil {
+		w.walk.Incomplete = true
+	}
+	switch {
+	case ctx.Err() != nil:
+		w.addNotificationToWalk(fspb.Notification_WARNING, "", fmt.Sprintf("walk was interrupted (%v), results are incomplete", ctx.Err()))
+	case walkCtx.Err() != nil:
+		w.addNotificationToWalk(fspb.Notification_WARNING, "", fmt.Sprintf("walk exceeded the maximum walk duration of %s, results are incomplete", maxDuration))
+	}
 
 	close(errCh)
 	<-done
+	if abortErr != nil {
+		return abortErr
+	}
 
 	for _, werr := range workerErrs {
-		w.addNotificationToWalk(fspb.Notification_ERROR, werr.path, werr.err)
+		w.addNotificationToWalk(werr.severity, werr.path, werr.err.Error())
+	}
+	w.addMissingStatWarnings()
+	for _, n := range w.futureMtimes {
+		w.addNotificationToWalk(n.Severity, n.Path, n.Message)
+	}
+	// Overlapping includes must not record a file twice, the reporter keeps only one.
+	for _, p := range duplicatePaths(w.walk.File, nil) {
+		w.addNotificationToWalk(fspb.Notification_WARNING, p, "path was recorded more than once")
+	}
+
+	if w.pol.ComputeTreeDigests {
+		computeTreeDigests(w.walk.File)
+	}
+	if w.pol.ComputeDirectorySizes {
+		computeDirectorySizes(w.walk.File)
+	}
+	if w.pol.SortOutput {
+		sortWalk(w.walk)
 	}
 
 	// Finishing work by writing out the report.
@@ -184,43 +556,118 @@ func (w *Walker) Run(ctx context.Context) error {
 	return w.WalkCallback(w.walk)
 }
 
+// walkWithWorkers walks includes, concurrently if the policy sets isolateIncludes,
+// and processes the discovered files with a single pool of parallelism workers.
+// It returns once all files are processed.
+func (w *Walker) walkWithWorkers(ctx context.Context, includes []string, errCh chan<- *workerErr) {
+	fileCh := make(chan *fileInfo, w.queueDepth())
+	var wg sync.WaitGroup
+	wg.Add(parallelism)
+
+	// start workers to hash and build file info concurrently
+	for i := 0; i < parallelism; i++ {
+		go func() {
+			defer wg.Done()
+			w.worker(fileCh, errCh)
+		}()
+	}
+
+	if w.pol.IsolateIncludes {
+		// Every include is discovered concurrently, so a slow one doesn't hold up the others.
+		var discoveryWg sync.WaitGroup
+		for _, include := range includes {
+			discoveryWg.Add(1)
+			go func(include string) {
+				defer discoveryWg.Done()
+				w.preformWalk(ctx, []string{include}, fileCh)
+			}(include)
+		}
+		discoveryWg.Wait()
+	} else {
+		w.preformWalk(ctx, includes, fileCh)
+	}
+	close(fileCh)
+	wg.Wait()
+}
+
+// queueDepth returns the number of discovered files to queue for the workers.
+// Like maxOpenFiles, it is capped to the maximum size of a channel on 32 bit platforms.
+func (w *Walker) queueDepth() int {
+	if w.QueueDepth > 0 {
+		return w.QueueDepth
+	}
+	if n := w.pol.QueueDepth; n > math.MaxInt32 {
+		return math.MaxInt32
+	} else if n > 0 {
+		return int(n)
+	}
+	return defaultQueueDepth
+}
+
+// sortWalk sorts the files of walk by path and its notifications by severity, path and message.
+func sortWalk(walk *fspb.Walk) {
+	slices.SortFunc(walk.File, func(a, b *fspb.File) bool {
+		return a.Path < b.Path
+	})
+	slices.SortFunc(walk.Notification, func(a, b *fspb.Notification) bool {
+		if a.Severity != b.Severity {
+			return a.Severity < b.Severity
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Message < b.Message
+	})
+}
+
 // worker is a worker routine that reads paths from chPaths and walks all the files and
 // subdirectories until the channel is exhausted. All discovered files are converted to
 // File and processed with w.process().
-func (w *Walker) preformWalk(fileCh chan<- *fileInfo) error {
-	for _, path := range w.pol.Include {
-		path = filepath.Clean(path)
-		baseInfo, err := os.Stat(path)
+// Include roots which can't be walked or which don't contribute a single file
+// are recorded as warnings since that usually indicates a misconfigured policy.
+// The walk stops discovering new files once ctx is done.
+func (w *Walker) preformWalk(ctx context.Context, includes []string, fileCh chan<- *fileInfo) error {
+	for _, path := range includes {
+		if ctx.Err() != nil {
+			return nil
+		}
+		baseInfo, err := w.stat(path)
 		if err != nil {
-			return fmt.Errorf("unable to get file info for base path %q: %v", path, err)
+			w.addNotificationToWalk(fspb.Notification_WARNING, path, fmt.Sprintf("unable to get file info for base path %q: %v", path, err))
+			w.explainf(path, true, "unable to get file info of include %q: %v", path, err)
+			continue
 		}
 		baseDev, err := fsstat.DevNumber(baseInfo)
-		if err != nil {
-			return fmt.Errorf("unable to get file stat on base path %q: %v", path, err)
+		if err != nil && w.FS == nil {
+			w.addNotificationToWalk(fspb.Notification_WARNING, path, fmt.Sprintf("unable to get file stat on base path %q: %v", path, err))
+			w.explainf(path, true, "unable to get file stat of include %q: %v", path, err)
+			continue
 		}
 
-		if err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		var walked int
+		// ignores are the ignore files of the directories being walked, innermost last.
+		var ignores []ignoreScope
+
+		if err := w.walkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if ctx.Err() != nil {
+				return errWalkStopped
+			}
 			p = NormalizePath(p, d.IsDir())
 			if err != nil {
 				msg := fmt.Sprintf("failed to walk %q: %s", p, err)
 				log.Print(msg)
 				w.addNotificationToWalk(fspb.Notification_WARNING, p, msg)
+				// The directory itself was passed before, only its contents are missing.
+				w.explainContentsf(p, "failed to walk %q: %s", p, err)
 				return nil
 			}
 
-			// Checking various exclusions based on flags in the walker policy.
-			if isExcluded(p, w.pol.Exclude) {
-				if w.Verbose {
-					w.addNotificationToWalk(fspb.Notification_INFO, p, fmt.Sprintf("skipping %q: excluded", p))
-				}
-				if d.IsDir() {
-					return filepath.SkipDir
-				}
-				return nil
+			for len(ignores) > 0 && !ignores[len(ignores)-1].contains(p) {
+				ignores = ignores[:len(ignores)-1]
 			}
-			if w.pol.MaxDirectoryDepth > 0 && d.IsDir() && w.relDirDepth(path, p) > w.pol.MaxDirectoryDepth {
-				w.addNotificationToWalk(fspb.Notification_WARNING, p, fmt.Sprintf("skipping %q: more than %d into base path %q", p, w.pol.MaxDirectoryDepth, path))
-				return filepath.SkipDir
+			// Checking various exclusions based on flags in the walker policy.
+			if s := w.skipEntry(path, p, d, ignores, true); s != nil {
+				return w.recordSkip(p, d.IsDir(), s)
 			}
 
 			info, err := d.Info()
@@ -228,42 +675,457 @@ func (w *Walker) preformWalk(fileCh chan<- *fileInfo) error {
 				msg := fmt.Sprintf("failed to stat %q: %s", p, err)
 				log.Print(msg)
 				w.addNotificationToWalk(fspb.Notification_WARNING, p, msg)
+				w.explainf(p, false, "failed to stat: %s", err)
 				return nil
 			}
 
-			if w.pol.IgnoreIrregularFiles && !info.Mode().IsRegular() && !d.IsDir() {
-				if w.Verbose {
-					w.addNotificationToWalk(fspb.Notification_INFO, p, fmt.Sprintf("skipping %q: irregular file (mode: %s)", p, info.Mode()))
+			if s := w.skipFile(path, baseDev, p, info); s != nil {
+				return w.recordSkip(p, d.IsDir(), s)
+			}
+
+			// Files above the depth band are not recorded but still walked through.
+			if w.pol.MinDirectoryDepth == 0 || entryDepth(path, p) >= w.pol.MinDirectoryDepth {
+				select {
+				case fileCh <- &fileInfo{path: p, info: info, root: path, dev: baseDev}:
+				case <-ctx.Done():
+					return errWalkStopped
 				}
-				return nil
+				walked++
+				w.explainf(p, false, "recorded as part of include %q", path)
+			} else {
+				w.explainf(p, false, "walked through but not recorded, less than minDirectoryDepth %d into include %q", w.pol.MinDirectoryDepth, path)
 			}
-			dev, ok := fsstat.Dev(info)
-			if !w.pol.WalkCrossDevice && ok && baseDev != dev {
-				msg := fmt.Sprintf("skipping %q: file is on different device", p)
-				log.Print(msg)
+
+			if e, ok := w.contentsExcludedBy(p, d.IsDir()); ok {
 				if w.Verbose {
-					w.addNotificationToWalk(fspb.Notification_INFO, p, msg)
-				}
-				if d.IsDir() {
-					return filepath.SkipDir
+					w.addNotificationToWalk(fspb.Notification_INFO, p, fmt.Sprintf("skipping contents of %q: excluded", p))
 				}
-				return nil
+				w.explainContentsf(p, "contents excluded by excludeContents entry %q", e)
+				return filepath.SkipDir
 			}
-
-			fileCh <- &fileInfo{
-				path: p,
-				info: info,
+			if d.IsDir() && w.pol.HonorIgnoreFiles {
+				scope, err := w.readIgnoreFile(p)
+				if err != nil {
+					w.addNotificationToWalk(fspb.Notification_WARNING, p, fmt.Sprintf("unable to read ignore file of %q: %v", p, err))
+				}
+				if len(scope.excludes) > 0 {
+					ignores = append(ignores, scope)
+				}
 			}
 
 			return nil
-		}); err != nil {
+		}); err == errWalkStopped {
+			return nil
+		} else if err != nil {
 			return fmt.Errorf("error walking root include path %q: %v", path, err)
 		}
+		if walked == 0 {
+			w.addNotificationToWalk(fspb.Notification_WARNING, path, fmt.Sprintf("base path %q did not contribute any files to the walk", path))
+		}
+	}
+	return nil
+}
+
+// skip describes why the walk of an include skips an entry.
+type skip struct {
+	// notice is the message of the notification recorded for the entry. It is
+	// recorded as a warning if warn is set, else only in verbose mode.
+	notice string
+	warn   bool
+	// logged makes the notice be logged as well.
+	logged bool
+	// reason is the explanation of the entry in explain mode.
+	reason string
+}
+
+// recordSkip records that the walk skips the entry p, a directory if isDir, and
+// returns the result for the fs.WalkDirFunc skipping it.
+func (w *Walker) recordSkip(p string, isDir bool, s *skip) error {
+	if s.logged {
+		log.Print(s.notice)
+	}
+	if s.warn {
+		w.addNotificationToWalk(fspb.Notification_WARNING, p, s.notice)
+	} else if w.Verbose {
+		w.addNotificationToWalk(fspb.Notification_INFO, p, s.notice)
+	}
+	w.explainf(p, isDir, "%s", s.reason)
+	return skipResult(isDir)
+}
+
+// skipResult returns the result for the fs.WalkDirFunc skipping an entry, which
+// is a directory if isDir.
+func skipResult(isDir bool) error {
+	if isDir {
+		return filepath.SkipDir
+	}
+	return nil
+}
+
+// skipEntry returns why the walk of the include root skips the normalized path p
+// with directory entry d, or nil if it doesn't. ignores are the ignore files of
+// the directories p is in. SkipDirFunc is only called if callSkipDirFunc is set.
+// The checks needing the file info are done by skipFile.
+// Both preformWalk and reaches decide with it, so they never disagree.
+func (w *Walker) skipEntry(root, p string, d fs.DirEntry, ignores []ignoreScope, callSkipDirFunc bool) *skip {
+	if e, ok := excludedBy(p, w.pol.Exclude); ok {
+		return &skip{
+			notice: fmt.Sprintf("skipping %q: excluded", p),
+			reason: fmt.Sprintf("excluded by exclude entry %q", e),
+		}
+	}
+	if e, ok := excludedBy(p, w.SkipPaths); ok {
+		return &skip{
+			notice: fmt.Sprintf("skipping %q: skipped", p),
+			reason: fmt.Sprintf("skipped by SkipPaths entry %q", e),
+		}
+	}
+	if ignoreFile, ok := ignoredBy(p, ignores); ok {
+		return &skip{
+			notice: fmt.Sprintf("skipping %q: excluded by %q", p, ignoreFile),
+			reason: fmt.Sprintf("excluded by ignore file %q", ignoreFile),
+		}
+	}
+	if w.pol.SkipHidden && strings.HasPrefix(d.Name(), ".") && filepath.Clean(p) != root {
+		return &skip{
+			notice: fmt.Sprintf("skipping %q: hidden", p),
+			reason: "hidden and skipHidden is set",
+		}
+	}
+	if d.IsDir() && callSkipDirFunc && w.SkipDirFunc != nil && w.SkipDirFunc(p, d) {
+		return &skip{
+			notice: fmt.Sprintf("skipping %q: skipped by SkipDirFunc", p),
+			reason: "skipped by SkipDirFunc",
+		}
+	}
+	if w.pol.MaxDirectoryDepth > 0 && d.IsDir() && w.relDirDepth(root, p) > w.pol.MaxDirectoryDepth {
+		return &skip{
+			notice: fmt.Sprintf("skipping %q: more than %d into base path %q", p, w.pol.MaxDirectoryDepth, root),
+			warn:   true,
+			reason: fmt.Sprintf("more than maxDirectoryDepth %d into include %q", w.pol.MaxDirectoryDepth, root),
+		}
 	}
 	return nil
 }
 
+// skipFile is like skipEntry but for the checks needing the file info of p.
+// rootDev is the device of root, which is ignored if p has no device number,
+// e.g. in a Walker.FS.
+func (w *Walker) skipFile(root string, rootDev uint64, p string, info fs.FileInfo) *skip {
+	if w.pol.IgnoreIrregularFiles && !info.Mode().IsRegular() && !info.IsDir() {
+		return &skip{
+			notice: fmt.Sprintf("skipping %q: irregular file (mode: %s)", p, info.Mode()),
+			reason: fmt.Sprintf("irregular file (mode: %s) and ignoreIrregularFiles is set", info.Mode()),
+		}
+	}
+	if dev, ok := fsstat.Dev(info); !w.pol.WalkCrossDevice && ok && rootDev != dev && !w.isVirtualDevice(dev) {
+		return &skip{
+			notice: fmt.Sprintf("skipping %q: file is on different device", p),
+			logged: true,
+			reason: fmt.Sprintf("on a different device than include %q and walkCrossDevice is unset", root),
+		}
+	}
+	return nil
+}
+
+// contentsExcludedBy returns the excludeContents entry matching the normalized
+// path p if it is a directory whose contents are not walked.
+func (w *Walker) contentsExcludedBy(p string, isDir bool) (string, bool) {
+	if !isDir {
+		return "", false
+	}
+	return excludedBy(p, w.pol.ExcludeContents)
+}
+
+// walkDir walks the tree at root in w.FS or, if not set, the local file system.
+func (w *Walker) walkDir(root string, fn fs.WalkDirFunc) error {
+	if w.FS != nil {
+		return fs.WalkDir(w.FS, root, fn)
+	}
+	return filepath.WalkDir(root, fn)
+}
+
+// stat returns the file info of name in w.FS or, if not set, the local file system.
+func (w *Walker) stat(name string) (fs.FileInfo, error) {
+	if w.FS != nil {
+		return fs.Stat(w.FS, name)
+	}
+	return os.Stat(name)
+}
+
+// lstat is like stat but doesn't follow symlinks on the local file system.
+func (w *Walker) lstat(name string) (fs.FileInfo, error) {
+	if w.FS != nil {
+		return fs.Stat(w.FS, name)
+	}
+	return os.Lstat(name)
+}
+
+// countEntries returns the number of entries of the directory name in w.FS or,
+// if not set, the local file system.
+func (w *Walker) countEntries(name string) (int, error) {
+	w.acquireFile()
+	defer w.releaseFile()
+	if w.FS != nil {
+		entries, err := fs.ReadDir(w.FS, name)
+		return len(entries), err
+	}
+	d, err := os.Open(name)
+	if err != nil {
+		return 0, err
+	}
+	defer d.Close()
+	names, err := d.Readdirnames(-1)
+	return len(names), err
+}
+
+// prePass counts the files the walk of includes will process and their total
+// size, without processing them. It has no side effects: nothing is recorded
+// in the Walk, and neither is SkipDirFunc called nor are ignore files read, so
+// the counts are an estimate if the policy relies on those.
+func (w *Walker) prePass(ctx context.Context, includes []string) (files, bytes int64) {
+	for _, root := range includes {
+		rootInfo, err := w.stat(root)
+		if err != nil {
+			continue
+		}
+		rootDev, err := fsstat.DevNumber(rootInfo)
+		if err != nil && w.FS == nil {
+			continue
+		}
+		w.walkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if ctx.Err() != nil {
+				return errWalkStopped
+			}
+			if err != nil {
+				return nil
+			}
+			p = NormalizePath(p, d.IsDir())
+			if w.skipEntry(root, p, d, nil, false) != nil {
+				return skipResult(d.IsDir())
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			if w.skipFile(root, rootDev, p, info) != nil {
+				return skipResult(d.IsDir())
+			}
+			if w.pol.MinDirectoryDepth == 0 || entryDepth(root, p) >= w.pol.MinDirectoryDepth {
+				files++
+				bytes += info.Size()
+			}
+			if _, ok := w.contentsExcludedBy(p, d.IsDir()); ok {
+				return filepath.SkipDir
+			}
+			return nil
+		})
+	}
+	return files, bytes
+}
+
+// dedupeIncludes returns the cleaned and sorted include paths of the policy.
+// Includes which are walked as part of another include anyway are dropped, as
+// their files would otherwise end up in the Walk twice.
+func (w *Walker) dedupeIncludes() []string {
+	includes := make([]string, 0, len(w.pol.Include))
+	for _, p := range w.pol.Include {
+		includes = append(includes, filepath.Clean(p))
+	}
+	slices.Sort(includes)
+
+	var kept []string
+	for i, p := range includes {
+		if i > 0 && includes[i-1] == p {
+			w.addNotificationToWalk(fspb.Notification_INFO, p, fmt.Sprintf("dropping include %q: listed more than once", p))
+			continue
+		}
+		if root, ok := w.coveringInclude(kept, p); ok {
+			w.addNotificationToWalk(fspb.Notification_INFO, p, fmt.Sprintf("dropping include %q: already walked as part of %q", p, root))
+			continue
+		}
+		kept = append(kept, p)
+	}
+	return kept
+}
+
+// coveringInclude returns the include of roots whose walk reaches path.
+func (w *Walker) coveringInclude(roots []string, path string) (string, bool) {
+	for _, root := range roots {
+		if root != string(filepath.Separator) && root != "." && !strings.HasPrefix(path, root+string(filepath.Separator)) {
+			continue
+		}
+		if w.reaches(root, path) {
+			return root, true
+		}
+	}
+	return "", false
+}
+
+// reaches returns true if walking root discovers path, which must be below root.
+// It applies the same checks as preformWalk to not drop includes which would be
+// skipped otherwise.
+func (w *Walker) reaches(root, path string) bool {
+	rootInfo, err := w.stat(root)
+	if err != nil {
+		return false
+	}
+	// preformWalk skips includes without a device number, except in a Walker.FS
+	// where files may have none at all and are regarded as on the same device.
+	rootDev, err := fsstat.DevNumber(rootInfo)
+	if err != nil && w.FS == nil {
+		return false
+	}
+	var ignores []ignoreScope
+	if w.pol.HonorIgnoreFiles {
+		for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+			scope, err := w.readIgnoreFile(dir)
+			if err != nil {
+				return false
+			}
+			ignores = append(ignores, scope)
+			if dir == root || dir == filepath.Dir(dir) {
+				break
+			}
+		}
+	}
+	for p := path; p != root; p = filepath.Dir(p) {
+		info, err := w.lstat(p)
+		if err != nil {
+			return false
+		}
+		// Symlinks are not followed, so the walk of root can only reach path through directories.
+		if p != path && !info.IsDir() {
+			return false
+		}
+		np := NormalizePath(p, info.IsDir())
+		if w.skipEntry(root, np, fs.FileInfoToDirEntry(info), ignores, true) != nil || w.skipFile(root, rootDev, np, info) != nil {
+			return false
+		}
+		if _, ok := w.contentsExcludedBy(np, p != path); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// countMissingStat counts fi as lacking stat info on its filesystem. As its device
+// is unknown without stat info, the filesystem is looked up in the mount table.
+// If that isn't available, e.g. in a Walker.FS, the device of its include is used.
+// The caller must hold walkMu.
+func (w *Walker) countMissingStat(fi *fileInfo) {
+	if w.FS == nil && !w.missingStatMountsLoaded {
+		w.missingStatMountsLoaded = true
+		w.missingStatMounts, _ = readMountInfo()
+	}
+	dev, path := fi.dev, fi.root
+	if m := mountinfo.Containing(w.missingStatMounts, filepath.Clean(fi.path)); m != nil {
+		dev, path = m.Dev(), m.MountPoint
+	}
+	if w.missingStat == nil {
+		w.missingStat = map[uint64]*missingStatFiles{}
+	}
+	m := w.missingStat[dev]
+	if m == nil {
+		m = &missingStatFiles{path: path}
+		w.missingStat[dev] = m
+	}
+	// Files are processed concurrently, so the include named doesn't depend on their order.
+	if path < m.path {
+		m.path = path
+	}
+	m.files++
+}
+
+// addMissingStatWarnings records a warning for every filesystem with files lacking stat info.
+func (w *Walker) addMissingStatWarnings() {
+	missing := make([]*missingStatFiles, 0, len(w.missingStat))
+	for _, m := range w.missingStat {
+		missing = append(missing, m)
+	}
+	slices.SortFunc(missing, func(a, b *missingStatFiles) bool {
+		return a.path < b.path
+	})
+	for _, m := range missing {
+		w.addNotificationToWalk(fspb.Notification_WARNING, m.path, fmt.Sprintf("no file stat available for %d files on the filesystem at %q, only basic file info was recorded", m.files, m.path))
+	}
+}
+
+// loadMounts reads the mount table. Failing to do so is recorded as a warning
+// and leaves the walk as if no mount information was requested.
+func (w *Walker) loadMounts() {
+	mounts, err := readMountInfo()
+	if err != nil {
+		w.addNotificationToWalk(fspb.Notification_WARNING, "", fmt.Sprintf("unable to read mount info: %v", err))
+		return
+	}
+	w.mounts = mountinfo.ByDevice(mounts)
+	if w.pol.RecordBindMounts {
+		w.bindSources = mountinfo.BindSources(mounts)
+	}
+}
+
+// isVirtualDevice returns true if dev belongs to an overlay or FUSE filesystem
+// and the policy allows walking across those.
+func (w *Walker) isVirtualDevice(dev uint64) bool {
+	if !w.pol.WalkCrossVirtualDevice {
+		return false
+	}
+	m, ok := w.mounts[dev]
+	return ok && mountinfo.IsVirtual(m.FSType)
+}
+
+// recordedMtime returns the modification time mt as recorded according to the
+// policy: the Unix epoch if it sets zeroMtime, mt relative to the mtimeReference
+// if set and mt itself otherwise.
+func (w *Walker) recordedMtime(mt time.Time) time.Time {
+	switch {
+	case w.pol.ZeroMtime:
+		return time.Unix(0, 0)
+	case !w.mtimeRef.IsZero():
+		return time.Unix(mt.Unix()-w.mtimeRef.Unix(), int64(mt.Nanosecond()-w.mtimeRef.Nanosecond()))
+	}
+	return mt
+}
+
+// onExcludedHashingFilesystem returns true if the file described by info is on a
+// filesystem whose files the policy excludes from hashing.
+func (w *Walker) onExcludedHashingFilesystem(info fs.FileInfo) bool {
+	if len(w.pol.ExcludeHashingFilesystems) == 0 {
+		return false
+	}
+	dev, ok := fsstat.Dev(info)
+	if !ok {
+		return false
+	}
+	m, ok := w.mounts[dev]
+	if !ok {
+		return false
+	}
+	for _, t := range w.pol.ExcludeHashingFilesystems {
+		if fsTypeMatches(m.FSType, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// fsTypeMatches returns true if the filesystem type fsType is t, one of its
+// subtypes (e.g. "fuse.sshfs" for "fuse") or one of its versions (e.g. "nfs4"
+// for "nfs").
+func fsTypeMatches(fsType, t string) bool {
+	if fsType == t || strings.HasPrefix(fsType, t+".") {
+		return true
+	}
+	version := strings.TrimPrefix(fsType, t)
+	return len(version) < len(fsType) && version != "" && strings.Trim(version, "0123456789") == ""
+}
+
+// addNotificationToWalk records a notification in the Walk. It takes walkMu as
+// includes may be walked concurrently, so callers must not hold it.
 func (w *Walker) addNotificationToWalk(s fspb.Notification_Severity, path, msg string) {
+	w.walkMu.Lock()
+	defer w.walkMu.Unlock()
 	w.walk.Notification = append(w.walk.Notification, &fspb.Notification{
 		Severity: s,
 		Path:     path,
@@ -277,8 +1139,21 @@ func (w *Walker) relDirDepth(origin, path string) uint32 {
 	return uint32(len(strings.Split(path, string(filepath.Separator))) - len(strings.Split(origin, string(filepath.Separator))))
 }
 
+// entryDepth returns how many levels path is below root, e.g. 1 for the direct
+// entries of root and 0 for root itself.
+func entryDepth(root, path string) uint32 {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return uint32(strings.Count(rel, string(filepath.Separator)) + 1)
+}
+
 func (w *Walker) worker(fileCh <-chan *fileInfo, errCh chan<- *workerErr) {
-	hasher := sha256.New()
+	var hasher hash.Hash = sha256.New()
+	if w.pol.HashSha512 {
+		hasher = newSHA256And512()
+	}
 	for file := range fileCh {
 		w.process(file, hasher, errCh)
 	}
@@ -296,9 +1171,9 @@ func (w *Walker) process(fi *fileInfo, h hash.Hash, errCh chan<- *workerErr) {
 			fmt.Sprintf("size(%d)", f.Info.Size),
 			fmt.Sprintf("mode(%v)", os.FileMode(f.Info.Mode)),
 			fmt.Sprintf("mTime(%v)", ts),
-			fmt.Sprintf("uid(%d)", f.Stat.Uid),
-			fmt.Sprintf("gid(%d)", f.Stat.Gid),
-			fmt.Sprintf("inode(%d)", f.Stat.Inode),
+			fmt.Sprintf("uid(%d)", f.Stat.GetUid()),
+			fmt.Sprintf("gid(%d)", f.Stat.GetGid()),
+			fmt.Sprintf("inode(%d)", f.Stat.GetInode()),
 		}
 		for _, fp := range f.Fingerprint {
 			info = append(info, fmt.Sprintf("%s(%s)", fspb.Fingerprint_Method_name[int32(fp.Method)], fp.Value))
@@ -306,11 +1181,21 @@ func (w *Walker) process(fi *fileInfo, h hash.Hash, errCh chan<- *workerErr) {
 		fmt.Println(strings.Join(info, ", "))
 	}
 
+	if w.OnFile != nil {
+		w.OnFile(f)
+	}
+
 	// Add file to the walk which will later be written out to disk.
 	w.walkMu.Lock()
 	defer w.walkMu.Unlock()
 	w.walk.File = append(w.walk.File, f)
 
+	w.progress.Files++
+	w.progress.Bytes += f.Info.Size
+	if w.ProgressCallback != nil {
+		w.ProgressCallback(w.progress)
+	}
+
 	// Collect some metrics.
 	if w.Counter != nil {
 		if f.Info.IsDir {
@@ -325,43 +1210,205 @@ func (w *Walker) process(fi *fileInfo, h hash.Hash, errCh chan<- *workerErr) {
 		if len(f.Fingerprint) > 0 {
 			w.Counter.Add(1, countHashes)
 		}
+		if w.DetailedMetrics && !f.Info.IsDir {
+			w.Counter.Add(1, SizeBucketPrefix+sizeBucket(f.Info.Size))
+		}
 	}
 }
 
+// sizeBucket returns the name of the size bucket of a file with the given size.
+func sizeBucket(size int64) string {
+	for _, b := range sizeBuckets {
+		if size < b.max {
+			return b.name
+		}
+	}
+	return sizeBuckets[len(sizeBuckets)-1].name
+}
+
+// relPath returns path relative to the policy's relativeTo directory, if set.
+// Paths outside of that directory are returned unchanged.
+func (w *Walker) relPath(path string) string {
+	if w.pol.RelativeTo == "" {
+		return path
+	}
+	rel, err := filepath.Rel(filepath.Clean(w.pol.RelativeTo), path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return rel
+}
+
+// maxHashFileSize returns the maximum size of the given file to still be hashed.
+// Per-extension limits take precedence over the global limit.
+func (w *Walker) maxHashFileSize(path string) uint64 {
+	if limit, ok := w.pol.MaxHashFileSizeByExtension[filepath.Ext(path)]; ok {
+		return limit
+	}
+	return w.pol.MaxHashFileSize
+}
+
+// fingerprint hashes the file at path, or the content of the block device at path
+// if blockDevice is true, and returns its fingerprints.
+// Failures are sent to errCh under the name recordedPath.
+func (w *Walker) fingerprint(path, recordedPath string, blockDevice bool, h hash.Hash, errCh chan<- *workerErr) []*fspb.Fingerprint {
+	w.acquireFile()
+	defer w.releaseFile()
+	var buf []byte
+	if w.hashBufs != nil {
+		bufp := w.hashBufs.Get().(*[]byte)
+		defer w.hashBufs.Put(bufp)
+		buf = *bufp
+	}
+	var shaSum string
+	var err error
+	switch {
+	case blockDevice && w.FS != nil:
+		shaSum, err = sha256sumBlockDeviceFS(w.FS, path, h, int64(w.pol.MaxBlockDeviceHashSize), buf)
+	case blockDevice:
+		shaSum, err = sha256sumBlockDevice(path, h, int64(w.pol.MaxBlockDeviceHashSize), buf)
+	case w.FS != nil:
+		shaSum, err = sha256sumFS(w.FS, path, h, buf)
+	default:
+		shaSum, err = sha256sumNoFollow(path, h, buf)
+	}
+	switch {
+	case errors.Is(err, errFileChanged):
+		errCh <- &workerErr{
+			severity: fspb.Notification_WARNING,
+			path:     recordedPath,
+			err:      fmt.Errorf("refusing to build hash: %w", err),
+		}
+		return nil
+	case err != nil:
+		errCh <- &workerErr{
+			severity: fspb.Notification_ERROR,
+			path:     recordedPath,
+			err:      fmt.Errorf("unable to build hash: %w", err),
+		}
+		return nil
+	}
+	if len(shaSum) == sha256AndSHA512HexLen {
+		// Built by a sha256And512 hash.
+		return []*fspb.Fingerprint{
+			{Method: fspb.Fingerprint_SHA256, Value: shaSum[:2*sha256.Size]},
+			{Method: fspb.Fingerprint_SHA512, Value: shaSum[2*sha256.Size:]},
+		}
+	}
+	return []*fspb.Fingerprint{
+		{
+			Method: fspb.Fingerprint_SHA256,
+			Value:  shaSum,
+		},
+	}
+}
+
+// unchangedFingerprints returns the SHA256 (and SHA512 if requested by the policy) fingerprints
+// of the baseline entry of the file recorded as path if its size, mtime and ctime are unchanged,
+// or nil if it needs to be hashed.
+func (w *Walker) unchangedFingerprints(path string, info fs.FileInfo) []*fspb.Fingerprint {
+	bf := w.baselineFiles[path]
+	if bf == nil || bf.Info.GetSize() != info.Size() || !bf.Info.GetModified().AsTime().Equal(w.recordedMtime(info.ModTime())) {
+		return nil
+	}
+	st, err := fsstat.ToStat(info, fsstat.FieldCtime)
+	if err != nil || bf.Stat.GetCtime() == nil || !proto.Equal(bf.Stat.GetCtime(), st.Ctime) {
+		return nil
+	}
+	var fps []*fspb.Fingerprint
+	var sha512 bool
+	for _, fp := range bf.Fingerprint {
+		switch {
+		case fp.Method == fspb.Fingerprint_SHA256:
+			fps = append(fps, fp)
+		case fp.Method == fspb.Fingerprint_SHA512 && w.pol.HashSha512:
+			fps = append(fps, fp)
+			sha512 = true
+		}
+	}
+	if w.pol.HashSha512 && !sha512 {
+		// The baseline wasn't hashed with SHA-512.
+		return nil
+	}
+	return fps
+}
+
 // convert creates a File from the given information and if requested embeds the hash sum too.
 func (w *Walker) convert(fi *fileInfo, h hash.Hash, errCh chan<- *workerErr) *fspb.File {
 	path := filepath.Clean(fi.path)
 
 	f := &fspb.File{
 		Version: fileVersion,
-		Path:    path,
+		Path:    w.relPath(path),
 	}
 
 	if fi.info == nil {
 		return f
 	}
 
-	var shaSum string
 	// Only build the hash sum if requested and if it is not a directory.
-	if !isExcluded(fi.path, w.pol.ExcludeHashing) && fi.info.Mode().IsRegular() && uint64(fi.info.Size()) <= w.pol.MaxHashFileSize {
-		var err error
-		shaSum, err = sha256sum(path, h)
-		if err != nil {
-			errCh <- &workerErr{
-				path: f.Path,
-				err:  fmt.Sprintf("unable to build hash: %v", err),
+	if !isExcluded(fi.path, w.pol.ExcludeHashing) && fi.info.Mode().IsRegular() && uint64(fi.info.Size()) <= w.maxHashFileSize(path) && !w.onExcludedHashingFilesystem(fi.info) {
+		if w.baselineScope != nil && !w.baselineScope.contains(path, false) {
+			// Comparing the walk with the baseline doesn't report the file.
+			w.walkMu.Lock()
+			if w.Counter != nil {
+				w.Counter.Add(1, countHashSkippedOutOfScope)
+			}
+			w.walkMu.Unlock()
+		} else if fps := w.unchangedFingerprints(f.Path, fi.info); fps != nil {
+			f.Fingerprint = fps
+			if w.pol.RecordEntropy {
+				f.Entropy = w.baselineFiles[f.Path].Entropy
+			}
+			w.walkMu.Lock()
+			if w.Counter != nil {
+				w.Counter.Add(1, countHashSkipped)
+			}
+			w.walkMu.Unlock()
+		} else if w.pol.RecordEntropy {
+			eh := &entropyHash{Hash: h}
+			if f.Fingerprint = w.fingerprint(path, f.Path, false, eh, errCh); f.Fingerprint != nil {
+				f.Entropy = proto.Float64(eh.entropy())
 			}
 		} else {
-			f.Fingerprint = []*fspb.Fingerprint{
-				{
-					Method: fspb.Fingerprint_SHA256,
-					Value:  shaSum,
-				},
+			f.Fingerprint = w.fingerprint(path, f.Path, false, h, errCh)
+		}
+	} else if w.pol.HashBlockDevices && isBlockDevice(fi.info.Mode()) && !isExcluded(fi.path, w.pol.ExcludeHashing) {
+		// The modification time of a device node doesn't change with its content,
+		// so its fingerprints are never taken from the baseline.
+		f.Fingerprint = w.fingerprint(path, f.Path, true, h, errCh)
+	}
+
+	if fi.info.IsDir() {
+		f.BindMountSource = w.bindSources[path]
+	}
+	if fi.info.IsDir() && w.pol.RecordEntryCount {
+		if n, err := w.countEntries(path); err != nil {
+			errCh <- &workerErr{
+				severity: fspb.Notification_WARNING,
+				path:     f.Path,
+				err:      fmt.Errorf("unable to count directory entries: %w", err),
 			}
+		} else {
+			f.EntryCount = proto.Uint64(uint64(n))
 		}
 	}
 
-	mts := tspb.New(fi.info.ModTime()) // ignoring the error and using default
+	mts := tspb.New(w.recordedMtime(fi.info.ModTime())) // ignoring the error and using default
+	// A modification time in the future hints at tampering or clock skew. As the
+	// file was stat'ed just before, files written during a long walk are not flagged.
+	if now := time.Now(); fi.info.ModTime().After(now.Add(futureMtimeSlack)) {
+		w.walkMu.Lock()
+		w.futureMtimes = append(w.futureMtimes, &fspb.Notification{
+			Severity: fspb.Notification_WARNING,
+			Path:     f.Path,
+			Message:  fmt.Sprintf("modification time %s is in the future (recorded at %s)", fi.info.ModTime().UTC().Format(time.RFC3339), now.UTC().Format(time.RFC3339)),
+		})
+		if w.Counter != nil {
+			w.Counter.Add(1, countFutureMtime)
+		}
+		w.walkMu.Unlock()
+	}
 	f.Info = &fspb.FileInfo{
 		Name:     fi.info.Name(),
 		Size:     fi.info.Size(),
@@ -371,12 +1418,54 @@ func (w *Walker) convert(fi *fileInfo, h hash.Hash, errCh chan<- *workerErr) *fs
 	}
 
 	var err error
-	if f.Stat, err = fsstat.ToStat(fi.info); err != nil {
-		errCh <- &workerErr{
-			path: f.Path,
-			err:  err.Error(),
+	if f.Stat, err = fsstat.ToStat(fi.info, ^w.droppedStatFields); err != nil {
+		// Some filesystems (e.g. certain FUSE mounts) don't provide stat info at all.
+		// The file is still recorded with its basic info and a single warning is
+		// added per filesystem instead of an error for every file.
+		w.walkMu.Lock()
+		w.countMissingStat(fi)
+		w.walkMu.Unlock()
+	} else if f.Stat.Mtime != nil {
+		f.Stat.Mtime = tspb.New(w.recordedMtime(f.Stat.Mtime.AsTime()))
+	}
+	if w.pol.CollectAttributes && f.Stat != nil && w.FS == nil {
+		w.acquireFile()
+		attrs, verityFp, err := fsstat.Attributes(path)
+		w.releaseFile()
+		if err != nil {
+			errCh <- &workerErr{
+				severity: fspb.Notification_ERROR,
+				path:     f.Path,
+				err:      err,
+			}
+		}
+		if w.droppedStatFields&fsstat.FieldAttributes == 0 {
+			f.Stat.Attributes = attrs
+		}
+		if verityFp != nil {
+			f.Fingerprint = append(f.Fingerprint, verityFp)
+		}
+	}
+	if w.pol.CollectBirthTime && f.Stat != nil && w.FS == nil && w.droppedStatFields&fsstat.FieldBtime == 0 {
+		if f.Stat.Btime, err = fsstat.BirthTime(path, fi.info); err != nil {
+			errCh <- &workerErr{
+				severity: fspb.Notification_ERROR,
+				path:     f.Path,
+				err:      err,
+			}
+		}
+	}
+	if w.pol.CollectDataStreams && fi.info.Mode().IsRegular() && w.FS == nil {
+		w.acquireFile()
+		f.DataStream, err = fsstat.DataStreams(path, w.maxHashFileSize(path))
+		w.releaseFile()
+		if err != nil {
+			errCh <- &workerErr{
+				severity: fspb.Notification_ERROR,
+				path:     f.Path,
+				err:      err,
+			}
 		}
 	}
-
 	return f
 }
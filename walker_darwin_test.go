@@ -1,7 +1,20 @@
 package fswalker
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
 	"syscall"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/sys/unix"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+	tspb "google.golang.org/protobuf/types/known/timestamppb"
+
+	fspb "github.com/google/fswalker/proto/fswalker"
 )
 
 func setTimes(st syscall.Stat_t, a, m, c syscall.Timespec) syscall.Stat_t {
@@ -11,3 +24,63 @@ func setTimes(st syscall.Stat_t, a, m, c syscall.Timespec) syscall.Stat_t {
 
 	return st
 }
+
+func TestConvertBirthTime(t *testing.T) {
+	path := filepath.Join(testdataDir, "hashSumTest")
+	btime := syscall.Timespec{Sec: 1543831000, Nsec: 400}
+	info := &testFile{
+		name: "hashSumTest",
+		size: 100,
+		mode: os.FileMode(0640),
+		sys:  &syscall.Stat_t{Birthtimespec: btime},
+	}
+
+	wlkr := &Walker{pol: &fspb.Policy{}}
+	f := wlkr.convert(&fileInfo{path: path, info: info}, sha256.New(), nil)
+	if f.Stat.Btime != nil {
+		t.Errorf("convert() without collectBirthTime set btime %s", f.Stat.Btime.AsTime())
+	}
+
+	wlkr.pol.CollectBirthTime = true
+	f = wlkr.convert(&fileInfo{path: path, info: info}, sha256.New(), nil)
+	want := &tspb.Timestamp{Seconds: btime.Sec, Nanos: int32(btime.Nsec)}
+	if !proto.Equal(f.Stat.Btime, want) {
+		t.Errorf("convert() btime = %v; want %v", f.Stat.Btime, want)
+	}
+}
+
+func TestRunResourceFork(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"plain": "content", "forked": "content"})
+	fork := []byte("hidden payload")
+	if err := unix.Setxattr(filepath.Join(root, "forked"), "com.apple.ResourceFork", fork, 0); err != nil {
+		t.Skipf("unable to set resource fork: %v", err)
+	}
+
+	walk := runWalk(t, &fspb.Policy{Include: []string{root}, CollectDataStreams: true})
+	if !walk.DataStreamsCollected {
+		t.Error("Run() with collectDataStreams didn't set dataStreamsCollected")
+	}
+	sum := sha256.Sum256(fork)
+	want := map[string][]*fspb.DataStream{
+		"plain": nil,
+		"forked": {{
+			Name:        "com.apple.ResourceFork",
+			Size:        int64(len(fork)),
+			Fingerprint: &fspb.Fingerprint{Method: fspb.Fingerprint_SHA256, Value: hex.EncodeToString(sum[:])},
+		}},
+	}
+	for _, f := range walk.File {
+		name := filepath.Base(f.Path)
+		if _, ok := want[name]; !ok {
+			continue
+		}
+		if diff := cmp.Diff(want[name], f.DataStream, protocmp.Transform()); diff != "" {
+			t.Errorf("%s: data streams diff (-want +got):\n%s", name, diff)
+		}
+		delete(want, name)
+	}
+	if len(want) > 0 {
+		t.Errorf("files not walked: %v", want)
+	}
+}
@@ -1,7 +1,19 @@
 package fswalker
 
 import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"syscall"
+	"testing"
+	"time"
+
+	"github.com/google/fswalker/internal/mountinfo"
+	fspb "github.com/google/fswalker/proto/fswalker"
 )
 
 func setTimes(st syscall.Stat_t, a, m, c syscall.Timespec) syscall.Stat_t {
@@ -11,3 +23,252 @@ func setTimes(st syscall.Stat_t, a, m, c syscall.Timespec) syscall.Stat_t {
 
 	return st
 }
+
+func TestWalkCrossVirtualDevice(t *testing.T) {
+	readMountInfoOrig := readMountInfo
+	defer func() { readMountInfo = readMountInfoOrig }()
+	readMountInfo = func() ([]*mountinfo.Mount, error) {
+		return mountinfo.Parse(strings.NewReader(`22 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw
+36 22 0:52 / /merged rw,relatime - overlay overlay rw,lowerdir=/l,upperdir=/u,workdir=/w
+37 22 8:2 / /mnt/bind rw,relatime - ext4 /dev/sda2 rw
+`))
+	}
+
+	testCases := []struct {
+		desc        string
+		walkVirtual bool
+		dev         uint64
+		want        bool
+	}{
+		{
+			desc:        "overlay device",
+			walkVirtual: true,
+			dev:         mountinfo.Mkdev(0, 52),
+			want:        true,
+		}, {
+			desc:        "overlay device without policy",
+			walkVirtual: false,
+			dev:         mountinfo.Mkdev(0, 52),
+			want:        false,
+		}, {
+			desc:        "real device",
+			walkVirtual: true,
+			dev:         mountinfo.Mkdev(8, 2),
+			want:        false,
+		}, {
+			desc:        "unknown device",
+			walkVirtual: true,
+			dev:         mountinfo.Mkdev(9, 9),
+			want:        false,
+		},
+	}
+	for _, tc := range testCases {
+		w := &Walker{
+			pol:  &fspb.Policy{WalkCrossVirtualDevice: tc.walkVirtual},
+			walk: &fspb.Walk{},
+		}
+		w.loadMounts()
+		if got := w.isVirtualDevice(tc.dev); got != tc.want {
+			t.Errorf("%s: isVirtualDevice() = %v; want %v", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestConvertBirthTime(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	before := time.Now().Add(-time.Minute)
+	if err := os.WriteFile(path, []byte("content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wlkr := &Walker{pol: &fspb.Policy{}}
+	f := wlkr.convert(&fileInfo{path: path, info: info}, sha256.New(), nil)
+	if f.Stat.Btime != nil {
+		t.Errorf("convert() without collectBirthTime set btime %s", f.Stat.Btime.AsTime())
+	}
+
+	wlkr.pol.CollectBirthTime = true
+	f = wlkr.convert(&fileInfo{path: path, info: info}, sha256.New(), nil)
+	if f.Stat.Btime == nil {
+		t.Skip("birth time is not supported by the kernel or filesystem")
+	}
+	if bt := f.Stat.Btime.AsTime(); bt.Before(before) || bt.After(time.Now()) {
+		t.Errorf("convert() btime = %s; want between %s and now", bt, before)
+	}
+}
+
+func TestConvertBindMountSource(t *testing.T) {
+	readMountInfoOrig := readMountInfo
+	defer func() { readMountInfo = readMountInfoOrig }()
+	readMountInfo = func() ([]*mountinfo.Mount, error) {
+		return mountinfo.Parse(strings.NewReader(`22 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw
+37 22 8:1 /srv/data /mnt/data rw,relatime - ext4 /dev/sda1 rw
+`))
+	}
+
+	testCases := []struct {
+		desc       string
+		record     bool
+		path       string
+		isDir      bool
+		wantSource string
+	}{
+		{
+			desc:       "bind mount",
+			record:     true,
+			path:       "/mnt/data",
+			isDir:      true,
+			wantSource: "/srv/data",
+		}, {
+			desc:   "bind mount without policy",
+			record: false,
+			path:   "/mnt/data",
+			isDir:  true,
+		}, {
+			desc:   "regular directory",
+			record: true,
+			path:   "/srv/data",
+			isDir:  true,
+		}, {
+			desc:   "file below bind mount",
+			record: true,
+			path:   "/mnt/data/file",
+		},
+	}
+	for _, tc := range testCases {
+		w := &Walker{
+			pol:  &fspb.Policy{RecordBindMounts: tc.record},
+			walk: &fspb.Walk{},
+		}
+		w.loadMounts()
+		// A non-zero size prevents hashing the non-existent files.
+		info := &testFile{name: filepath.Base(tc.path), size: 1, isDir: tc.isDir, sys: &syscall.Stat_t{}}
+		if tc.isDir {
+			info.mode = os.ModeDir
+		}
+		f := w.convert(&fileInfo{path: tc.path, info: info}, sha256.New(), nil)
+		if f.BindMountSource != tc.wantSource {
+			t.Errorf("%s: convert() bindMountSource = %q; want %q", tc.desc, f.BindMountSource, tc.wantSource)
+		}
+	}
+}
+
+func TestConvertExcludeHashingFilesystems(t *testing.T) {
+	readMountInfoOrig := readMountInfo
+	defer func() { readMountInfo = readMountInfoOrig }()
+	readMountInfo = func() ([]*mountinfo.Mount, error) {
+		return mountinfo.Parse(strings.NewReader(`22 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw
+40 22 0:50 / /mnt/nfs rw,relatime - nfs4 server:/export rw
+41 22 0:51 / /mnt/ssh rw,relatime - fuse.sshfs user@host: rw
+`))
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file")
+	if err := os.WriteFile(path, []byte("content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		desc     string
+		dev      uint64
+		excluded []string
+		wantHash bool
+	}{
+		{
+			desc:     "local filesystem",
+			dev:      mountinfo.Mkdev(8, 1),
+			excluded: []string{"nfs4", "fuse"},
+			wantHash: true,
+		}, {
+			desc:     "excluded filesystem",
+			dev:      mountinfo.Mkdev(0, 50),
+			excluded: []string{"nfs4", "fuse"},
+		}, {
+			desc:     "excluded fuse subtype",
+			dev:      mountinfo.Mkdev(0, 51),
+			excluded: []string{"nfs4", "fuse"},
+		}, {
+			desc:     "excluded filesystem version",
+			dev:      mountinfo.Mkdev(0, 50),
+			excluded: []string{"nfs"},
+		}, {
+			desc:     "filesystem not excluded",
+			dev:      mountinfo.Mkdev(0, 50),
+			excluded: []string{"cifs", "nfs4.1", "nf"},
+			wantHash: true,
+		}, {
+			desc:     "unknown device",
+			dev:      mountinfo.Mkdev(0, 99),
+			excluded: []string{"nfs4"},
+			wantHash: true,
+		},
+	}
+	for _, tc := range testCases {
+		w := &Walker{
+			pol: &fspb.Policy{
+				MaxHashFileSize:           1024,
+				ExcludeHashingFilesystems: tc.excluded,
+			},
+			walk: &fspb.Walk{},
+		}
+		w.loadMounts()
+		// The device decides about hashing, the file itself is on the temp directory.
+		info := &testFile{name: "file", size: 7, sys: &syscall.Stat_t{Dev: tc.dev}}
+		f := w.convert(&fileInfo{path: path, info: info}, sha256.New(), nil)
+		if got := len(f.Fingerprint) > 0; got != tc.wantHash {
+			t.Errorf("%s: convert() hashed = %v; want %v", tc.desc, got, tc.wantHash)
+		}
+		if f.Info == nil || f.Stat == nil {
+			t.Errorf("%s: convert() didn't record metadata: %v", tc.desc, f)
+		}
+	}
+}
+
+func TestSha256sumBlockDevice(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("reading block devices requires root")
+	}
+	entries, err := os.ReadDir("/dev")
+	if err != nil {
+		t.Skip(err)
+	}
+	const limit = 4096
+	for _, e := range entries {
+		path := filepath.Join("/dev", e.Name())
+		info, err := os.Lstat(path)
+		if err != nil || !isBlockDevice(info.Mode()) {
+			continue
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		want := make([]byte, limit)
+		n, err := io.ReadFull(f, want)
+		f.Close()
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			continue
+		}
+		got, err := sha256sumBlockDevice(path, sha256.New(), limit, nil)
+		if err != nil {
+			t.Fatalf("sha256sumBlockDevice(%q) error: %v", path, err)
+		}
+		if wantSum := fmt.Sprintf("%x", sha256.Sum256(want[:n])); got != wantSum {
+			t.Errorf("sha256sumBlockDevice(%q) = %q; want %q", path, got, wantSum)
+		}
+
+		regular := filepath.Join(t.TempDir(), "file")
+		if err := os.WriteFile(regular, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := sha256sumBlockDevice(regular, sha256.New(), limit, nil); !errors.Is(err, errFileChanged) {
+			t.Errorf("sha256sumBlockDevice(%q) error = %v; want %v", regular, err, errFileChanged)
+		}
+		return
+	}
+	t.Skip("no readable block device found")
+}
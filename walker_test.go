@@ -15,32 +15,75 @@
 package fswalker
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
 	"os"
+	"path"
 	"path/filepath"
 	"reflect"
 	"sort"
+	"strings"
+	"sync"
 	"syscall"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"golang.org/x/exp/slices"
 	"google.golang.org/protobuf/proto"
 	tspb "google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/google/fswalker/internal/metrics"
+	"github.com/google/fswalker/internal/mountinfo"
 	fspb "github.com/google/fswalker/proto/fswalker"
 )
 
 type outpathWriter string
 
 func (o outpathWriter) writeWalk(walk *fspb.Walk) error {
-	walkBytes, err := proto.Marshal(walk)
+	mode, err := ParseFileMode(walk.GetPolicy().GetOutputFileMode(), DefaultWalkFileMode)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(string(o), walkBytes, 0444)
+	return WriteWalk(string(o), walk, mode)
+}
+
+// runWalk runs a Walker with the given policy and returns the resulting Walk.
+func runWalk(t *testing.T, pol *fspb.Policy) *fspb.Walk {
+	t.Helper()
+	var walk *fspb.Walk
+	wlkr := &Walker{
+		pol: pol,
+		WalkCallback: func(w *fspb.Walk) error {
+			walk = w
+			return nil
+		},
+	}
+	if err := wlkr.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	return walk
+}
+
+// writeFiles creates the given files with their content below dir, including parent directories.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
 }
 
 // testFile implements the os.FileInfo interface.
@@ -62,7 +105,7 @@ func (t *testFile) IsDir() bool        { return t.isDir }
 func (t *testFile) Sys() interface{}   { return t.sys }
 
 func TestWalkerFromPolicyFile(t *testing.T) {
-	path := filepath.Join(testdataDir, "defaultClientPolicy.asciipb")
+	path := filepath.Join(testdataDir, "defaultClientPolicy.toml")
 	wantPol := &fspb.Policy{
 		Version:         1,
 		MaxHashFileSize: 1048576,
@@ -91,6 +134,50 @@ func TestWalkerFromPolicyFile(t *testing.T) {
 	}
 }
 
+func TestWalkerFromPolicyBytes(t *testing.T) {
+	b, err := os.ReadFile(filepath.Join(testdataDir, "defaultClientPolicy.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := WalkerFromPolicyFile(filepath.Join(testdataDir, "defaultClientPolicy.toml"))
+	if err != nil {
+		t.Fatalf("WalkerFromPolicyFile() error: %v", err)
+	}
+
+	got, err := WalkerFromPolicyBytes(b)
+	if err != nil {
+		t.Fatalf("WalkerFromPolicyBytes() error: %v", err)
+	}
+	if diff := cmp.Diff(want.pol, got.pol, cmp.Comparer(proto.Equal)); diff != "" {
+		t.Errorf("WalkerFromPolicyBytes() policy: diff (-want +got):\n%s", diff)
+	}
+	if got.Counter == nil {
+		t.Error("WalkerFromPolicyBytes() left Counter unset")
+	}
+
+	if _, err := WalkerFromPolicyBytes([]byte("includ = [\"/\"]")); err == nil {
+		t.Error("WalkerFromPolicyBytes() with an unknown key succeeded; want error")
+	}
+}
+
+func TestWalkerFromPolicyEnv(t *testing.T) {
+	t.Setenv(PolicyEnvVar, "")
+	os.Unsetenv(PolicyEnvVar)
+	if _, err := WalkerFromPolicyFile(""); err == nil {
+		t.Errorf("WalkerFromPolicyFile(\"\") without %s succeeded; want error", PolicyEnvVar)
+	}
+
+	t.Setenv(PolicyEnvVar, "version = 1\ninclude = [\"/srv\"]\n")
+	wlkr, err := WalkerFromPolicyFile("")
+	if err != nil {
+		t.Fatalf("WalkerFromPolicyFile(\"\") error: %v", err)
+	}
+	want := &fspb.Policy{Version: 1, Include: []string{"/srv"}}
+	if diff := cmp.Diff(want, wlkr.pol, cmp.Comparer(proto.Equal)); diff != "" {
+		t.Errorf("WalkerFromPolicyFile(\"\") policy: diff (-want +got):\n%s", diff)
+	}
+}
+
 func TestIsExcluded(t *testing.T) {
 	testCases := []struct {
 		desc     string
@@ -140,6 +227,84 @@ func TestIsExcluded(t *testing.T) {
 				"/tmp/some_file",
 			},
 			wantExcl: false,
+		}, {
+			desc: "test exclusion with base name glob",
+			path: "/srv/images/ubuntu.iso",
+			excludes: []string{
+				"*.iso",
+			},
+			wantExcl: true,
+		}, {
+			desc: "test exclusion with base name glob no match",
+			path: "/srv/images/ubuntu.img",
+			excludes: []string{
+				"*.iso",
+			},
+			wantExcl: false,
+		}, {
+			desc: "test exclusion with double star glob",
+			path: "/var/lib/app/data/main.db",
+			excludes: []string{
+				"/var/lib/**/*.db",
+			},
+			wantExcl: true,
+		}, {
+			desc: "test exclusion with double star glob matching no elements",
+			path: "/var/lib/main.db",
+			excludes: []string{
+				"/var/lib/**/*.db",
+			},
+			wantExcl: true,
+		}, {
+			desc: "test exclusion with double star glob no match",
+			path: "/var/log/main.db",
+			excludes: []string{
+				"/var/lib/**/*.db",
+			},
+			wantExcl: false,
+		}, {
+			desc: "test exclusion with file glob on dir",
+			path: "/var/lib/data.db/",
+			excludes: []string{
+				"/var/lib/*.db",
+			},
+			wantExcl: false,
+		}, {
+			desc: "test exclusion with dir glob",
+			path: "/home/user/.cache/",
+			excludes: []string{
+				"/home/*/.cache/",
+			},
+			wantExcl: true,
+		}, {
+			desc: "test exclusion with dir glob on contained file",
+			path: "/home/user/.cache/foo/bar",
+			excludes: []string{
+				"/home/*/.cache/",
+			},
+			wantExcl: true,
+		}, {
+			desc: "test exclusion with dir glob on file",
+			path: "/home/user/.cache",
+			excludes: []string{
+				"/home/*/.cache/",
+			},
+			wantExcl: false,
+		}, {
+			desc: "test exclusion with empty entry",
+			path: "/foo",
+			excludes: []string{
+				"",
+			},
+			wantExcl: false,
+		}, {
+			desc: "test exclusion with empty entry before match",
+			path: "/tmp/foo",
+			excludes: []string{
+				"",
+				"/tmp/",
+			},
+			wantExcl: true,
 		},
 	}
 
@@ -151,6 +316,94 @@ func TestIsExcluded(t *testing.T) {
 	}
 }
 
+func TestWalkerAddIncludesExcludes(t *testing.T) {
+	path := filepath.Join(testdataDir, "defaultClientPolicy.toml")
+	wlkr, err := WalkerFromPolicyFile(path)
+	if err != nil {
+		t.Fatalf("WalkerFromPolicyFile() error: %v", err)
+	}
+	wantInclude := append(append([]string{}, wlkr.pol.Include...), "/opt/app")
+	wantExclude := append(append([]string{}, wlkr.pol.Exclude...), "/opt/app/cache/", "/srv/")
+
+	wlkr.AddIncludes("/opt/app")
+	wlkr.AddExcludes("/opt/app/cache/", "/srv/")
+	if diff := cmp.Diff(wantInclude, wlkr.pol.Include); diff != "" {
+		t.Errorf("AddIncludes() includes: diff (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(wantExclude, wlkr.pol.Exclude); diff != "" {
+		t.Errorf("AddExcludes() excludes: diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestWalkerEffectivePolicy(t *testing.T) {
+	path := filepath.Join(testdataDir, "defaultClientPolicy.toml")
+	wlkr, err := WalkerFromPolicyFile(path)
+	if err != nil {
+		t.Fatalf("WalkerFromPolicyFile() error: %v", err)
+	}
+	wlkr.AddIncludes("/opt/app")
+
+	pol := wlkr.EffectivePolicy()
+	if pol.OutputFileMode != "0444" {
+		t.Errorf("EffectivePolicy().OutputFileMode = %q; want the default %q", pol.OutputFileMode, "0444")
+	}
+	if !slices.Contains(pol.Include, "/opt/app") {
+		t.Errorf("EffectivePolicy().Include = %q; want it to contain the added %q", pol.Include, "/opt/app")
+	}
+	if pol.MaxOpenFiles == 0 {
+		t.Errorf("EffectivePolicy().MaxOpenFiles = 0; want the default derived from the file descriptor limit")
+	}
+	if pol.QueueDepth != defaultQueueDepth {
+		t.Errorf("EffectivePolicy().QueueDepth = %d; want the default %d", pol.QueueDepth, defaultQueueDepth)
+	}
+	if pol.HashBufferSize != defaultHashBufferSize {
+		t.Errorf("EffectivePolicy().HashBufferSize = %d; want the default %d", pol.HashBufferSize, defaultHashBufferSize)
+	}
+	wlkr.pol.QueueDepth = 16
+	if got := wlkr.EffectivePolicy().QueueDepth; got != 16 {
+		t.Errorf("EffectivePolicy().QueueDepth = %d with queueDepth set in the policy; want 16", got)
+	}
+	wlkr.QueueDepth = 8
+	if got := wlkr.EffectivePolicy().QueueDepth; got != 8 {
+		t.Errorf("EffectivePolicy().QueueDepth = %d with Walker.QueueDepth set; want 8", got)
+	}
+	if wlkr.pol.OutputFileMode != "" {
+		t.Errorf("EffectivePolicy() modified the walker's policy: outputFileMode = %q", wlkr.pol.OutputFileMode)
+	}
+}
+
+func TestWalkerFromPolicyFileGzip(t *testing.T) {
+	path := filepath.Join(testdataDir, "defaultClientPolicy.toml")
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	gzPath := filepath.Join(t.TempDir(), "defaultClientPolicy.toml.gz")
+	if err := os.WriteFile(gzPath, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := WalkerFromPolicyFile(path)
+	if err != nil {
+		t.Fatalf("WalkerFromPolicyFile(%q) error: %v", path, err)
+	}
+	got, err := WalkerFromPolicyFile(gzPath)
+	if err != nil {
+		t.Fatalf("WalkerFromPolicyFile(%q) error: %v", gzPath, err)
+	}
+	if diff := cmp.Diff(want.pol, got.pol, cmp.Comparer(proto.Equal)); diff != "" {
+		t.Errorf("WalkerFromPolicyFile() gzipped policy: diff (-want +got):\n%s", diff)
+	}
+}
+
 func TestConvert(t *testing.T) {
 	wlkr := &Walker{
 		pol: &fspb.Policy{
@@ -234,6 +487,88 @@ func TestConvert(t *testing.T) {
 	}
 }
 
+func TestConvertExcludeHashingGlob(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"image.iso":      "iso",
+		"big.txt":        "too large to hash",
+		"small.txt":      "small",
+		"lib/app/foo.db": "db",
+		"lib/app/foo.md": "md",
+	})
+
+	wlkr := &Walker{
+		pol: &fspb.Policy{
+			ExcludeHashing: []string{
+				"*.iso",
+				filepath.Join(dir, "lib/**/*.db"),
+			},
+			MaxHashFileSize: 10,
+		},
+	}
+	wantHashed := map[string]bool{
+		"image.iso":      false,
+		"big.txt":        false,
+		"small.txt":      true,
+		"lib/app/foo.db": false,
+		"lib/app/foo.md": true,
+	}
+	h := sha256.New()
+	for name, want := range wantHashed {
+		path := filepath.Join(dir, name)
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f := wlkr.convert(&fileInfo{path: path, info: info}, h, nil)
+		if got := len(f.Fingerprint) > 0; got != want {
+			t.Errorf("convert(%q) hashed = %v; want %v", name, got, want)
+		}
+	}
+}
+
+func TestConvertHashSizeByExtension(t *testing.T) {
+	dir := t.TempDir()
+	content := make([]byte, 100)
+	wantHashed := map[string]bool{
+		"small.log": true,
+		"large.log": false,
+		"large.bin": true,
+		"large.txt": false,
+	}
+	for name := range wantHashed {
+		c := content
+		if name == "small.log" {
+			c = content[:10]
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), c, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wlkr := &Walker{
+		pol: &fspb.Policy{
+			MaxHashFileSize: 50,
+			MaxHashFileSizeByExtension: map[string]uint64{
+				".log": 20,
+				".bin": 1000,
+			},
+		},
+	}
+	h := sha256.New()
+	for name, want := range wantHashed {
+		path := filepath.Join(dir, name)
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f := wlkr.convert(&fileInfo{path: path, info: info}, h, nil)
+		if got := len(f.Fingerprint) > 0; got != want {
+			t.Errorf("convert(%q) hashed = %v; want %v", name, got, want)
+		}
+	}
+}
+
 func TestRun(t *testing.T) {
 	ctx := context.Background()
 	tmpfile, err := os.CreateTemp("", "walk.pb")
@@ -303,3 +638,1595 @@ func TestRun(t *testing.T) {
 		t.Error("walk.Id is empty")
 	}
 }
+
+func TestRunWarnsAboutUnwalkedIncludes(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"valid/file":    "content",
+		"excluded/file": "content",
+	})
+	valid := filepath.Join(root, "valid")
+	excluded := filepath.Join(root, "excluded")
+	missing := filepath.Join(root, "missing")
+
+	walk := runWalk(t, &fspb.Policy{
+		Include: []string{missing, valid, excluded},
+		Exclude: []string{excluded + "/"},
+	})
+
+	warned := map[string]bool{}
+	for _, n := range walk.Notification {
+		if n.Severity == fspb.Notification_WARNING {
+			warned[n.Path] = true
+		}
+	}
+	wantWarned := map[string]bool{
+		valid:    false,
+		excluded: true,
+		missing:  true,
+	}
+	for path, want := range wantWarned {
+		if warned[path] != want {
+			t.Errorf("include %q warned = %v; want %v", path, warned[path], want)
+		}
+	}
+
+	var gotFiles []string
+	for _, f := range walk.File {
+		gotFiles = append(gotFiles, f.Path)
+	}
+	sort.Strings(gotFiles)
+	wantFiles := []string{valid, filepath.Join(valid, "file")}
+	if diff := cmp.Diff(wantFiles, gotFiles); diff != "" {
+		t.Errorf("walked files: diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunRelativeTo(t *testing.T) {
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	var walks []*fspb.Walk
+	for _, mnt := range []string{"mnt1", "mnt2"} {
+		root := filepath.Join(t.TempDir(), mnt, "tree")
+		writeFiles(t, root, map[string]string{
+			"etc/passwd":  "root:x:0:0",
+			"bin/program": "binary",
+		})
+		for _, p := range []string{"etc/passwd", "bin/program", "etc", "bin", "."} {
+			if err := os.Chtimes(filepath.Join(root, p), mtime, mtime); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		walk := runWalk(t, &fspb.Policy{
+			Include:         []string{root},
+			MaxHashFileSize: 1024,
+			RelativeTo:      root,
+		})
+		for _, f := range walk.File {
+			if filepath.IsAbs(f.Path) {
+				t.Errorf("walk of %q contains absolute path %q", root, f.Path)
+			}
+			// The ctime can't be set and differs between the trees.
+			f.Stat.Ctime = nil
+		}
+		walks = append(walks, walk)
+	}
+
+	r := &Reporter{config: &fspb.ReportConfig{}}
+	report, err := r.Compare(walks[0], walks[1])
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	if !report.Empty() {
+		t.Errorf("Compare() of relative walks is not empty: %+v", report)
+	}
+	if n, _ := report.Counter.Get("before-files"); n != 5 {
+		t.Errorf("Compare() compared %d files; want 5", n)
+	}
+}
+
+func TestRunMaxWalkDuration(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{}
+	for i := 0; i < 500; i++ {
+		files[fmt.Sprintf("dir%d/file%d", i%10, i)] = "content"
+	}
+	writeFiles(t, root, files)
+
+	walk := runWalk(t, &fspb.Policy{
+		Include:         []string{root},
+		MaxWalkDuration: "1ns",
+	})
+	if n := len(walk.File); n >= len(files) {
+		t.Errorf("walk recorded %d files; want fewer than %d", n, len(files))
+	}
+	var warned bool
+	for _, n := range walk.Notification {
+		if n.Severity == fspb.Notification_WARNING && strings.Contains(n.Message, "maximum walk duration") {
+			warned = true
+		}
+	}
+	if !warned {
+		t.Errorf("walk has no maximum walk duration warning: %v", walk.Notification)
+	}
+	if walk.StopWalk == nil {
+		t.Error("walk.StopWalk is not set")
+	}
+
+	walk = runWalk(t, &fspb.Policy{
+		Include:         []string{root},
+		MaxWalkDuration: "1h",
+	})
+	if n, want := len(walk.File), len(files)+11; n != want {
+		t.Errorf("walk recorded %d files; want %d", n, want)
+	}
+	if len(walk.Notification) > 0 {
+		t.Errorf("walk has unexpected notifications: %v", walk.Notification)
+	}
+
+	wlkr := &Walker{pol: &fspb.Policy{MaxWalkDuration: "soon"}}
+	if err := wlkr.Run(context.Background()); err == nil {
+		t.Error("Run() with invalid maxWalkDuration: no error")
+	}
+}
+
+func TestRunSkipDirFunc(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"cache/a":           "a",
+		"data/b":            "b",
+		"data/cache/c":      "c",
+		"data/cache/more/d": "d",
+		"excluded/cache/e":  "e",
+	})
+
+	var called []string
+	var walk *fspb.Walk
+	wlkr := &Walker{
+		pol: &fspb.Policy{
+			Include: []string{root},
+			Exclude: []string{filepath.Join(root, "excluded") + "/"},
+		},
+		SkipDirFunc: func(path string, d fs.DirEntry) bool {
+			called = append(called, path)
+			return d.Name() == "cache"
+		},
+		WalkCallback: func(w *fspb.Walk) error {
+			walk = w
+			return nil
+		},
+	}
+	if err := wlkr.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	var gotFiles []string
+	for _, f := range walk.File {
+		rel, err := filepath.Rel(root, f.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotFiles = append(gotFiles, rel)
+	}
+	sort.Strings(gotFiles)
+	wantFiles := []string{".", "data", "data/b"}
+	if diff := cmp.Diff(wantFiles, gotFiles); diff != "" {
+		t.Errorf("walked files: diff (-want +got):\n%s", diff)
+	}
+	for _, p := range called {
+		if strings.HasPrefix(p, filepath.Join(root, "excluded")) {
+			t.Errorf("SkipDirFunc called for excluded path %q", p)
+		}
+	}
+}
+
+func TestRunPolicyFingerprint(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"file": "content"})
+	pol := &fspb.Policy{Include: []string{root}, MaxHashFileSize: 1024}
+
+	first := runWalk(t, pol)
+	second := runWalk(t, proto.Clone(pol).(*fspb.Policy))
+	if first.PolicyFingerprint == nil {
+		t.Fatal("walk has no policy fingerprint")
+	}
+	if !proto.Equal(first.PolicyFingerprint, second.PolicyFingerprint) {
+		t.Errorf("fingerprints of identical policies differ: %v != %v", first.PolicyFingerprint, second.PolicyFingerprint)
+	}
+
+	changed := proto.Clone(pol).(*fspb.Policy)
+	changed.MaxHashFileSize = 2048
+	third := runWalk(t, changed)
+	if third.PolicyFingerprint.GetValue() == first.PolicyFingerprint.GetValue() {
+		t.Errorf("policy fingerprint did not change with the policy: %s", first.PolicyFingerprint.GetValue())
+	}
+}
+
+func TestRunOutputFileMode(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"file": "content"})
+	out := filepath.Join(t.TempDir(), "walk.pb")
+
+	wlkr := &Walker{
+		pol: &fspb.Policy{
+			Include:         []string{root},
+			MaxHashFileSize: 1024,
+			OutputFileMode:  "0400",
+		},
+		WalkCallback: outpathWriter(out).writeWalk,
+		Counter:      &metrics.Counter{},
+	}
+	if err := wlkr.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	info, err := os.Stat(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Mode().Perm(); got != 0400 {
+		t.Errorf("walk file mode = %#o; want %#o", got, 0400)
+	}
+
+	wlkr.pol.OutputFileMode = "rw-------"
+	if err := wlkr.Run(context.Background()); err == nil {
+		t.Error("Run() with invalid outputFileMode succeeded; want error")
+	}
+}
+
+func TestRunFIFO(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"file": "content"})
+	fifo := filepath.Join(dir, "fifo")
+	if err := syscall.Mkfifo(fifo, 0644); err != nil {
+		t.Skipf("unable to create FIFO: %v", err)
+	}
+
+	// Hashing a FIFO must fail instead of blocking until a writer shows up.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := sha256sumNoFollow(fifo, sha256.New(), nil); !errors.Is(err, errFileChanged) {
+			t.Errorf("sha256sumNoFollow(%q) error = %v; want %v", fifo, err, errFileChanged)
+		}
+		if _, err := sha256sumFS(os.DirFS(dir), "fifo", sha256.New(), nil); !errors.Is(err, errFileChanged) {
+			t.Errorf("sha256sumFS(%q) error = %v; want %v", "fifo", err, errFileChanged)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("hashing a FIFO is blocked")
+	}
+
+	for _, tc := range []struct {
+		desc     string
+		fsys     fs.FS
+		include  string
+		fifoPath string
+	}{
+		{desc: "local", include: dir, fifoPath: fifo},
+		{desc: "fs", fsys: os.DirFS(dir), include: ".", fifoPath: "fifo"},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			var walk *fspb.Walk
+			wlkr := &Walker{
+				pol: &fspb.Policy{Include: []string{tc.include}, MaxHashFileSize: 1024},
+				FS:  tc.fsys,
+				WalkCallback: func(w *fspb.Walk) error {
+					walk = w
+					return nil
+				},
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := wlkr.Run(ctx); err != nil {
+				t.Fatalf("Run() error: %v", err)
+			}
+			if ctx.Err() != nil {
+				t.Fatal("Run() blocked on the FIFO")
+			}
+			var recorded *fspb.File
+			for _, f := range walk.File {
+				if f.Path == tc.fifoPath {
+					recorded = f
+				}
+			}
+			if recorded == nil {
+				t.Fatalf("Run() didn't record FIFO %q", tc.fifoPath)
+			}
+			if os.FileMode(recorded.Info.Mode)&os.ModeNamedPipe == 0 || recorded.Stat == nil || len(recorded.Fingerprint) > 0 {
+				t.Errorf("Run() recorded FIFO as %v; want a named pipe with stat info and without fingerprint", recorded)
+			}
+		})
+	}
+}
+
+func TestConvertSymlinkSwap(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"file":   "content",
+		"secret": "secret",
+	})
+	path := filepath.Join(dir, "file")
+	info, err := os.Lstat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Swap the file for a symlink after it was discovered as a regular file.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "secret"), path); err != nil {
+		t.Fatal(err)
+	}
+
+	wlkr := &Walker{
+		pol: &fspb.Policy{MaxHashFileSize: 1024},
+	}
+	errCh := make(chan *workerErr, 10)
+	f := wlkr.convert(&fileInfo{path: path, info: info}, sha256.New(), errCh)
+	close(errCh)
+
+	if len(f.Fingerprint) > 0 {
+		t.Errorf("convert() hashed symlink target: %v", f.Fingerprint)
+	}
+	var warnings int
+	for werr := range errCh {
+		if werr.severity != fspb.Notification_WARNING {
+			t.Errorf("convert() reported %s: %s; want only warnings", werr.severity, werr.err)
+			continue
+		}
+		warnings++
+	}
+	if warnings != 1 {
+		t.Errorf("convert() reported %d warnings; want 1", warnings)
+	}
+}
+
+func TestRunSkipHidden(t *testing.T) {
+	root := filepath.Join(t.TempDir(), ".home")
+	writeFiles(t, root, map[string]string{
+		".bashrc":       "alias ll='ls -l'",
+		".git/HEAD":     "ref: refs/heads/main",
+		"src/main.go":   "package main",
+		"src/.main.swp": "swap",
+	})
+
+	for _, tc := range []struct {
+		skipHidden bool
+		wantFiles  []string
+	}{
+		{
+			skipHidden: false,
+			wantFiles:  []string{".", ".bashrc", ".git", ".git/HEAD", "src", "src/.main.swp", "src/main.go"},
+		}, {
+			skipHidden: true,
+			wantFiles:  []string{".", "src", "src/main.go"},
+		},
+	} {
+		walk := runWalk(t, &fspb.Policy{
+			Include:         []string{root},
+			MaxHashFileSize: 1024,
+			SkipHidden:      tc.skipHidden,
+		})
+		var gotFiles []string
+		for _, f := range walk.File {
+			rel, err := filepath.Rel(root, f.Path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			gotFiles = append(gotFiles, rel)
+		}
+		sort.Strings(gotFiles)
+		if diff := cmp.Diff(tc.wantFiles, gotFiles); diff != "" {
+			t.Errorf("Run() with skipHidden=%t files: diff (-want +got):\n%s", tc.skipHidden, diff)
+		}
+	}
+}
+
+func TestRunOverlappingIncludes(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"usr/bin/ls":        "ls",
+		"usr/local/bin/foo": "foo",
+		"home/.config/rc":   "rc",
+	})
+	usr := filepath.Join(root, "usr")
+	walk := runWalk(t, &fspb.Policy{
+		Include: []string{
+			filepath.Join(usr, "local"),
+			usr + "/",
+			usr,
+			filepath.Join(root, "home"),
+			// Not dropped as the walk of home skips the hidden directory.
+			filepath.Join(root, "home", ".config"),
+		},
+		SkipHidden:      true,
+		MaxHashFileSize: 1024,
+	})
+
+	seen := map[string]int{}
+	for _, f := range walk.File {
+		seen[f.Path]++
+	}
+	for p, n := range seen {
+		if n != 1 {
+			t.Errorf("Run() recorded %q %d times; want once", p, n)
+		}
+	}
+	for _, p := range []string{
+		filepath.Join(usr, "bin", "ls"),
+		filepath.Join(usr, "local", "bin", "foo"),
+		filepath.Join(root, "home", ".config", "rc"),
+	} {
+		if seen[p] != 1 {
+			t.Errorf("Run() didn't record %q", p)
+		}
+	}
+
+	var dropped int
+	for _, n := range walk.Notification {
+		if n.Severity == fspb.Notification_INFO && strings.HasPrefix(n.Message, "dropping include") {
+			dropped++
+		}
+	}
+	if dropped != 2 {
+		t.Errorf("Run() recorded %d dropped includes; want 2", dropped)
+	}
+}
+
+func TestRunOverlappingIncludesFS(t *testing.T) {
+	// Files of a MapFS have no device number, which must not keep includes apart.
+	fsys := fstest.MapFS{
+		"usr/bin/ls":        {Data: []byte("ls")},
+		"usr/local/bin/foo": {Data: []byte("foo")},
+	}
+	var walk *fspb.Walk
+	wlkr := &Walker{
+		pol: &fspb.Policy{Include: []string{"usr", "usr/local"}},
+		FS:  fsys,
+		WalkCallback: func(w *fspb.Walk) error {
+			walk = w
+			return nil
+		},
+	}
+	if err := wlkr.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	seen := map[string]int{}
+	for _, f := range walk.File {
+		seen[f.Path]++
+	}
+	for p, n := range seen {
+		if n != 1 {
+			t.Errorf("Run() recorded %q %d times; want once", p, n)
+		}
+	}
+	if seen["usr/local/bin/foo"] != 1 {
+		t.Errorf("Run() didn't record %q", "usr/local/bin/foo")
+	}
+}
+
+func TestRunDuplicateRecordedPaths(t *testing.T) {
+	// Files outside of relativeTo keep their path, so both includes record "etc/hosts".
+	fsys := fstest.MapFS{
+		"etc/hosts":     {Data: []byte("host")},
+		"img/etc/hosts": {Data: []byte("image")},
+	}
+	var walk *fspb.Walk
+	wlkr := &Walker{
+		pol: &fspb.Policy{Include: []string{"etc", "img/etc"}, RelativeTo: "img"},
+		FS:  fsys,
+		WalkCallback: func(w *fspb.Walk) error {
+			walk = w
+			return nil
+		},
+	}
+	if err := wlkr.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	var got []string
+	for _, n := range walk.Notification {
+		if n.Message == "path was recorded more than once" {
+			got = append(got, n.Path)
+		}
+	}
+	if diff := cmp.Diff([]string{"etc/", "etc/hosts"}, got); diff != "" {
+		t.Errorf("Run() duplicate path notifications: diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunExcludeContents(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"data/huge/blob1":   "blob1",
+		"data/huge/d/blob2": "blob2",
+		"data/small":        "small",
+	})
+	if err := os.Chmod(filepath.Join(root, "data", "huge"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	walk := runWalk(t, &fspb.Policy{
+		Include:         []string{root},
+		ExcludeContents: []string{filepath.Join(root, "data", "huge") + "/"},
+		MaxHashFileSize: 1024,
+	})
+	var gotFiles []string
+	var huge *fspb.File
+	for _, f := range walk.File {
+		rel, err := filepath.Rel(root, f.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotFiles = append(gotFiles, rel)
+		if rel == "data/huge" {
+			huge = f
+		}
+	}
+	sort.Strings(gotFiles)
+	wantFiles := []string{".", "data", "data/huge", "data/small"}
+	if diff := cmp.Diff(wantFiles, gotFiles); diff != "" {
+		t.Errorf("Run() files: diff (-want +got):\n%s", diff)
+	}
+	if huge == nil || os.FileMode(huge.Info.Mode).Perm() != 0700 {
+		t.Errorf("Run() didn't record the metadata of the directory with excluded contents: %v", huge)
+	}
+}
+
+func TestRunHonorIgnoreFiles(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".fswalkerignore":     "cache/\n",
+		"keep":                "keep",
+		"cache/x":             "x",
+		"sub/.fswalkerignore": "# secrets stay local\nsecret\n\n**/*.tmp\n",
+		"sub/secret":          "secret",
+		"sub/public":          "public",
+		"sub/deep/a.tmp":      "a",
+		"sub/deep/b":          "b",
+		"other/secret":        "secret",
+	})
+
+	walkedFiles := func(walk *fspb.Walk) []string {
+		var files []string
+		for _, f := range walk.File {
+			rel, err := filepath.Rel(root, f.Path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			files = append(files, rel)
+		}
+		sort.Strings(files)
+		return files
+	}
+
+	walk := runWalk(t, &fspb.Policy{
+		Include:          []string{root},
+		HonorIgnoreFiles: true,
+	})
+	wantFiles := []string{
+		".", ".fswalkerignore", "keep",
+		"other", "other/secret",
+		"sub", "sub/.fswalkerignore", "sub/deep", "sub/deep/b", "sub/public",
+	}
+	if diff := cmp.Diff(wantFiles, walkedFiles(walk)); diff != "" {
+		t.Errorf("Run() files: diff (-want +got):\n%s", diff)
+	}
+
+	// An include below an ignored path is not dropped as already walked.
+	walk = runWalk(t, &fspb.Policy{
+		Include:          []string{root, filepath.Join(root, "sub", "secret")},
+		HonorIgnoreFiles: true,
+	})
+	wantFiles = append(wantFiles, "sub/secret")
+	sort.Strings(wantFiles)
+	if diff := cmp.Diff(wantFiles, walkedFiles(walk)); diff != "" {
+		t.Errorf("Run() files with include below ignored path: diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunPrePass(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"a":          "a",
+		"b/c":        "cc",
+		"b/d/e":      "eee",
+		"excluded/f": "f",
+	})
+
+	var progress []Progress
+	var walk *fspb.Walk
+	skipDirCalls := map[string]int{}
+	wlkr := &Walker{
+		pol: &fspb.Policy{
+			Include:         []string{root},
+			Exclude:         []string{filepath.Join(root, "excluded") + "/"},
+			MaxHashFileSize: 1024,
+		},
+		Verbose: true,
+		SkipDirFunc: func(path string, d fs.DirEntry) bool {
+			skipDirCalls[path]++
+			return false
+		},
+		PrePass: true,
+		ProgressCallback: func(p Progress) {
+			progress = append(progress, p)
+		},
+		WalkCallback: func(w *fspb.Walk) error {
+			walk = w
+			return nil
+		},
+	}
+	if err := wlkr.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	if len(progress) != len(walk.File) {
+		t.Fatalf("ProgressCallback called %d times; want once per file (%d)", len(progress), len(walk.File))
+	}
+	last := progress[len(progress)-1]
+	if last.TotalFiles != int64(len(walk.File)) || last.Files != last.TotalFiles {
+		t.Errorf("last progress = %+v; want %d files in total and processed", last, len(walk.File))
+	}
+	if last.Bytes != last.TotalBytes {
+		t.Errorf("last progress = %+v; want all bytes processed", last)
+	}
+
+	// The pre-pass has no side effects.
+	for p, n := range skipDirCalls {
+		if n != 1 {
+			t.Errorf("SkipDirFunc called %d times for %q; want once", n, p)
+		}
+	}
+	notifications := map[string]int{}
+	for _, n := range walk.Notification {
+		notifications[n.Message]++
+	}
+	for m, n := range notifications {
+		if n != 1 {
+			t.Errorf("notification %q recorded %d times; want once", m, n)
+		}
+	}
+}
+
+// noSysFile is a FileInfo without any underlying data source.
+type noSysFile struct {
+	testFile
+}
+
+func (*noSysFile) Sys() interface{} { return nil }
+
+func TestConvertWithoutStat(t *testing.T) {
+	defer func(f func() ([]*mountinfo.Mount, error)) { readMountInfo = f }(readMountInfo)
+	readMountInfo = func() ([]*mountinfo.Mount, error) {
+		return []*mountinfo.Mount{
+			{Major: 8, Minor: 1, MountPoint: "/"},
+			{Major: 0, Minor: 50, MountPoint: "/fuse", FSType: "fuse.sshfs"},
+		}, nil
+	}
+	wlkr := &Walker{
+		pol:  &fspb.Policy{MaxHashFileSize: 1},
+		walk: &fspb.Walk{},
+	}
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	// Two includes on the same FUSE mount and one include spanning both mounts.
+	for _, fi := range []*fileInfo{
+		{path: "/fuse/x/a", root: "/fuse/x"},
+		{path: "/fuse/y/b", root: "/fuse/y"},
+		{path: "/data/c", root: "/"},
+		{path: "/fuse/d", root: "/"},
+	} {
+		name := filepath.Base(fi.path)
+		fi.info = &noSysFile{testFile{name: name, size: 10, mode: 0644, modTime: mtime}}
+		// A nil errCh makes sure no per-file error is sent as that would block.
+		f := wlkr.convert(fi, sha256.New(), nil)
+		if f.Stat != nil {
+			t.Errorf("convert(%q) stat = %v; want nil", fi.path, f.Stat)
+		}
+		wantInfo := &fspb.FileInfo{Name: name, Size: 10, Mode: 0644, Modified: tspb.New(mtime)}
+		if !proto.Equal(f.Info, wantInfo) {
+			t.Errorf("convert(%q) info = %v; want %v", fi.path, f.Info, wantInfo)
+		}
+	}
+
+	wlkr.addMissingStatWarnings()
+	got := map[string]string{}
+	for _, n := range wlkr.walk.Notification {
+		if n.Severity != fspb.Notification_WARNING {
+			t.Errorf("notification %v is no warning", n)
+		}
+		got[n.Path] = n.Message
+	}
+	want := map[string]string{
+		"/":     `no file stat available for 1 files on the filesystem at "/", only basic file info was recorded`,
+		"/fuse": `no file stat available for 3 files on the filesystem at "/fuse", only basic file info was recorded`,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("missing stat warnings: diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunHashChangedOnly(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"unchanged": "unchanged",
+		"changed":   "before",
+	})
+	pol := &fspb.Policy{
+		Include:         []string{root},
+		MaxHashFileSize: 1024,
+		HashChangedOnly: true,
+	}
+	baseline := runWalk(t, pol)
+	// Tamper with the baseline fingerprint of the unchanged file to tell
+	// whether it was copied or hashed again.
+	for _, f := range baseline.File {
+		if filepath.Base(f.Path) == "unchanged" {
+			f.Fingerprint[0].Value = "from-baseline"
+		}
+	}
+	writeFiles(t, root, map[string]string{"changed": "after, with a different size"})
+
+	var walk *fspb.Walk
+	wlkr := &Walker{
+		pol:      pol,
+		Baseline: baseline,
+		Counter:  &metrics.Counter{},
+		WalkCallback: func(w *fspb.Walk) error {
+			walk = w
+			return nil
+		},
+	}
+	if err := wlkr.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	wantFingerprints := map[string]string{
+		"unchanged": "from-baseline",
+		"changed":   fmt.Sprintf("%x", sha256.Sum256([]byte("after, with a different size"))),
+	}
+	for _, f := range walk.File {
+		want, ok := wantFingerprints[filepath.Base(f.Path)]
+		if !ok {
+			continue
+		}
+		if len(f.Fingerprint) != 1 || f.Fingerprint[0].Value != want {
+			t.Errorf("Run() fingerprint of %q = %v; want %q", f.Path, f.Fingerprint, want)
+		}
+	}
+	if got, _ := wlkr.Counter.Get(countHashSkipped); got != 1 {
+		t.Errorf("Run() %s = %d; want 1", countHashSkipped, got)
+	}
+}
+
+func TestRunHashChangedOnlyOutOfScope(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"kept/file": "kept",
+		"new/file":  "new",
+	})
+	baseline := runWalk(t, &fspb.Policy{
+		Include:         []string{root},
+		Exclude:         []string{filepath.Join(root, "new") + "/"},
+		MaxHashFileSize: 1024,
+		HashChangedOnly: true,
+	})
+	writeFiles(t, root, map[string]string{"kept/file": "changed"})
+
+	var walk *fspb.Walk
+	wlkr := &Walker{
+		pol: &fspb.Policy{
+			Include:         []string{root},
+			MaxHashFileSize: 1024,
+			HashChangedOnly: true,
+		},
+		Baseline: baseline,
+		Counter:  &metrics.Counter{},
+		WalkCallback: func(w *fspb.Walk) error {
+			walk = w
+			return nil
+		},
+	}
+	if err := wlkr.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	// Files the baseline couldn't have recorded are left out of the comparison
+	// with it, so they aren't hashed.
+	wantHashed := map[string]bool{
+		filepath.Join(root, "kept/file"): true,
+		filepath.Join(root, "new/file"):  false,
+	}
+	for _, f := range walk.File {
+		want, ok := wantHashed[f.Path]
+		if !ok {
+			continue
+		}
+		if got := len(f.Fingerprint) > 0; got != want {
+			t.Errorf("Run() hashed %q = %t; want %t", f.Path, got, want)
+		}
+	}
+	if got, _ := wlkr.Counter.Get(countHashSkippedOutOfScope); got != 1 {
+		t.Errorf("Run() %s = %d; want 1", countHashSkippedOutOfScope, got)
+	}
+}
+
+// failOpenFS is a MapFS in which opening files with a name starting with "bad" fails.
+type failOpenFS struct {
+	fstest.MapFS
+}
+
+func (f failOpenFS) Open(name string) (fs.File, error) {
+	if strings.HasPrefix(path.Base(name), "bad") {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+	}
+	return f.MapFS.Open(name)
+}
+
+func TestRunErrorCallback(t *testing.T) {
+	fsys := failOpenFS{fstest.MapFS{
+		"dir/bad1": {Data: []byte("bad1")},
+		"dir/bad2": {Data: []byte("bad2")},
+		"dir/good": {Data: []byte("good")},
+	}}
+	errAbort := errors.New("abort")
+
+	for _, tc := range []struct {
+		desc      string
+		abort     bool
+		wantCalls int
+		wantErr   error
+	}{
+		{desc: "continue", abort: false, wantCalls: 2, wantErr: nil},
+		{desc: "abort", abort: true, wantCalls: 1, wantErr: errAbort},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			var calls int
+			var walked bool
+			wlkr := &Walker{
+				pol: &fspb.Policy{Include: []string{"dir"}, MaxHashFileSize: 1024},
+				FS:  fsys,
+				ErrorCallback: func(path string, err error) error {
+					calls++
+					if !errors.Is(err, fs.ErrPermission) {
+						t.Errorf("ErrorCallback(%q, %v): want a permission error", path, err)
+					}
+					if tc.abort {
+						return errAbort
+					}
+					return nil
+				},
+				WalkCallback: func(*fspb.Walk) error {
+					walked = true
+					return nil
+				},
+			}
+			if err := wlkr.Run(context.Background()); err != tc.wantErr {
+				t.Errorf("Run() error = %v; want %v", err, tc.wantErr)
+			}
+			if calls != tc.wantCalls {
+				t.Errorf("ErrorCallback called %d times; want %d", calls, tc.wantCalls)
+			}
+			if walked == tc.abort {
+				t.Errorf("WalkCallback called = %t; want %t", walked, !tc.abort)
+			}
+		})
+	}
+}
+
+func TestRunSortOutput(t *testing.T) {
+	fsys := failOpenFS{fstest.MapFS{}}
+	for i := 0; i < 20; i++ {
+		fsys.MapFS[fmt.Sprintf("dir/bad%02d", i)] = &fstest.MapFile{Data: []byte("bad")}
+		fsys.MapFS[fmt.Sprintf("dir/good%02d", i)] = &fstest.MapFile{Data: []byte("good")}
+	}
+
+	var first *fspb.Walk
+	for run := 0; run < 5; run++ {
+		var walk *fspb.Walk
+		wlkr := &Walker{
+			pol: &fspb.Policy{
+				Include:         []string{"dir", "missing"},
+				MaxHashFileSize: 1024,
+				SortOutput:      true,
+			},
+			FS: fsys,
+			WalkCallback: func(w *fspb.Walk) error {
+				walk = w
+				return nil
+			},
+		}
+		if err := wlkr.Run(context.Background()); err != nil {
+			t.Fatalf("Run() error: %v", err)
+		}
+		if !sort.SliceIsSorted(walk.File, func(i, j int) bool { return walk.File[i].Path < walk.File[j].Path }) {
+			t.Errorf("Run() files are not sorted by path")
+		}
+		if first == nil {
+			first = walk
+			continue
+		}
+		if diff := cmp.Diff(first.Notification, walk.Notification, cmp.Comparer(proto.Equal)); diff != "" {
+			t.Fatalf("Run() notifications differ between runs: diff (-first +run %d):\n%s", run, diff)
+		}
+	}
+
+	if len(first.Notification) < 20 {
+		t.Fatalf("Run() recorded %d notifications; want at least one per bad file", len(first.Notification))
+	}
+	if !sort.SliceIsSorted(first.Notification, func(i, j int) bool {
+		a, b := first.Notification[i], first.Notification[j]
+		if a.Severity != b.Severity {
+			return a.Severity < b.Severity
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Message < b.Message
+	}) {
+		t.Errorf("Run() notifications are not sorted: %v", first.Notification)
+	}
+}
+
+func TestRunDetailedMetrics(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"empty":  "",
+		"small":  "small",
+		"medium": strings.Repeat("m", 2<<10),
+		"sub/x":  "x",
+	})
+	// Sparse files keep the large buckets cheap.
+	for name, size := range map[string]int64{"large": 2 << 20, "huge": 200 << 20} {
+		f, err := os.Create(filepath.Join(root, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := f.Truncate(size); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wlkr := &Walker{
+		pol:             &fspb.Policy{Include: []string{root}},
+		Counter:         &metrics.Counter{},
+		DetailedMetrics: true,
+	}
+	if err := wlkr.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	got := map[string]int64{}
+	for m, v := range wlkr.Counter.Snapshot() {
+		if strings.HasPrefix(m, SizeBucketPrefix) {
+			got[strings.TrimPrefix(m, SizeBucketPrefix)] = v
+		}
+	}
+	want := map[string]int64{"0-1K": 3, "1K-1M": 1, "1M-100M": 1, ">100M": 1}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Run() size histogram: diff (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"0-1K", "1K-1M", "1M-100M", ">100M"}, SizeBuckets()); diff != "" {
+		t.Errorf("SizeBuckets(): diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunFS(t *testing.T) {
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	fsys := fstest.MapFS{
+		"etc/passwd":      {Data: []byte("root:x:0:0"), Mode: 0644, ModTime: mtime},
+		"etc/shadow":      {Data: []byte("root:*"), Mode: 0600, ModTime: mtime},
+		"etc/ssl/cert":    {Data: []byte("cert"), Mode: 0644, ModTime: mtime},
+		"var/log/syslog":  {Data: []byte("log"), Mode: 0644, ModTime: mtime},
+		"var/cache/entry": {Data: []byte("cache"), Mode: 0644, ModTime: mtime},
+	}
+
+	var walk *fspb.Walk
+	wlkr := &Walker{
+		pol: &fspb.Policy{
+			Include:         []string{"etc", "var"},
+			Exclude:         []string{"var/cache/", "etc/ssl/"},
+			MaxHashFileSize: 1024,
+		},
+		FS: fsys,
+		WalkCallback: func(w *fspb.Walk) error {
+			walk = w
+			return nil
+		},
+	}
+	if err := wlkr.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	gotFiles := map[string]string{}
+	for _, f := range walk.File {
+		var fp string
+		if len(f.Fingerprint) > 0 {
+			fp = f.Fingerprint[0].Value
+		}
+		gotFiles[f.Path] = fp
+	}
+	wantFiles := map[string]string{
+		"etc":            "",
+		"etc/passwd":     fmt.Sprintf("%x", sha256.Sum256([]byte("root:x:0:0"))),
+		"etc/shadow":     fmt.Sprintf("%x", sha256.Sum256([]byte("root:*"))),
+		"var":            "",
+		"var/log":        "",
+		"var/log/syslog": fmt.Sprintf("%x", sha256.Sum256([]byte("log"))),
+	}
+	if diff := cmp.Diff(wantFiles, gotFiles); diff != "" {
+		t.Errorf("Run() files: diff (-want +got):\n%s", diff)
+	}
+	for _, f := range walk.File {
+		if f.Path == "etc/shadow" && (f.Info.Size != 6 || os.FileMode(f.Info.Mode) != 0600 || !f.Info.Modified.AsTime().Equal(mtime)) {
+			t.Errorf("Run() info of %q = %v; want size 6, mode 0600 and mtime %v", f.Path, f.Info, mtime)
+		}
+	}
+}
+
+// countOpenFS is a MapFS which tracks the maximum number of regular files open at once.
+type countOpenFS struct {
+	fstest.MapFS
+	mu      sync.Mutex
+	open    int
+	maxOpen int
+}
+
+func (c *countOpenFS) Open(name string) (fs.File, error) {
+	f, err := c.MapFS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := c.MapFS[name]; !ok || c.MapFS[name].Mode.IsDir() {
+		return f, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.open++
+	if c.open > c.maxOpen {
+		c.maxOpen = c.open
+	}
+	return &countedFile{File: f, fs: c}, nil
+}
+
+type countedFile struct {
+	fs.File
+	fs *countOpenFS
+}
+
+func (f *countedFile) Read(b []byte) (int, error) {
+	// Give other workers the chance to open files concurrently.
+	time.Sleep(time.Millisecond)
+	return f.File.Read(b)
+}
+
+func (f *countedFile) Close() error {
+	f.fs.mu.Lock()
+	f.fs.open--
+	f.fs.mu.Unlock()
+	return f.File.Close()
+}
+
+func TestRunMaxOpenFiles(t *testing.T) {
+	// More workers than files allowed to be open make the limit matter on any host.
+	defer func(p int) { parallelism = p }(parallelism)
+	parallelism = 8
+
+	fsys := &countOpenFS{MapFS: fstest.MapFS{}}
+	for i := 0; i < 50; i++ {
+		fsys.MapFS[fmt.Sprintf("dir/file%02d", i)] = &fstest.MapFile{Data: []byte(fmt.Sprint(i))}
+	}
+	var walk *fspb.Walk
+	wlkr := &Walker{
+		pol: &fspb.Policy{
+			Include:         []string{"dir"},
+			MaxHashFileSize: 1024,
+			MaxOpenFiles:    1,
+		},
+		FS: fsys,
+		WalkCallback: func(w *fspb.Walk) error {
+			walk = w
+			return nil
+		},
+	}
+	if err := wlkr.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	for _, n := range walk.Notification {
+		if n.Severity == fspb.Notification_ERROR {
+			t.Errorf("Run() recorded error %v; want none", n)
+		}
+	}
+	for _, f := range walk.File {
+		if !f.Info.IsDir && len(f.Fingerprint) == 0 {
+			t.Errorf("Run() didn't hash %q", f.Path)
+		}
+	}
+	if fsys.maxOpen != 1 {
+		t.Errorf("Run() opened up to %d files at once; want 1", fsys.maxOpen)
+	}
+
+	// The limit needs to fit a channel size on 32 bit platforms too.
+	wlkr.pol.MaxOpenFiles = math.MaxUint32
+	if got := wlkr.maxOpenFiles(); got != math.MaxInt32 {
+		t.Errorf("maxOpenFiles() = %d; want %d", got, math.MaxInt32)
+	}
+}
+
+func TestRunMinDirectoryDepth(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"top":          "1",
+		"a/mid":        "2",
+		"a/b/deep":     "3",
+		"a/b/c/deeper": "4",
+	})
+
+	walk := runWalk(t, &fspb.Policy{
+		Include:           []string{root},
+		MinDirectoryDepth: 2,
+		MaxDirectoryDepth: 3,
+	})
+	var files []string
+	for _, f := range walk.File {
+		rel, err := filepath.Rel(root, f.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, rel)
+	}
+	sort.Strings(files)
+	wantFiles := []string{"a/b", "a/b/deep", "a/mid"}
+	if diff := cmp.Diff(wantFiles, files); diff != "" {
+		t.Errorf("Run() files: diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunHashBufferSize(t *testing.T) {
+	root := t.TempDir()
+	large := strings.Repeat("0123456789", 100000)
+	writeFiles(t, root, map[string]string{"small": "small", "large": large})
+	want := map[string]string{
+		filepath.Join(root, "small"): fmt.Sprintf("%x", sha256.Sum256([]byte("small"))),
+		filepath.Join(root, "large"): fmt.Sprintf("%x", sha256.Sum256([]byte(large))),
+	}
+
+	walk := runWalk(t, &fspb.Policy{
+		Include:         []string{root},
+		MaxHashFileSize: 1 << 30,
+		HashBufferSize:  4096,
+	})
+	for _, f := range walk.File {
+		if f.Info.IsDir {
+			continue
+		}
+		if len(f.Fingerprint) != 1 || f.Fingerprint[0].Value != want[f.Path] {
+			t.Errorf("Run() fingerprint of %q = %v; want %s", f.Path, f.Fingerprint, want[f.Path])
+		}
+	}
+
+	wlkr := &Walker{pol: &fspb.Policy{Include: []string{root}, HashBufferSize: math.MaxUint32}}
+	if err := wlkr.Run(context.Background()); err == nil {
+		t.Error("Run() with a 4GB hashBufferSize succeeded; want error")
+	}
+}
+
+func TestRunMaxBlockDeviceHashSizeOverflow(t *testing.T) {
+	wlkr := &Walker{pol: &fspb.Policy{Include: []string{t.TempDir()}, MaxBlockDeviceHashSize: math.MaxUint64}}
+	if err := wlkr.Run(context.Background()); err == nil {
+		t.Error("Run() with maxBlockDeviceHashSize above MaxInt64 succeeded; want error")
+	}
+}
+
+func TestRunFutureMtime(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"future": "x", "present": "y"})
+	future := time.Now().Add(24 * time.Hour)
+	if err := os.Chtimes(filepath.Join(root, "future"), future, future); err != nil {
+		t.Fatal(err)
+	}
+
+	var walk *fspb.Walk
+	wlkr := &Walker{
+		pol:     &fspb.Policy{Include: []string{root}},
+		Counter: &metrics.Counter{},
+		WalkCallback: func(w *fspb.Walk) error {
+			walk = w
+			return nil
+		},
+	}
+	if err := wlkr.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	// The modification times are compared to the time they were recorded at,
+	// not to the start of the walk.
+	var warned []string
+	for _, n := range walk.Notification {
+		if n.Severity == fspb.Notification_WARNING && strings.Contains(n.Message, "in the future") {
+			warned = append(warned, n.Path)
+			if !strings.Contains(n.Message, "(recorded at ") {
+				t.Errorf("Run() warning %q; want it to name when the file was recorded", n.Message)
+			}
+		}
+	}
+	if want := []string{filepath.Join(root, "future")}; !reflect.DeepEqual(warned, want) {
+		t.Errorf("Run() warned about future modification times of %q; want %q", warned, want)
+	}
+	if n, _ := wlkr.Counter.Get(countFutureMtime); n != 1 {
+		t.Errorf("%s = %d; want 1", countFutureMtime, n)
+	}
+
+	// A file written during a walk which started long ago isn't in the future.
+	path := filepath.Join(root, "present")
+	info, err := os.Lstat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wlkr.walk = &fspb.Walk{StartWalk: tspb.New(time.Now().Add(-time.Hour))}
+	wlkr.futureMtimes = nil
+	wlkr.convert(&fileInfo{path: path, info: info}, sha256.New(), nil)
+	if len(wlkr.futureMtimes) != 0 {
+		t.Errorf("convert() of a file written during the walk warned %v; want no warning", wlkr.futureMtimes)
+	}
+}
+
+func TestRunMtimeNormalization(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"file": "x"})
+	path := filepath.Join(root, "file")
+	mtime := time.Date(2018, 1, 1, 12, 30, 0, 500, time.UTC)
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		desc string
+		pol  *fspb.Policy
+		want time.Time
+	}{
+		{
+			desc: "absolute",
+			pol:  &fspb.Policy{},
+			want: mtime,
+		}, {
+			desc: "relative to reference",
+			pol:  &fspb.Policy{MtimeReference: "2018-01-01T12:00:00Z"},
+			want: time.Unix(30*60, 500),
+		}, {
+			desc: "before reference",
+			pol:  &fspb.Policy{MtimeReference: "2018-01-02T12:30:00+00:00"},
+			want: time.Unix(-24*60*60, 500),
+		}, {
+			desc: "zeroed",
+			pol:  &fspb.Policy{MtimeReference: "2018-01-01T12:00:00Z", ZeroMtime: true},
+			want: time.Unix(0, 0),
+		},
+	}
+	for _, tc := range testCases {
+		tc.pol.Include = []string{root}
+		walk := runWalk(t, tc.pol)
+		found := false
+		for _, f := range walk.File {
+			if f.Path != path {
+				continue
+			}
+			found = true
+			if got := f.Info.Modified.AsTime(); !got.Equal(tc.want) {
+				t.Errorf("%s: Run() modified = %s; want %s", tc.desc, got, tc.want)
+			}
+			if f.Stat != nil {
+				if got := f.Stat.Mtime.AsTime(); !got.Equal(tc.want) {
+					t.Errorf("%s: Run() stat mtime = %s; want %s", tc.desc, got, tc.want)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("%s: Run() didn't record %q", tc.desc, path)
+		}
+	}
+
+	wlkr := &Walker{pol: &fspb.Policy{Include: []string{root}, MtimeReference: "yesterday"}}
+	if err := wlkr.Run(context.Background()); err == nil {
+		t.Error("Run() with invalid mtimeReference: no error")
+	}
+}
+
+func TestRunOnFile(t *testing.T) {
+	// Several workers make the hook be called concurrently.
+	defer func(p int) { parallelism = p }(parallelism)
+	parallelism = 8
+
+	fsys := fstest.MapFS{}
+	for i := 0; i < 50; i++ {
+		fsys[fmt.Sprintf("dir/sub%d/file%02d", i%5, i)] = &fstest.MapFile{Data: []byte(fmt.Sprint(i))}
+	}
+	var mu sync.Mutex
+	seen := map[string]int{}
+	var walk *fspb.Walk
+	wlkr := &Walker{
+		pol: &fspb.Policy{
+			Include:         []string{"dir"},
+			MaxHashFileSize: 1024,
+		},
+		FS: fsys,
+		OnFile: func(f *fspb.File) {
+			mu.Lock()
+			defer mu.Unlock()
+			seen[f.Path]++
+		},
+		WalkCallback: func(w *fspb.Walk) error {
+			walk = w
+			return nil
+		},
+	}
+	if err := wlkr.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	calls := 0
+	for _, n := range seen {
+		calls += n
+	}
+	if calls != len(walk.File) {
+		t.Errorf("OnFile called %d times; want %d, once per file", calls, len(walk.File))
+	}
+	for _, f := range walk.File {
+		if seen[f.Path] != 1 {
+			t.Errorf("OnFile called %d times for %q; want 1", seen[f.Path], f.Path)
+		}
+	}
+}
+
+func TestRunHashBlockDevices(t *testing.T) {
+	disk := make([]byte, 4096)
+	for i := range disk {
+		disk[i] = byte(i * 7)
+	}
+	fsys := fstest.MapFS{
+		"dev/sda":  &fstest.MapFile{Data: disk, Mode: fs.ModeDevice | 0600},
+		"dev/tty":  &fstest.MapFile{Data: []byte("tty"), Mode: fs.ModeDevice | fs.ModeCharDevice | 0600},
+		"dev/file": &fstest.MapFile{Data: []byte("file"), Mode: 0644},
+	}
+	sum := func(b []byte) string { return fmt.Sprintf("%x", sha256.Sum256(b)) }
+
+	testCases := []struct {
+		desc string
+		pol  *fspb.Policy
+		want map[string]string
+	}{
+		{
+			desc: "disabled",
+			pol:  &fspb.Policy{},
+			want: map[string]string{"dev/file": sum([]byte("file"))},
+		}, {
+			desc: "whole device",
+			pol:  &fspb.Policy{HashBlockDevices: true},
+			want: map[string]string{"dev/file": sum([]byte("file")), "dev/sda": sum(disk)},
+		}, {
+			desc: "limited",
+			pol:  &fspb.Policy{HashBlockDevices: true, MaxBlockDeviceHashSize: 512},
+			want: map[string]string{"dev/file": sum([]byte("file")), "dev/sda": sum(disk[:512])},
+		}, {
+			desc: "excluded",
+			pol:  &fspb.Policy{HashBlockDevices: true, ExcludeHashing: []string{"dev/sda"}},
+			want: map[string]string{"dev/file": sum([]byte("file"))},
+		},
+	}
+	for _, tc := range testCases {
+		tc.pol.Include = []string{"dev"}
+		tc.pol.MaxHashFileSize = 1024
+		var walk *fspb.Walk
+		wlkr := &Walker{
+			pol: tc.pol,
+			FS:  fsys,
+			WalkCallback: func(w *fspb.Walk) error {
+				walk = w
+				return nil
+			},
+		}
+		if err := wlkr.Run(context.Background()); err != nil {
+			t.Fatalf("%s: Run() error: %v", tc.desc, err)
+		}
+		got := map[string]string{}
+		for _, f := range walk.File {
+			for _, fp := range f.Fingerprint {
+				got[f.Path] = fp.Value
+			}
+		}
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("%s: Run() fingerprints: diff (-want +got):\n%s", tc.desc, diff)
+		}
+	}
+}
+
+func TestRunEmptyExcludes(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"file": "x", "dir/file": "y"})
+
+	// A trailing newline in a policy easily yields empty entries.
+	walk := runWalk(t, &fspb.Policy{
+		Include:         []string{root},
+		Exclude:         []string{""},
+		ExcludeHashing:  []string{""},
+		ExcludeContents: []string{""},
+		MaxHashFileSize: 1024,
+	})
+	if len(walk.File) != 4 {
+		t.Errorf("Run() recorded %d files; want 4", len(walk.File))
+	}
+	for _, f := range walk.File {
+		if !f.Info.IsDir && len(f.Fingerprint) == 0 {
+			t.Errorf("Run() didn't hash %q", f.Path)
+		}
+	}
+}
+
+// blockingFS is a MapFS of which opening the file blocked waits until release is closed.
+type blockingFS struct {
+	fstest.MapFS
+	blocked string
+	release chan struct{}
+}
+
+func (b *blockingFS) Open(name string) (fs.File, error) {
+	if name == b.blocked {
+		<-b.release
+	}
+	return b.MapFS.Open(name)
+}
+
+func TestRunIsolateIncludes(t *testing.T) {
+	// A single worker per pool makes a shared pool stall on the slow include.
+	defer func(p int) { parallelism = p }(parallelism)
+	parallelism = 1
+
+	// The slow include sorts first, so it would be walked before the fast one.
+	fsys := &blockingFS{
+		MapFS: fstest.MapFS{
+			"nfs/big": &fstest.MapFile{Data: []byte("slow")},
+		},
+		blocked: "nfs/big",
+		release: make(chan struct{}),
+	}
+	for i := 0; i < 10; i++ {
+		fsys.MapFS[fmt.Sprintf("var/file%d", i)] = &fstest.MapFile{Data: []byte(fmt.Sprint(i))}
+	}
+	const fastFiles = 11 // var and its files
+
+	var mu sync.Mutex
+	fast := 0
+	fastDone := make(chan struct{})
+	var walk *fspb.Walk
+	wlkr := &Walker{
+		pol: &fspb.Policy{
+			Include:         []string{"nfs", "var"},
+			MaxHashFileSize: 1024,
+			IsolateIncludes: true,
+		},
+		FS: fsys,
+		OnFile: func(f *fspb.File) {
+			mu.Lock()
+			defer mu.Unlock()
+			if strings.HasPrefix(f.Path, "var") {
+				if fast++; fast == fastFiles {
+					close(fastDone)
+				}
+			}
+		},
+		WalkCallback: func(w *fspb.Walk) error {
+			walk = w
+			return nil
+		},
+	}
+
+	fastFirst := false
+	go func() {
+		select {
+		case <-fastDone:
+			fastFirst = true
+		case <-time.After(10 * time.Second):
+		}
+		close(fsys.release)
+	}()
+	if err := wlkr.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	if !fastFirst {
+		t.Error("Run() didn't process the fast include while the slow one was blocked")
+	}
+	if got, want := len(walk.File), fastFiles+2; got != want {
+		t.Errorf("Run() recorded %d files; want %d", got, want)
+	}
+}
+
+func TestRunLabels(t *testing.T) {
+	labels := map[string]string{"ticket": "CHG-1234", "operator": "alice"}
+	var walk *fspb.Walk
+	wlkr := &Walker{
+		pol:    &fspb.Policy{Include: []string{"dir"}},
+		FS:     fstest.MapFS{"dir/file": &fstest.MapFile{Data: []byte("content")}},
+		Labels: labels,
+		WalkCallback: func(w *fspb.Walk) error {
+			walk = w
+			return nil
+		},
+	}
+	if err := wlkr.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	labels["ticket"] = "changed after the run"
+
+	path := filepath.Join(t.TempDir(), "walk.pb")
+	if err := WriteWalk(path, walk, 0600); err != nil {
+		t.Fatalf("WriteWalk() error: %v", err)
+	}
+	walkFile, err := (&Reporter{}).ReadWalk(path)
+	if err != nil {
+		t.Fatalf("ReadWalk() error: %v", err)
+	}
+	want := map[string]string{"ticket": "CHG-1234", "operator": "alice"}
+	if diff := cmp.Diff(want, walkFile.Walk.Labels); diff != "" {
+		t.Errorf("ReadWalk() labels: diff (-want +got):\n%s", diff)
+	}
+	header, err := ReadWalkHeader(path)
+	if err != nil {
+		t.Fatalf("ReadWalkHeader() error: %v", err)
+	}
+	if diff := cmp.Diff(want, header.Labels); diff != "" {
+		t.Errorf("ReadWalkHeader() labels: diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunQueueDepth(t *testing.T) {
+	defer func(p int) { parallelism = p }(parallelism)
+	parallelism = 4
+
+	fsys := fstest.MapFS{}
+	var want []string
+	for i := 0; i < 200; i++ {
+		p := fmt.Sprintf("dir/sub%d/file%03d", i%10, i)
+		fsys[p] = &fstest.MapFile{Data: []byte(fmt.Sprint(i))}
+		want = append(want, p)
+	}
+	for i := 0; i < 10; i++ {
+		want = append(want, fmt.Sprintf("dir/sub%d", i))
+	}
+	want = append(want, "dir")
+	sort.Strings(want)
+
+	for _, depth := range []int{1, 10000} {
+		var walk *fspb.Walk
+		wlkr := &Walker{
+			pol: &fspb.Policy{
+				Include:         []string{"dir"},
+				MaxHashFileSize: 1024,
+			},
+			FS:         fsys,
+			QueueDepth: depth,
+			WalkCallback: func(w *fspb.Walk) error {
+				walk = w
+				return nil
+			},
+		}
+		if err := wlkr.Run(context.Background()); err != nil {
+			t.Fatalf("QueueDepth=%d: Run() error: %v", depth, err)
+		}
+		var got []string
+		for _, f := range walk.File {
+			if !f.Info.IsDir && len(f.Fingerprint) == 0 {
+				t.Errorf("QueueDepth=%d: %s not hashed", depth, f.Path)
+			}
+			got = append(got, f.Path)
+		}
+		sort.Strings(got)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("QueueDepth=%d: walked files diff (-want +got):\n%s", depth, diff)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unix
+
+package fswalker
+
+import (
+	"math"
+	"syscall"
+)
+
+// defaultMaxOpenFiles returns the default of the policy's maxOpenFiles, half of the
+// soft limit of open file descriptors, leaving room for the descriptors used otherwise.
+func defaultMaxOpenFiles() uint32 {
+	var rl syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rl); err != nil {
+		return fallbackMaxOpenFiles
+	}
+	switch {
+	case rl.Cur/2 < 1:
+		return 1
+	case rl.Cur/2 > math.MaxInt32:
+		return math.MaxInt32
+	}
+	return uint32(rl.Cur / 2)
+}